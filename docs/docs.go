@@ -29,7 +29,7 @@ const docTemplate = `{
                         "JWT": []
                     }
                 ],
-                "description": "List all access requests",
+                "description": "List access requests created by the user or those the user has permission to approve. Supports filtering, sorting and cursor pagination. Total count and next page are returned in X-Total-Count and Link headers",
                 "consumes": [
                     "application/json"
                 ],
@@ -40,6 +40,82 @@ const docTemplate = `{
                     "Access requests"
                 ],
                 "summary": "List access requests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "jane.doe",
+                        "name": "approver",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-01-01T00:00:00Z",
+                        "name": "createdAfter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-02-01T00:00:00Z",
+                        "name": "createdBefore",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-01-01T00:00:00Z",
+                        "name": "expiresAfter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-02-01T00:00:00Z",
+                        "name": "expiresBefore",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "john.doe",
+                        "name": "requester",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "SRE-PU-ACCESS",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Approved",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "sre",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor returned in the Link header of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 50,
+                        "description": "Maximum number of records to return. Defaults to 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-createdAt",
+                        "description": "Sort field. Prefix with \"-\" for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -48,6 +124,16 @@ const docTemplate = `{
                             "items": {
                                 "$ref": "#/definitions/models.AccessRequest"
                             }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Link to the next page"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of matching records"
+                            }
                         }
                     }
                 }
@@ -90,6 +176,37 @@ const docTemplate = `{
                 }
             }
         },
+        "/access/requests/archived": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "List deleted access requests. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Access requests"
+                ],
+                "summary": "List archived access requests",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AccessRequest"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/access/requests/{ID}": {
             "get": {
                 "security": [
@@ -133,7 +250,7 @@ const docTemplate = `{
                         "JWT": []
                     }
                 ],
-                "description": "Delete access request by id",
+                "description": "Archive access request by id. Active access is revoked first, deletion is refused if revocation fails",
                 "consumes": [
                     "application/json"
                 ],
@@ -205,14 +322,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/access/requests/{ID}/expire": {
-            "post": {
+        "/access/requests/{ID}/credentials": {
+            "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Expire user access. All providers assigned to role will ensure access expiration",
+                "description": "Returns credentials issued by role providers on approval, e.g. generated passwords, short-lived tokens or SSH certificates. Available only to the requester and only once",
                 "consumes": [
                     "application/json"
                 ],
@@ -222,7 +339,7 @@ const docTemplate = `{
                 "tags": [
                     "Access requests"
                 ],
-                "summary": "Expire access request",
+                "summary": "Retrieve access credentials",
                 "parameters": [
                     {
                         "type": "string",
@@ -237,20 +354,23 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.ResponseSuccess"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AccessCredential"
+                            }
                         }
                     }
                 }
             }
         },
-        "/access/roles": {
-            "get": {
+        "/access/requests/{ID}/expire": {
+            "post": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Create a new which can be later used in access requests",
+                "description": "Expire user access. All providers assigned to role will ensure access expiration",
                 "consumes": [
                     "application/json"
                 ],
@@ -258,28 +378,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Access roles"
+                    "Access requests"
+                ],
+                "summary": "Expire access request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "xxxx-xxxx-xxxx",
+                        "description": "AccessRequest id",
+                        "name": "ID",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "List roles",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.AccessRole"
-                            }
+                            "$ref": "#/definitions/controllers.ResponseSuccess"
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/access/requests/{ID}/purge": {
+            "delete": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Create a new which can be later used in access requests",
+                "description": "Permanently delete archived access request. Admin only",
                 "consumes": [
                     "application/json"
                 ],
@@ -287,38 +416,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Access roles"
+                    "Access requests"
                 ],
-                "summary": "Create role",
+                "summary": "Purge access request",
                 "parameters": [
                     {
-                        "description": "Role definition",
-                        "name": "role",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.AccessRole"
-                        }
+                        "type": "string",
+                        "default": "xxxx-xxxx-xxxx",
+                        "description": "AccessRequest id",
+                        "name": "ID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.ResponseSuccess"
+                            "$ref": "#/definitions/controllers.ResponseSuccessDeleted"
                         }
                     }
                 }
             }
         },
-        "/activity-logs": {
-            "get": {
+        "/access/requests/{ID}/recertification": {
+            "post": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "List all events",
+                "description": "Ask approvers to recertify standing access. Called by the scheduler when recertification comes due. Grant is expired if nobody recertifies within the role grace period",
                 "consumes": [
                     "application/json"
                 ],
@@ -326,30 +454,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Activity logs"
+                    "Access requests"
+                ],
+                "summary": "Request recertification",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "xxxx-xxxx-xxxx",
+                        "description": "AccessRequest id",
+                        "name": "ID",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "List events",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.ActivityLog"
-                            }
+                            "$ref": "#/definitions/controllers.ResponseSuccess"
                         }
                     }
                 }
             }
         },
-        "/events": {
-            "get": {
+        "/access/requests/{ID}/recertify": {
+            "post": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "List all events",
+                "description": "Confirm that standing access is still required. Schedules the next recertification",
                 "consumes": [
                     "application/json"
                 ],
@@ -357,30 +492,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Events"
+                    "Access requests"
+                ],
+                "summary": "Recertify access request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "xxxx-xxxx-xxxx",
+                        "description": "AccessRequest id",
+                        "name": "ID",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "List events",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Event"
-                            }
+                            "$ref": "#/definitions/controllers.ResponseSuccess"
                         }
                     }
                 }
             }
         },
-        "/events/{ID}": {
-            "get": {
+        "/access/requests/{ID}/restore": {
+            "post": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Get single event by id",
+                "description": "Restore archived access request. Admin only. Revoked access is not granted again",
                 "consumes": [
                     "application/json"
                 ],
@@ -388,14 +530,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Events"
+                    "Access requests"
                 ],
-                "summary": "Get event",
+                "summary": "Restore access request",
                 "parameters": [
                     {
                         "type": "string",
                         "default": "xxxx-xxxx-xxxx",
-                        "description": "Event id",
+                        "description": "AccessRequest id",
                         "name": "ID",
                         "in": "path",
                         "required": true
@@ -405,23 +547,20 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Event"
-                            }
+                            "$ref": "#/definitions/controllers.ResponseSuccess"
                         }
                     }
                 }
             }
         },
-        "/healthz": {
+        "/access/roles": {
             "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Healthy",
+                "description": "Create a new which can be later used in access requests",
                 "consumes": [
                     "application/json"
                 ],
@@ -429,30 +568,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "API health"
+                    "Access roles"
                 ],
-                "summary": "Healthy",
+                "summary": "List roles",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Health"
+                                "$ref": "#/definitions/models.AccessRole"
                             }
                         }
                     }
                 }
-            }
-        },
-        "/livez": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Liveness",
+                "description": "Create a new which can be later used in access requests",
                 "consumes": [
                     "application/json"
                 ],
@@ -460,30 +597,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "API health"
+                    "Access roles"
+                ],
+                "summary": "Create role",
+                "parameters": [
+                    {
+                        "description": "Role definition",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AccessRole"
+                        }
+                    }
                 ],
-                "summary": "Liveness",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Health"
-                            }
+                            "$ref": "#/definitions/controllers.ResponseSuccess"
                         }
                     }
                 }
             }
         },
-        "/readyz": {
+        "/activity-logs": {
             "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Readyness",
+                "description": "List all events",
                 "consumes": [
                     "application/json"
                 ],
@@ -491,30 +636,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "API health"
+                    "Activity logs"
                 ],
-                "summary": "Readyness",
+                "summary": "List events",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Health"
+                                "$ref": "#/definitions/models.ActivityLog"
                             }
                         }
                     }
                 }
             }
         },
-        "/user/profile": {
+        "/events": {
             "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Returns curent user's profile",
+                "description": "List events. Supports filtering, sorting and cursor pagination. Total count and next page are returned in X-Total-Count and Link headers",
                 "consumes": [
                     "application/json"
                 ],
@@ -522,21 +667,605 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Events"
+                ],
+                "summary": "List events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "2025-01-01T00:00:00Z",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "john.doe",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-02-01T00:00:00Z",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "parentId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "passage-server",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "transactionId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "cto2b.passage.accessRequest.created",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor returned in the Link header of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 50,
+                        "description": "Maximum number of records to return. Defaults to 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-createdAt",
+                        "description": "Sort field. Prefix with \"-\" for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    }
                 ],
-                "summary": "User profile",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.UserProfile"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Event"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Link to the next page"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Total number of matching records"
+                            }
                         }
                     }
                 }
             }
         },
-        "/user/profile/settings": {
-            "put": {
+        "/events/{ID}": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get single event by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Events"
+                ],
+                "summary": "Get event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "xxxx-xxxx-xxxx",
+                        "description": "Event id",
+                        "name": "ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Event"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Healthy",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API health"
+                ],
+                "summary": "Healthy",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Health"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/livez": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Liveness",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API health"
+                ],
+                "summary": "Liveness",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Health"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/providers": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "List registered provider kinds with their parameters and credential keys",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Providers"
+                ],
+                "summary": "List providers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/registry.Descriptor"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Readyness",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API health"
+                ],
+                "summary": "Readyness",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Health"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/reviews/campaigns": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "List access review campaigns without items. Admins receive all campaigns, reviewers receive campaigns with items assigned to them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Access reviews"
+                ],
+                "summary": "List review campaigns",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ReviewCampaign"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Create access review campaign. Current grants of roles in scope are snapshotted from approved access requests and provider user lists. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Access reviews"
+                ],
+                "summary": "Create review campaign",
+                "parameters": [
+                    {
+                        "description": "Review campaign definition",
+                        "name": "campaign",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReviewCampaign"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReviewCampaign"
+                        }
+                    }
+                }
+            }
+        },
+        "/reviews/campaigns/{ID}": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get review campaign. Admins receive all items, reviewers receive items assigned to them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Access reviews"
+                ],
+                "summary": "Get review campaign",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "xxxx-xxxx-xxxx",
+                        "description": "ReviewCampaign id",
+                        "name": "ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReviewCampaign"
+                        }
+                    }
+                }
+            }
+        },
+        "/reviews/campaigns/{ID}/items/{ItemID}/decision": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Record keep or revoke decision. Revoke decisions remove access through role providers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Access reviews"
+                ],
+                "summary": "Decide review item",
+                "parameters": [
+                    {
+                        "description": "Review decision",
+                        "name": "decision",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReviewDecision"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "xxxx-xxxx-xxxx",
+                        "description": "ReviewCampaign id",
+                        "name": "ID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "xxxx-xxxx-xxxx",
+                        "description": "ReviewItem id",
+                        "name": "ItemID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReviewItem"
+                        }
+                    }
+                }
+            }
+        },
+        "/reviews/campaigns/{ID}/report": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Export review campaign completion report as JSON or CSV. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Access reviews"
+                ],
+                "summary": "Review campaign report",
+                "parameters": [
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "Report format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "xxxx-xxxx-xxxx",
+                        "description": "ReviewCampaign id",
+                        "name": "ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReviewCampaignReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/profile": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Returns curent user's profile including current quota usage",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "User profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserProfile"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/profile/delegations": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Lists approval delegations registered by current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "List approval delegations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Delegation"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Delegates current user's approval permissions to another user for a limited time. Approvals are recorded as made on behalf of current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Create approval delegation",
+                "parameters": [
+                    {
+                        "description": "Delegation",
+                        "name": "delegation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Delegation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Delegation"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/profile/delegations/{ID}": {
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Revokes approval delegation registered by current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Delete approval delegation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "xxxx-xxxx-xxxx",
+                        "description": "Delegation id",
+                        "name": "ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResponseSuccessDeleted"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/profile/settings": {
+            "put": {
                 "security": [
                     {
                         "JWT": []
@@ -700,6 +1429,54 @@ const docTemplate = `{
                 }
             }
         },
+        "controllers.ResponseSuccessDeleted": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Record successfully deleted"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "/status/success"
+                }
+            }
+        },
+        "models.AccessCredential": {
+            "type": "object",
+            "properties": {
+                "accessRequestId": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "Password"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "PostgresRead"
+                }
+            }
+        },
         "models.AccessRequest": {
             "type": "object",
             "properties": {
@@ -760,6 +1537,16 @@ const docTemplate = `{
                         "$ref": "#/definitions/models.ProviderConfig"
                     }
                 },
+                "roles": {
+                    "description": "Constituent roles of a composite role",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AccessRoleRef"
+                    }
+                },
+                "standing": {
+                    "$ref": "#/definitions/models.StandingAccess"
+                },
                 "tags": {
                     "type": "array",
                     "items": {
@@ -813,6 +1600,29 @@ const docTemplate = `{
                 }
             }
         },
+        "models.ApprovalRule": {
+            "type": "object",
+            "properties": {
+                "authorCanApprove": {
+                    "type": "boolean"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "string": {
+                    "type": "string"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "models.ApprovalRuleRef": {
             "type": "object",
             "properties": {
@@ -832,6 +1642,39 @@ const docTemplate = `{
                 }
             }
         },
+        "models.Delegation": {
+            "type": "object",
+            "required": [
+                "delegate",
+                "until"
+            ],
+            "properties": {
+                "delegate": {
+                    "type": "string",
+                    "example": "john.doe"
+                },
+                "delegator": {
+                    "type": "string",
+                    "example": "jane.doe"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3b7af992-5a30-4ce1-821b-cac8194a230b"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Vacation"
+                },
+                "startsAt": {
+                    "type": "string",
+                    "example": "2025-07-01T00:00:00Z"
+                },
+                "until": {
+                    "type": "string",
+                    "example": "2025-07-14T00:00:00Z"
+                }
+            }
+        },
         "models.Event": {
             "type": "object",
             "properties": {
@@ -904,6 +1747,217 @@ const docTemplate = `{
                 }
             }
         },
+        "models.QuotaUsage": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "duration": {
+                    "type": "string",
+                    "example": "12h0m0s"
+                },
+                "maxActive": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "maxDuration": {
+                    "type": "string",
+                    "example": "40h"
+                },
+                "maxRequests": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "period": {
+                    "type": "string",
+                    "example": "720h"
+                },
+                "quota": {
+                    "type": "string",
+                    "example": "Privileged access"
+                },
+                "requests": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "models.ReviewCampaign": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Quarterly SOC2 access review"
+                },
+                "dueAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "2025 Q1 access review"
+                },
+                "scope": {
+                    "$ref": "#/definitions/models.ReviewCampaignScope"
+                }
+            }
+        },
+        "models.ReviewCampaignReport": {
+            "type": "object",
+            "properties": {
+                "campaign": {
+                    "$ref": "#/definitions/models.ReviewCampaign"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "kept": {
+                    "type": "integer"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "revoked": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReviewCampaignScope": {
+            "type": "object",
+            "properties": {
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "GitlabSrePu"
+                    ]
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "SRE-PU-ACCESS"
+                    ]
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "sre"
+                    ]
+                }
+            }
+        },
+        "models.ReviewDecision": {
+            "type": "object",
+            "required": [
+                "decision"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "example": "Still on-call for production"
+                },
+                "decision": {
+                    "type": "string",
+                    "enum": [
+                        "Keep",
+                        "Revoke"
+                    ],
+                    "example": "Keep"
+                }
+            }
+        },
+        "models.ReviewItem": {
+            "type": "object",
+            "properties": {
+                "accessRequestId": {
+                    "type": "string"
+                },
+                "campaignId": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "decidedAt": {
+                    "type": "string"
+                },
+                "decidedBy": {
+                    "type": "string"
+                },
+                "decision": {
+                    "type": "string",
+                    "example": "Pending"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "GitlabSrePu"
+                },
+                "providerKind": {
+                    "type": "string",
+                    "example": "gitlab"
+                },
+                "requester": {
+                    "type": "string",
+                    "example": "john.doe"
+                },
+                "reviewers": {
+                    "$ref": "#/definitions/models.ApprovalRule"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "SRE-PU-ACCESS"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "john.doe"
+                }
+            }
+        },
+        "models.StandingAccess": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "gracePeriod": {
+                    "description": "Time approvers have to recertify the grant before it is expired",
+                    "type": "string",
+                    "example": "168h"
+                },
+                "recertificationInterval": {
+                    "description": "Interval between recertifications",
+                    "type": "string",
+                    "example": "2160h"
+                }
+            }
+        },
         "models.User": {
             "type": "object",
             "properties": {
@@ -924,9 +1978,22 @@ const docTemplate = `{
         "models.UserProfile": {
             "type": "object",
             "properties": {
+                "delegations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Delegation"
+                    }
+                },
                 "id": {
                     "type": "string"
                 },
+                "quotas": {
+                    "description": "Current usage of quotas applying to the user",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.QuotaUsage"
+                    }
+                },
                 "settings": {
                     "$ref": "#/definitions/models.UserProfileSettings"
                 },
@@ -943,6 +2010,60 @@ const docTemplate = `{
                     "additionalProperties": {
                         "type": "string"
                     }
+                },
+                "sshPublicKey": {
+                    "description": "Public key signed by SSH certificate providers, in authorized_keys format",
+                    "type": "string",
+                    "example": "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBpDpu7t+yANYItPWECX71auIrVAL+y9SJzz1Pdnmumt alice@laptop"
+                }
+            }
+        },
+        "registry.Descriptor": {
+            "type": "object",
+            "properties": {
+                "credentials": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/registry.Parameter"
+                    }
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Manages Gitlab group membership"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "gitlab"
+                },
+                "parameters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/registry.Parameter"
+                    }
+                },
+                "requiresUsername": {
+                    "description": "Access requests must set username for the provider kind. Providers falling back to the requester leave it unset",
+                    "type": "boolean"
+                }
+            }
+        },
+        "registry.Parameter": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Full path of the group"
+                },
+                "example": {
+                    "type": "string",
+                    "example": "exampleorg/pu-group"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "group"
+                },
+                "required": {
+                    "type": "boolean"
                 }
             }
         }
@@ -12,6 +12,7 @@ import (
 	"github.com/CTO2BPublic/passage-server/pkg/api"
 	"github.com/CTO2BPublic/passage-server/pkg/config"
 	"github.com/CTO2BPublic/passage-server/pkg/crondriver"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/plugin"
 
 	"github.com/urfave/cli/v2"
 )
@@ -69,6 +70,7 @@ func main() {
 			server := api.GetServer()
 			server.SetupEngineWithDefaults()
 			server.RunEngine()
+			plugin.Shutdown()
 			return nil
 		},
 
@@ -92,6 +94,7 @@ func main() {
 							server := api.GetServer()
 							server.SetupEngineWithDefaults()
 							server.RunEngine()
+							plugin.Shutdown()
 
 							return nil
 						},
@@ -2,6 +2,12 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/CTO2BPublic/passage-server/pkg/config"
@@ -9,6 +15,7 @@ import (
 	"github.com/CTO2BPublic/passage-server/pkg/dbdriver"
 	"github.com/CTO2BPublic/passage-server/pkg/eventdriver"
 	"github.com/CTO2BPublic/passage-server/pkg/middlewares"
+	"github.com/CTO2BPublic/passage-server/pkg/providers"
 
 	docs "github.com/CTO2BPublic/passage-server/docs"
 
@@ -72,6 +79,17 @@ func (s *Server) SetupEngineWithDefaults() *Server {
 
 	Event.NewDriver()
 
+	// Reject roles providers can not honour. Broken composite roles are reported when requested
+	for _, role := range Config.Roles {
+		resolved, err := role.Resolve(Config.Roles)
+		if err != nil {
+			continue
+		}
+		if err := providers.ValidateRole(resolved); err != nil {
+			log.Fatal().Err(err).Msg("Invalid role configuration")
+		}
+	}
+
 	// Initialize controllers
 	accessRoleController := controllers.NewAccessRoleController()
 	accessRequestController := controllers.NewAccessRequestController()
@@ -79,6 +97,8 @@ func (s *Server) SetupEngineWithDefaults() *Server {
 	userController := controllers.NewUserController()
 	eventControlller := controllers.NewEventController()
 	activityLogController := controllers.NewActivityLogController()
+	reviewCampaignController := controllers.NewReviewCampaignController(accessRequestController)
+	providerController := controllers.NewProviderController()
 
 	// Define routes
 	rg := s.Engine.Group("")
@@ -89,10 +109,32 @@ func (s *Server) SetupEngineWithDefaults() *Server {
 		access.GET("/roles", accessRoleController.List)
 		access.POST("/requests", accessRequestController.Create)
 		access.GET("/requests", accessRequestController.List)
+		access.GET("/requests/archived", accessRequestController.ListArchived)
 		access.GET("/requests/:ID", accessRequestController.Get)
 		access.POST("/requests/:ID/approve", accessRequestController.Approve)
 		access.POST("/requests/:ID/expire", accessRequestController.Expire)
+		access.GET("/requests/:ID/credentials", accessRequestController.Credentials)
+		access.POST("/requests/:ID/recertification", accessRequestController.RequestRecertification)
+		access.POST("/requests/:ID/recertify", accessRequestController.Recertify)
+		access.POST("/requests/:ID/restore", accessRequestController.Restore)
 		access.DELETE("/requests/:ID", accessRequestController.Delete)
+		access.DELETE("/requests/:ID/purge", accessRequestController.Purge)
+	}
+
+	providers := rg.Group("/providers")
+	providers.Use(middlewares.Auth())
+	{
+		providers.GET("", providerController.List)
+	}
+
+	reviews := rg.Group("/reviews")
+	reviews.Use(middlewares.Auth())
+	{
+		reviews.POST("/campaigns", reviewCampaignController.Create)
+		reviews.GET("/campaigns", reviewCampaignController.List)
+		reviews.GET("/campaigns/:ID", reviewCampaignController.Get)
+		reviews.POST("/campaigns/:ID/items/:ItemID/decision", reviewCampaignController.Decide)
+		reviews.GET("/campaigns/:ID/report", reviewCampaignController.Report)
 	}
 
 	user := rg.Group("/user")
@@ -100,6 +142,9 @@ func (s *Server) SetupEngineWithDefaults() *Server {
 	{
 		user.GET("/profile", userController.GetProfile)
 		user.PUT("/profile/settings", userController.UpdateProfileSettings)
+		user.GET("/profile/delegations", userController.ListDelegations)
+		user.POST("/profile/delegations", userController.CreateDelegation)
+		user.DELETE("/profile/delegations/:ID", userController.DeleteDelegation)
 	}
 
 	users := rg.Group("/users")
@@ -131,10 +176,33 @@ func (s *Server) SetupEngineWithDefaults() *Server {
 	return s
 }
 
+// RunEngine serves API until SIGINT or SIGTERM is received and in-flight requests are finished
 func (s *Server) RunEngine() {
-	err := s.Engine.Run()
-	if err != nil {
-		log.Error().Str("Engine", "Failed to start apiserver").Msg(err.Error())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	address := ":8080"
+	if port := os.Getenv("PORT"); port != "" {
+		address = ":" + port
+	}
+	srv := &http.Server{Addr: address, Handler: s.Engine}
+
+	go func() {
+		err := srv.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Str("Engine", "Failed to start apiserver").Msg(err.Error())
+		}
+		stop()
+	}()
+
+	<-ctx.Done()
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error().Str("Engine", "Failed to stop apiserver").Msg(err.Error())
 	}
 }
 
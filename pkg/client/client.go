@@ -3,6 +3,8 @@ package client
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strconv"
 
 	"github.com/CTO2BPublic/passage-server/pkg/models"
 )
@@ -21,17 +23,31 @@ type ClientResponse struct {
 }
 
 type GetAccessRequestsOpts struct {
-	Status string
+	Status    string
+	Role      string
+	Requester string
 }
 
 func (c *ApiClient) GetAccessRequests(opts GetAccessRequestsOpts) (response []models.AccessRequest, statusCode int, err error) {
 
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(models.PaginationMaxLimit))
+	if opts.Status != "" {
+		query.Set("status", opts.Status)
+	}
+	if opts.Role != "" {
+		query.Set("role", opts.Role)
+	}
+	if opts.Requester != "" {
+		query.Set("requester", opts.Requester)
+	}
+
 	req := ClientRequest{
-		ApiEndpoint: "/access/requests",
+		ApiEndpoint: "/access/requests?" + query.Encode(),
 		Method:      "GET",
 	}
 
-	return processRequest[[]models.AccessRequest](c, req)
+	return processPagedRequest[models.AccessRequest](c, req)
 
 }
 
@@ -50,9 +66,50 @@ func (c *ApiClient) ExpireAccessRequest(opts ExpireAccessRequestOpts) (response
 
 }
 
+type RequestRecertificationOpts struct {
+	Id string
+}
+
+func (c *ApiClient) RequestRecertification(opts RequestRecertificationOpts) (response ClientResponse, statusCode int, err error) {
+
+	req := ClientRequest{
+		ApiEndpoint: fmt.Sprintf("/access/requests/%s/recertification", opts.Id),
+		Method:      "POST",
+	}
+
+	return processRequest[ClientResponse](c, req)
+
+}
+
+func (c *ApiClient) GetArchivedAccessRequests() (response []models.AccessRequest, statusCode int, err error) {
+
+	req := ClientRequest{
+		ApiEndpoint: "/access/requests/archived",
+		Method:      "GET",
+	}
+
+	return processRequest[[]models.AccessRequest](c, req)
+
+}
+
+type PurgeAccessRequestOpts struct {
+	Id string
+}
+
+func (c *ApiClient) PurgeAccessRequest(opts PurgeAccessRequestOpts) (response ClientResponse, statusCode int, err error) {
+
+	req := ClientRequest{
+		ApiEndpoint: fmt.Sprintf("/access/requests/%s/purge", opts.Id),
+		Method:      "DELETE",
+	}
+
+	return processRequest[ClientResponse](c, req)
+
+}
+
 // Generic processRequest function
 func processRequest[T any](c *ApiClient, req ClientRequest) (T, int, error) {
-	data, statusCode, err := c.doRequest(req)
+	data, _, statusCode, err := c.doRequest(req)
 	if err != nil || statusCode != 200 {
 		return *new(T), statusCode, fmt.Errorf("unexpected API response code: %d, body: %s err: %s", statusCode, string(data), err)
 	}
@@ -65,3 +122,24 @@ func processRequest[T any](c *ApiClient, req ClientRequest) (T, int, error) {
 
 	return result, statusCode, nil
 }
+
+// processPagedRequest follows Link headers and collects records of all pages
+func processPagedRequest[T any](c *ApiClient, req ClientRequest) ([]T, int, error) {
+	result := []T{}
+	for req.ApiEndpoint != "" {
+		data, header, statusCode, err := c.doRequest(req)
+		if err != nil || statusCode != 200 {
+			return nil, statusCode, fmt.Errorf("unexpected API response code: %d, body: %s err: %s", statusCode, string(data), err)
+		}
+
+		var page []T
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, statusCode, fmt.Errorf("failed to parse api response: %d, body: %s err: %s", statusCode, string(data), err)
+		}
+		result = append(result, page...)
+
+		req.ApiEndpoint = nextPage(header)
+	}
+
+	return result, 200, nil
+}
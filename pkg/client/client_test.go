@@ -0,0 +1,44 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccessRequestsFollowsPages(t *testing.T) {
+	pages := map[string][]models.AccessRequest{
+		"":      {{Id: "req-1"}, {Id: "req-2"}},
+		"page2": {{Id: "req-3"}},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "approved", r.URL.Query().Get("status"))
+		assert.Equal(t, "1000", r.URL.Query().Get("limit"))
+
+		cursor := r.URL.Query().Get("cursor")
+		if cursor == "" {
+			query := r.URL.Query()
+			query.Set("cursor", "page2")
+			w.Header().Set("Link", "<"+r.URL.Path+"?"+query.Encode()+`>; rel="next"`)
+		}
+		_ = json.NewEncoder(w).Encode(pages[cursor])
+	}))
+	t.Cleanup(server.Close)
+
+	apiClient := NewApiClient(ApiClientOpts{Url: server.URL})
+	requests, _, err := apiClient.GetAccessRequests(GetAccessRequestsOpts{Status: "approved"})
+	require.NoError(t, err)
+
+	ids := []string{}
+	for _, request := range requests {
+		ids = append(ids, request.Id)
+	}
+	assert.Equal(t, []string{"req-1", "req-2", "req-3"}, ids)
+}
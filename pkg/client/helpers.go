@@ -13,7 +13,7 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func (a *ApiClient) doRequest(Req ClientRequest) (ResponseData []byte, StatusCode int, Err error) {
+func (a *ApiClient) doRequest(Req ClientRequest) (ResponseData []byte, Header http.Header, StatusCode int, Err error) {
 
 	apiurl := a.Url + Req.ApiEndpoint
 	bytearr, err := json.Marshal(Req.Body)
@@ -33,7 +33,7 @@ func (a *ApiClient) doRequest(Req ClientRequest) (ResponseData []byte, StatusCod
 
 	response, err := client.Do(req)
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, 0, err
 	}
 
 	defer func() { _ = response.Body.Close() }()
@@ -45,7 +45,20 @@ func (a *ApiClient) doRequest(Req ClientRequest) (ResponseData []byte, StatusCod
 		Str("Url", apiurl).
 		Msgf("Response %s", responseData)
 
-	return responseData, response.StatusCode, err
+	return responseData, response.Header, response.StatusCode, err
+}
+
+// nextPage returns endpoint of the next page advertised in the Link header
+func nextPage(header http.Header) string {
+	link := header.Get("Link")
+	if !strings.HasSuffix(link, `rel="next"`) {
+		return ""
+	}
+	start, end := strings.Index(link, "<"), strings.Index(link, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return link[start+1 : end]
 }
 
 func NewStaticToken(id string) string {
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/CTO2BPublic/passage-server/pkg/models"
@@ -23,9 +24,11 @@ type Config struct {
 	Events        EventsConfig
 	Log           LogConfig
 	Db            DbConfig
+	Archive       ArchiveConfig
 	Creds         map[string]models.Credential `json:"-"`
 	Roles         []models.AccessRole
 	ApprovalRules []models.ApprovalRule
+	Quotas        []models.Quota
 	SharedSecret  string `json:"-"`
 }
 
@@ -33,8 +36,15 @@ type SwaggerConfig struct {
 	Host string
 }
 type AuthConfig struct {
-	OIDC AuthOIDC
-	JWT  AuthJWT
+	OIDC   AuthOIDC
+	JWT    AuthJWT
+	Admins AuthAdmins
+}
+
+// AuthAdmins lists users and groups allowed to manage archived records
+type AuthAdmins struct {
+	Users  []string
+	Groups []string
 }
 
 type AuthOIDC struct {
@@ -125,6 +135,13 @@ type SqliteConfig struct {
 	Filename string
 }
 
+// ArchiveConfig controls retention of soft deleted records
+type ArchiveConfig struct {
+	// Retention is a duration (e.g. "2160h") after which archived access requests are purged.
+	// Empty value disables purging
+	Retention string
+}
+
 var k = koanf.New(".")
 var configData Config
 
@@ -163,6 +180,12 @@ func InitConfig(dir string) error {
 		configData.SharedSecret = generateRandomSecret()
 	}
 
+	for _, role := range configData.Roles {
+		if err := role.Standing.Validate(); err != nil {
+			return fmt.Errorf("invalid role %s: %v", role.Name, err)
+		}
+	}
+
 	return nil
 }
 
@@ -191,6 +214,19 @@ func (c *Config) GetCredentials(provider string) models.Credential {
 	return models.Credential{}
 }
 
+// IsAdmin checks if the user is explicitly listed or belongs to one of the admin groups
+func (a *AuthAdmins) IsAdmin(user string, groups []string) bool {
+	if slices.Contains(a.Users, user) {
+		return true
+	}
+	for _, group := range groups {
+		if slices.Contains(a.Groups, group) {
+			return true
+		}
+	}
+	return false
+}
+
 func generateRandomSecret() string {
 	bytes := make([]byte, 32) // 256-bit secret
 	_, err := rand.Read(bytes)
@@ -21,22 +21,44 @@ func NewEventController() *EventController {
 // @Security JWT
 // @Summary List events
 // @Schemes
-// @Description List all events
+// @Description List events. Supports filtering, sorting and cursor pagination. Total count and next page are returned in X-Total-Count and Link headers
 // @Tags Events
 // @Accept json
 // @Produce json
+// @Param filter query models.EventFilter false "Filters"
+// @Param page query models.Pagination false "Pagination"
 // @Success 200 {object} []models.Event
+// @Header 200 {integer} X-Total-Count "Total number of matching records"
+// @Header 200 {string} Link "Link to the next page"
 // @Router /events [get]
 func (r *EventController) List(c *gin.Context) {
 
 	ctx, span := tracing.NewSpanWrapper(c.Request.Context(), "controllers.eventController.List")
 	defer span.End()
 
-	data, err := Db.SelectEvents(ctx)
+	filter := models.EventFilter{}
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(err))
+		return
+	}
+
+	page := models.Pagination{}
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(err))
+		return
+	}
+	if err := page.Validate(); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(err))
+		return
+	}
+
+	data, info, err := Db.SelectFilteredEvents(ctx, filter, page)
 	if err != nil {
 		c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
+		return
 	}
 
+	setPaginationHeaders(c, info)
 	c.JSON(200, data)
 }
 
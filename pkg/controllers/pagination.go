@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setPaginationHeaders sets X-Total-Count and Link headers pointing to the next page
+func setPaginationHeaders(c *gin.Context, page models.Page) {
+
+	c.Header("X-Total-Count", strconv.FormatInt(page.Total, 10))
+
+	if page.NextCursor == "" {
+		return
+	}
+
+	next := *c.Request.URL
+	query := next.Query()
+	query.Set("cursor", page.NextCursor)
+	next.RawQuery = query.Encode()
+
+	c.Header("Link", fmt.Sprintf("<%s>; rel=\"next\"", next.RequestURI()))
+}
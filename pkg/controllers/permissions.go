@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+)
+
+// isAdmin checks if caller can manage archived records. Internal tokens are always allowed
+func isAdmin(uid string, groups []string, utype string) bool {
+	if utype == "token" {
+		return true
+	}
+	return Config.Auth.Admins.IsAdmin(uid, groups)
+}
+
+// hasPermissions checks approval permissions honouring active delegations. Returns approver on whose behalf the user acts
+func hasPermissions(ctx context.Context, request *models.AccessRequest, uid string, groups []string, utype string) (allowed bool, onBehalfOf string, err error) {
+
+	if request.HasPermissions(uid, groups, utype) {
+		return true, "", nil
+	}
+
+	delegations, err := getActiveDelegations(ctx, uid)
+	if err != nil {
+		return false, "", err
+	}
+
+	delegator := request.GetDelegator(delegations)
+	return delegator != "", delegator, nil
+}
+
+// getActiveDelegations returns approval delegations to the user currently in effect
+func getActiveDelegations(ctx context.Context, uid string) ([]models.Delegation, error) {
+
+	return Db.SelectActiveDelegations(ctx, uid, time.Now())
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasPermissionsDelegation(t *testing.T) {
+	testDatabase(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, id := range []string{"jane.doe", "bob"} {
+		require.NoError(t, Db.InsertUserProfile(ctx, models.UserProfile{Id: id}))
+	}
+	for _, delegation := range []models.Delegation{
+		{Id: "active", Delegator: "jane.doe", Delegate: "john.doe", StartsAt: now.Add(-time.Hour), Until: now.Add(time.Hour)},
+		{Id: "ended", Delegator: "bob", Delegate: "john.doe", StartsAt: now.Add(-2 * time.Hour), Until: now.Add(-time.Hour)},
+		{Id: "future", Delegator: "bob", Delegate: "carol", StartsAt: now.Add(time.Hour), Until: now.Add(2 * time.Hour)},
+	} {
+		require.NoError(t, Db.InsertDelegation(ctx, delegation))
+	}
+
+	request := &models.AccessRequest{}
+	request.SetApprovalRule(models.ApprovalRule{Users: []string{"jane.doe"}})
+
+	// Approver listed in the rule
+	allowed, onBehalfOf, err := hasPermissions(ctx, request, "jane.doe", nil, "user")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Empty(t, onBehalfOf)
+
+	// Active delegation of a listed approver
+	allowed, onBehalfOf, err = hasPermissions(ctx, request, "john.doe", nil, "user")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, "jane.doe", onBehalfOf)
+
+	// Delegator is not an approver
+	request.SetApprovalRule(models.ApprovalRule{Users: []string{"bob"}})
+	allowed, _, err = hasPermissions(ctx, request, "john.doe", nil, "user")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// Delegation not started yet
+	allowed, _, err = hasPermissions(ctx, request, "carol", nil, "user")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	delegations, err := getActiveDelegations(ctx, "john.doe")
+	require.NoError(t, err)
+	require.Len(t, delegations, 1)
+	assert.Equal(t, "active", delegations[0].Id)
+}
@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProviderController struct {
+}
+
+func NewProviderController() *ProviderController {
+
+	controller := ProviderController{}
+
+	return &controller
+}
+
+// @Security JWT
+// @Summary List providers
+// @Schemes
+// @Description List registered provider kinds with their parameters and credential keys
+// @Tags Providers
+// @Accept json
+// @Produce json
+// @Success 200 {object} []registry.Descriptor
+// @Router /providers [get]
+func (r *ProviderController) List(c *gin.Context) {
+
+	c.JSON(200, registry.Descriptors())
+
+}
@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+)
+
+// getQuotaUsage returns usage of quotas applying to role. Nil role returns usage of all quotas
+func getQuotaUsage(ctx context.Context, user string, role *models.AccessRole) ([]models.QuotaUsage, error) {
+
+	usages := []models.QuotaUsage{}
+	if len(Config.Quotas) == 0 {
+		return usages, nil
+	}
+
+	requests, _, err := Db.SelectFilteredAccessRequests(ctx, models.AccessRequestFilter{Requester: user}, models.Pagination{})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, quota := range Config.Quotas {
+		if role != nil && !quota.Matches(*role) {
+			continue
+		}
+
+		usage, err := quota.Usage(requests, Config.Roles, now)
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, usage)
+	}
+
+	return usages, nil
+}
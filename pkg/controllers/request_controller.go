@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/CTO2BPublic/passage-server/pkg/errors"
 	"github.com/CTO2BPublic/passage-server/pkg/models"
@@ -60,9 +61,16 @@ func (r *AccessRequestController) Create(c *gin.Context) {
 	}
 
 	// Retrieve role
-	accessRole, err := data.GetRole(r.Roles)
+	accessRole, err := r.getRole(&data)
 	if err != nil {
 		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(err))
+		return
+	}
+
+	// Empty TTL is only allowed for roles permitting standing access
+	if err := data.ValidateTTL(accessRole); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(err))
+		return
 	}
 
 	// Retrieve approval role
@@ -75,22 +83,31 @@ func (r *AccessRequestController) Create(c *gin.Context) {
 		SetRequester(uid).
 		SetTraceId(ctx).
 		SetApprovalRule(approvalRule).
-		SetExpiration(ctx)
+		SetExpiration(ctx).
+		SetRecertificationPolicy(accessRole.Standing)
 
-	// Retrieve ProviderUsernames from UserProfile
-	profile, err := Db.SelectUserProfile(ctx, models.UserProfile{Id: uid})
+	// Enforce quotas
+	usages, err := getQuotaUsage(ctx, uid, &accessRole)
 	if err != nil {
-		c.AbortWithStatusJSON(errors.ErrorInvalidUserProfile(err))
+		c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
 		return
 	}
+	for _, usage := range usages {
+		if err := usage.CheckCreate(data); err != nil {
+			c.AbortWithStatusJSON(errors.ErrorQuotaExceeded(err))
+			return
+		}
+	}
 
-	// Validate profile
-	err = profile.Validate()
+	// Retrieve ProviderUsernames from UserProfile
+	profile, err := Db.SelectUserProfile(ctx, models.UserProfile{Id: uid})
 	if err != nil {
 		c.AbortWithStatusJSON(errors.ErrorInvalidUserProfile(err))
 		return
 	}
+
 	data.SetProviderUsernames(profile.Settings.ProviderUsernames.ProviderUsernames)
+	data.SetSSHPublicKey(profile.Settings.SSHPublicKey)
 
 	// ProvideUsernames from Traits should always override UserProfile
 	if Config.Auth.JWT.ProviderUsernamesClaim != "" {
@@ -102,6 +119,12 @@ func (r *AccessRequestController) Create(c *gin.Context) {
 		}
 	}
 
+	// Only providers of the requested role need a username
+	if err := data.ValidateProviderUsernames(accessRole, providers.RequiresUsername); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorInvalidUserProfile(err))
+		return
+	}
+
 	// Save it to DB
 	if err := Db.InsertAccessRequest(ctx, data); err != nil {
 		c.AbortWithStatusJSON(errors.ErrorDatabaseInsert(err))
@@ -119,11 +142,15 @@ func (r *AccessRequestController) Create(c *gin.Context) {
 // @Security JWT
 // @Summary List access requests
 // @Schemes
-// @Description List all access requests
+// @Description List access requests created by the user or those the user has permission to approve. Supports filtering, sorting and cursor pagination. Total count and next page are returned in X-Total-Count and Link headers
 // @Tags Access requests
 // @Accept json
 // @Produce json
+// @Param filter query models.AccessRequestFilter false "Filters"
+// @Param page query models.Pagination false "Pagination"
 // @Success 200 {object} []models.AccessRequest
+// @Header 200 {integer} X-Total-Count "Total number of matching records"
+// @Header 200 {string} Link "Link to the next page"
 // @Router /access/requests [get]
 func (r *AccessRequestController) List(c *gin.Context) {
 
@@ -134,20 +161,55 @@ func (r *AccessRequestController) List(c *gin.Context) {
 	groups := c.GetStringSlice("groups")
 	utype := c.GetString("utype")
 
-	data, err := Db.SelectAccessRequests(ctx)
-	if err != nil {
-		c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
+	filter := models.AccessRequestFilter{}
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(err))
+		return
+	}
+
+	page := models.Pagination{}
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(err))
+		return
+	}
+	if err := page.Validate(); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(err))
+		return
 	}
 
-	// Filter requests to include only those created by the user or those the user has permission to approve
-	filtered := []models.AccessRequest{}
-	for _, request := range data {
-		if request.HasPermissions(uid, groups, utype) || request.Status.RequestedBy == uid {
-			filtered = append(filtered, request)
+	if filter.Tag != "" {
+		filter.RoleNames = models.GetRoleNamesByTag(r.Roles, filter.Tag)
+	}
+
+	// Include only requests created by the user or those the user has permission to approve
+	if utype != "token" {
+		delegations, err := getActiveDelegations(ctx, uid)
+		if err != nil {
+			c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
+			return
+		}
+
+		// Approvers who delegated to the user
+		users := []string{uid}
+		for _, d := range delegations {
+			users = append(users, d.Delegator)
+		}
+
+		filter.Visibility = &models.AccessRequestAccess{
+			Requester: uid,
+			Users:     users,
+			Groups:    groups,
 		}
 	}
 
-	c.JSON(200, filtered)
+	data, info, err := Db.SelectFilteredAccessRequests(ctx, filter, page)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
+		return
+	}
+
+	setPaginationHeaders(c, info)
+	c.JSON(200, data)
 }
 
 // @Security JWT
@@ -175,8 +237,12 @@ func (r *AccessRequestController) Get(c *gin.Context) {
 		c.AbortWithStatusJSON(errors.ErrorDatabaseRecordNotFound())
 		return
 	}
-	// Check if user is allowed
-	allowed := accessRequest.HasPermissions(uid, groups, utype)
+	// Check if user is allowed directly or via delegation
+	allowed, _, err := hasPermissions(ctx, accessRequest, uid, groups, utype)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
+		return
+	}
 	if !allowed {
 		c.AbortWithStatusJSON(errors.StatusDenied())
 		return
@@ -188,7 +254,7 @@ func (r *AccessRequestController) Get(c *gin.Context) {
 // @Security JWT
 // @Summary Delete access request
 // @Schemes
-// @Description Delete access request by id
+// @Description Archive access request by id. Active access is revoked first, deletion is refused if revocation fails
 // @Tags Access requests
 // @Accept json
 // @Produce json
@@ -211,13 +277,55 @@ func (r *AccessRequestController) Delete(c *gin.Context) {
 		return
 	}
 
-	// Check if user is allowed
-	allowed := accessRequest.HasPermissions(uid, groups, utype)
+	// Check if user is allowed directly or via delegation
+	allowed, _, err := hasPermissions(ctx, accessRequest, uid, groups, utype)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
+		return
+	}
 	if !allowed {
 		c.AbortWithStatusJSON(errors.StatusDenied())
 		return
 	}
 
+	// Revoke active access before archiving, so nothing is left behind without expiration
+	if accessRequest.IsActive() {
+		accessRole, err := r.getRole(accessRequest)
+		if err != nil {
+			c.AbortWithStatusJSON(errors.ErrorActiveAccessRevocation(err))
+			return
+		}
+
+		err = r.callRoleProvidersAsync(ctx, providerMethodExpire, accessRequest, accessRole)
+		if err != nil {
+			// Persist provider statuses so failures are visible
+			if err := Db.UpdateAccessRequest(ctx, accessRequest); err != nil {
+				log.Error().Err(err).Msg("failed to update access request provider statuses")
+			}
+			c.AbortWithStatusJSON(errors.ErrorActiveAccessRevocation(err))
+			return
+		}
+
+		accessRequest.
+			SetStatusExpired().
+			SetTraceId(ctx)
+
+		if err := Event.AccessRequestExpired(ctx, *accessRequest); err != nil {
+			log.Error().Err(err).Msg("failed to fire AccessRequestExpired event")
+		}
+	}
+
+	// Undelivered credentials must not outlive the request
+	if err := Db.DeleteAccessCredentials(ctx, *accessRequest); err != nil {
+		log.Error().Err(err).Str("AccessRequest", accessRequest.Id).Msg("failed to delete issued credentials")
+	}
+
+	accessRequest.SetDeletedBy(uid)
+	if err := Db.UpdateAccessRequest(ctx, accessRequest); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseUpdate(err))
+		return
+	}
+
 	err = Db.DeleteAccessRequest(ctx, models.AccessRequest{Id: id})
 	if err != nil {
 		c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
@@ -231,6 +339,124 @@ func (r *AccessRequestController) Delete(c *gin.Context) {
 	c.JSON(errors.StatusDeleted())
 }
 
+// @Security JWT
+// @Summary List archived access requests
+// @Schemes
+// @Description List deleted access requests. Admin only
+// @Tags Access requests
+// @Accept json
+// @Produce json
+// @Success 200 {object} []models.AccessRequest
+// @Router /access/requests/archived [get]
+func (r *AccessRequestController) ListArchived(c *gin.Context) {
+
+	ctx, span := tracing.NewSpanWrapper(c.Request.Context(), "controllers.RequestController.ListArchived")
+	defer span.End()
+
+	uid := c.GetString("uid")
+	groups := c.GetStringSlice("groups")
+	utype := c.GetString("utype")
+
+	if !isAdmin(uid, groups, utype) {
+		c.AbortWithStatusJSON(errors.StatusDenied())
+		return
+	}
+
+	data, err := Db.SelectArchivedAccessRequests(ctx)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
+		return
+	}
+
+	c.JSON(200, data)
+}
+
+// @Security JWT
+// @Summary Restore access request
+// @Schemes
+// @Description Restore archived access request. Admin only. Revoked access is not granted again
+// @Tags Access requests
+// @Accept json
+// @Produce json
+// @Success 200 {object} ResponseSuccess
+// @Router /access/requests/{ID}/restore [post]
+// @Param ID path string true "AccessRequest id" default(xxxx-xxxx-xxxx)
+func (r *AccessRequestController) Restore(c *gin.Context) {
+
+	ctx, span := tracing.NewSpanWrapper(c.Request.Context(), "controllers.RequestController.Restore")
+	defer span.End()
+
+	id := c.Param("ID")
+	uid := c.GetString("uid")
+	groups := c.GetStringSlice("groups")
+	utype := c.GetString("utype")
+
+	if !isAdmin(uid, groups, utype) {
+		c.AbortWithStatusJSON(errors.StatusDenied())
+		return
+	}
+
+	accessRequest, err := Db.SelectArchivedAccessRequest(ctx, models.AccessRequest{Id: id})
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseRecordNotFound())
+		return
+	}
+
+	if err := Db.RestoreAccessRequest(ctx, *accessRequest); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseUpdate(err))
+		return
+	}
+
+	if err := Event.AccessRequestRestored(ctx, *accessRequest); err != nil {
+		log.Error().Err(err).Msg("failed to fire AccessRequestRestored event")
+	}
+
+	c.JSON(errors.StatusUpdated())
+}
+
+// @Security JWT
+// @Summary Purge access request
+// @Schemes
+// @Description Permanently delete archived access request. Admin only
+// @Tags Access requests
+// @Accept json
+// @Produce json
+// @Success 200 {object} ResponseSuccessDeleted
+// @Router /access/requests/{ID}/purge [delete]
+// @Param ID path string true "AccessRequest id" default(xxxx-xxxx-xxxx)
+func (r *AccessRequestController) Purge(c *gin.Context) {
+
+	ctx, span := tracing.NewSpanWrapper(c.Request.Context(), "controllers.RequestController.Purge")
+	defer span.End()
+
+	id := c.Param("ID")
+	uid := c.GetString("uid")
+	groups := c.GetStringSlice("groups")
+	utype := c.GetString("utype")
+
+	if !isAdmin(uid, groups, utype) {
+		c.AbortWithStatusJSON(errors.StatusDenied())
+		return
+	}
+
+	accessRequest, err := Db.SelectArchivedAccessRequest(ctx, models.AccessRequest{Id: id})
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseRecordNotFound())
+		return
+	}
+
+	if err := Db.PurgeAccessRequest(ctx, *accessRequest); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseUpdate(err))
+		return
+	}
+
+	if err := Event.AccessRequestPurged(ctx, *accessRequest); err != nil {
+		log.Error().Err(err).Msg("failed to fire AccessRequestPurged event")
+	}
+
+	c.JSON(errors.StatusDeleted())
+}
+
 // @Security JWT
 // @Summary Approve access request
 // @Schemes
@@ -258,21 +484,44 @@ func (r *AccessRequestController) Approve(c *gin.Context) {
 	}
 
 	// Find role
-	accessRole, err := accessRequest.GetRole(r.Roles)
+	accessRole, err := r.getRole(accessRequest)
 	if err != nil {
 		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(err))
 		return
 	}
 
-	// Check if user is allowed
-	allowed := accessRequest.HasPermissions(uid, groups, utype)
+	// Check if user is allowed directly or via delegation
+	allowed, onBehalfOf, err := hasPermissions(ctx, accessRequest, uid, groups, utype)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
+		return
+	}
 	if !allowed {
 		c.AbortWithStatusJSON(errors.StatusDenied())
 		return
 	}
 
+	// Enforce requester quotas
+	usages, err := getQuotaUsage(ctx, accessRequest.Status.RequestedBy, &accessRole)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
+		return
+	}
+	for _, usage := range usages {
+		if err := usage.CheckApprove(*accessRequest); err != nil {
+			c.AbortWithStatusJSON(errors.ErrorQuotaExceeded(err))
+			return
+		}
+	}
+
 	// Call role providers
 	err = r.callRoleProvidersAsync(ctx, providerMethodApprove, accessRequest, accessRole)
+
+	// Keep issued credentials for one-time delivery, also on partial success
+	if err := Db.InsertAccessCredentials(ctx, accessRequest.Credentials); err != nil {
+		log.Error().Err(err).Str("AccessRequest", accessRequest.Id).Msg("failed to store issued credentials")
+	}
+
 	// Partial success
 	if err != nil {
 		c.AbortWithStatusJSON(errors.AccessProviderCallPartiallyFailed(err))
@@ -282,10 +531,13 @@ func (r *AccessRequestController) Approve(c *gin.Context) {
 	// Update request status
 	accessRequest.
 		SetStatusApprove(uid).
+		SetApprovedOnBehalfOf(onBehalfOf).
+		ScheduleRecertification(time.Now()).
 		SetTraceId(ctx)
 
 	if err := Db.UpdateAccessRequest(ctx, accessRequest); err != nil {
 		c.AbortWithStatusJSON(errors.ErrorDatabaseUpdate(err))
+		return
 	}
 
 	// Fire approval event
@@ -323,14 +575,18 @@ func (r *AccessRequestController) Expire(c *gin.Context) {
 	}
 
 	// Find role
-	accessRole, err := accessRequest.GetRole(r.Roles)
+	accessRole, err := r.getRole(accessRequest)
 	if err != nil {
 		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(err))
 		return
 	}
 
-	// Check if user is allowed
-	allowed := accessRequest.HasPermissions(uid, groups, utype)
+	// Check if user is allowed directly or via delegation
+	allowed, _, err := hasPermissions(ctx, accessRequest, uid, groups, utype)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
+		return
+	}
 	if !allowed {
 		c.AbortWithStatusJSON(errors.StatusDenied())
 		return
@@ -343,6 +599,11 @@ func (r *AccessRequestController) Expire(c *gin.Context) {
 		return
 	}
 
+	// Undelivered credentials are no longer valid
+	if err := Db.DeleteAccessCredentials(ctx, *accessRequest); err != nil {
+		log.Error().Err(err).Str("AccessRequest", accessRequest.Id).Msg("failed to delete issued credentials")
+	}
+
 	// Update request status
 	accessRequest.
 		SetStatusExpired().
@@ -360,6 +621,181 @@ func (r *AccessRequestController) Expire(c *gin.Context) {
 	c.JSON(errors.StatusUpdated())
 }
 
+// @Security JWT
+// @Summary Retrieve access credentials
+// @Schemes
+// @Description Returns credentials issued by role providers on approval, e.g. generated passwords, short-lived tokens or SSH certificates. Available only to the requester and only once
+// @Tags Access requests
+// @Accept json
+// @Produce json
+// @Success 200 {object} []models.AccessCredential
+// @Router /access/requests/{ID}/credentials [get]
+// @Param ID path string true "AccessRequest id" default(xxxx-xxxx-xxxx)
+func (r *AccessRequestController) Credentials(c *gin.Context) {
+
+	ctx, span := tracing.NewSpanWrapper(c.Request.Context(), "controllers.RequestController.Credentials")
+	defer span.End()
+
+	id := c.Param("ID")
+	uid := c.GetString("uid")
+
+	accessRequest, err := Db.SelectAccessRequest(ctx, models.AccessRequest{Id: id})
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseRecordNotFound())
+		return
+	}
+
+	if accessRequest.Status.RequestedBy != uid {
+		c.AbortWithStatusJSON(errors.StatusDenied())
+		return
+	}
+
+	if accessRequest.Status.Status != models.AccessRequestApproved {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseRecordNotFound())
+		return
+	}
+
+	credentials, err := Db.PopAccessCredentials(ctx, *accessRequest)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseRecordNotFound())
+		return
+	}
+
+	if err := Event.AccessRequestCredentialsRetrieved(ctx, *accessRequest, credentials); err != nil {
+		log.Error().Err(err).Msg("failed to fire AccessRequestCredentialsRetrieved event")
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(200, credentials)
+}
+
+// @Security JWT
+// @Summary Request recertification
+// @Schemes
+// @Description Ask approvers to recertify standing access. Called by the scheduler when recertification comes due. Grant is expired if nobody recertifies within the role grace period
+// @Tags Access requests
+// @Accept json
+// @Produce json
+// @Success 200 {object} ResponseSuccess
+// @Router /access/requests/{ID}/recertification [post]
+// @Param ID path string true "AccessRequest id" default(xxxx-xxxx-xxxx)
+func (r *AccessRequestController) RequestRecertification(c *gin.Context) {
+
+	ctx, span := tracing.NewSpanWrapper(c.Request.Context(), "controllers.RequestController.RequestRecertification")
+	defer span.End()
+
+	id := c.Param("ID")
+	uid := c.GetString("uid")
+	groups := c.GetStringSlice("groups")
+	utype := c.GetString("utype")
+
+	accessRequest, err := Db.SelectAccessRequest(ctx, models.AccessRequest{Id: id})
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseRecordNotFound())
+		return
+	}
+
+	// Check if user is allowed directly or via delegation
+	allowed, _, err := hasPermissions(ctx, accessRequest, uid, groups, utype)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
+		return
+	}
+	if !allowed {
+		c.AbortWithStatusJSON(errors.StatusDenied())
+		return
+	}
+
+	if !accessRequest.IsActive() || accessRequest.Status.Recertification == nil {
+		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(fmt.Errorf("access request %s is not an active standing grant", id)))
+		return
+	}
+
+	// Update request status
+	accessRequest.
+		SetRecertificationRequested(time.Now()).
+		SetTraceId(ctx)
+
+	if err := Db.UpdateAccessRequest(ctx, accessRequest); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseUpdate(err))
+		return
+	}
+
+	if err := Event.AccessRequestRecertificationRequested(ctx, *accessRequest); err != nil {
+		log.Error().Err(err).Msg("failed to fire AccessRequestRecertificationRequested event")
+	}
+
+	c.JSON(errors.StatusUpdated())
+}
+
+// @Security JWT
+// @Summary Recertify access request
+// @Schemes
+// @Description Confirm that standing access is still required. Schedules the next recertification
+// @Tags Access requests
+// @Accept json
+// @Produce json
+// @Success 200 {object} ResponseSuccess
+// @Router /access/requests/{ID}/recertify [post]
+// @Param ID path string true "AccessRequest id" default(xxxx-xxxx-xxxx)
+func (r *AccessRequestController) Recertify(c *gin.Context) {
+
+	ctx, span := tracing.NewSpanWrapper(c.Request.Context(), "controllers.RequestController.Recertify")
+	defer span.End()
+
+	id := c.Param("ID")
+	uid := c.GetString("uid")
+	groups := c.GetStringSlice("groups")
+	utype := c.GetString("utype")
+
+	accessRequest, err := Db.SelectAccessRequest(ctx, models.AccessRequest{Id: id})
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseRecordNotFound())
+		return
+	}
+
+	// Check if user is allowed directly or via delegation
+	allowed, _, err := hasPermissions(ctx, accessRequest, uid, groups, utype)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
+		return
+	}
+	if !allowed {
+		c.AbortWithStatusJSON(errors.StatusDenied())
+		return
+	}
+
+	if !accessRequest.IsActive() || accessRequest.Status.Recertification == nil {
+		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(fmt.Errorf("access request %s is not an active standing grant", id)))
+		return
+	}
+
+	// Update request status
+	accessRequest.
+		SetRecertified(uid, time.Now()).
+		SetTraceId(ctx)
+
+	if err := Db.UpdateAccessRequest(ctx, accessRequest); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseUpdate(err))
+		return
+	}
+
+	if err := Event.AccessRequestRecertified(ctx, *accessRequest); err != nil {
+		log.Error().Err(err).Msg("failed to fire AccessRequestRecertified event")
+	}
+
+	c.JSON(errors.StatusUpdated())
+}
+
+// getRole finds request role. Composite roles are resolved into the union of constituent role providers
+func (r *AccessRequestController) getRole(request *models.AccessRequest) (models.AccessRole, error) {
+	role, err := request.GetRole(r.Roles)
+	if err != nil {
+		return role, err
+	}
+	return role.Resolve(r.Roles)
+}
+
 type providerMethod int
 
 const (
@@ -456,6 +892,9 @@ func (r *AccessRequestController) callRoleProvidersAsync(ctx context.Context, me
 		}
 	}
 
+	// Summarize provider statuses per constituent role
+	request.SetRoleStatuses(role)
+
 	// Return combined errors if any
 	if len(errs) > 0 {
 		return fmt.Errorf("errors occurred: %v", errs)
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestAccessRequestCreateProviderUsernames(t *testing.T) {
+	testDatabase(t)
+	ctx := context.Background()
+
+	r := &AccessRequestController{Roles: []models.AccessRole{
+		{Name: "Directory", Providers: []models.ProviderConfig{{Name: "Directory", Provider: testProviderKind}}},
+		{Name: "Cluster", Providers: []models.ProviderConfig{{Name: "Cluster", Provider: testScopedProviderKind}}},
+		{Name: "Credentials", Providers: []models.ProviderConfig{
+			{Name: "AWS", Provider: string(kinds.ProviderKindAWSSTS)},
+			{Name: "SSH", Provider: string(kinds.ProviderKindSSHCA)},
+		}},
+	}}
+
+	// New profile lists all provider kinds with empty usernames
+	profile, err := NewUserController().newDefaultProfile(ctx, "alice")
+	require.NoError(t, err)
+	profile.Settings.ProviderUsernames.ProviderUsernames[testProviderKind] = "alice"
+	require.NoError(t, Db.UpdateUserProfile(ctx, profile))
+
+	create := func(role string) int {
+		request := models.AccessRequest{RoleRef: models.AccessRoleRef{Name: role}}
+		request.Details.TTL = "1h"
+		return serve(r.Create, testCaller{uid: "alice"}, http.MethodPost, nil, request).Code
+	}
+
+	assert.Equal(t, http.StatusCreated, create("Directory"))
+	assert.Equal(t, http.StatusBadRequest, create("Cluster"))
+
+	// Credential providers fall back to the requester
+	assert.Equal(t, http.StatusCreated, create("Credentials"))
+}
+
+func TestAccessRequestDeleteDropsCredentials(t *testing.T) {
+	testDatabase(t)
+	ctx := context.Background()
+
+	request := models.AccessRequest{Id: "req-1", RoleRef: models.AccessRoleRef{Name: "Directory"}}
+	request.SetRequester("alice").SetStatusPending()
+	request.AddCredential("Directory", models.AccessCredentialPassword, map[string]string{"password": "s3cret"}, nil)
+	require.NoError(t, Db.InsertAccessRequest(ctx, request))
+	require.NoError(t, Db.InsertAccessCredentials(ctx, request.Credentials))
+
+	r := &AccessRequestController{}
+	w := serve(r.Delete, testCaller{uid: "cron", utype: "token"}, http.MethodDelete, gin.Params{{Key: "ID", Value: "req-1"}}, nil)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	_, err := Db.PopAccessCredentials(ctx, request)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
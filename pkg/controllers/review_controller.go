@@ -0,0 +1,549 @@
+package controllers
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/errors"
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers"
+	"github.com/CTO2BPublic/passage-server/pkg/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/rs/zerolog/log"
+)
+
+type ReviewCampaignController struct {
+	Requests *AccessRequestController
+}
+
+func NewReviewCampaignController(requests *AccessRequestController) *ReviewCampaignController {
+
+	controller := ReviewCampaignController{
+		Requests: requests,
+	}
+
+	return &controller
+}
+
+// @Security JWT
+// @Summary Create review campaign
+// @Schemes
+// @Description Create access review campaign. Current grants of roles in scope are snapshotted from approved access requests and provider user lists. Admin only
+// @Tags Access reviews
+// @Accept json
+// @Produce json
+// @Param campaign body models.ReviewCampaign true "Review campaign definition"
+// @Success 200 {object} models.ReviewCampaign
+// @Router /reviews/campaigns [post]
+func (r *ReviewCampaignController) Create(c *gin.Context) {
+
+	ctx, span := tracing.NewSpanWrapper(c.Request.Context(), "controllers.ReviewCampaignController.Create")
+	defer span.End()
+
+	uid := c.GetString("uid")
+	groups := c.GetStringSlice("groups")
+	utype := c.GetString("utype")
+
+	if !isAdmin(uid, groups, utype) {
+		c.AbortWithStatusJSON(errors.StatusDenied())
+		return
+	}
+
+	data := models.ReviewCampaign{}
+	err := c.ShouldBindBodyWith(&data, binding.JSON)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(err))
+		return
+	}
+
+	data.
+		Admit().
+		SetCreatedBy(uid)
+
+	if err := r.snapshot(ctx, &data); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
+		return
+	}
+
+	if err := Db.InsertReviewCampaign(ctx, data); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseInsert(err))
+		return
+	}
+
+	if err := Event.ReviewCampaignCreated(ctx, data); err != nil {
+		log.Error().Err(err).Msg("failed to fire ReviewCampaignCreated event")
+	}
+
+	c.JSON(200, data)
+}
+
+// @Security JWT
+// @Summary List review campaigns
+// @Schemes
+// @Description List access review campaigns without items. Admins receive all campaigns, reviewers receive campaigns with items assigned to them
+// @Tags Access reviews
+// @Accept json
+// @Produce json
+// @Success 200 {object} []models.ReviewCampaign
+// @Router /reviews/campaigns [get]
+func (r *ReviewCampaignController) List(c *gin.Context) {
+
+	ctx, span := tracing.NewSpanWrapper(c.Request.Context(), "controllers.ReviewCampaignController.List")
+	defer span.End()
+
+	uid := c.GetString("uid")
+	groups := c.GetStringSlice("groups")
+	utype := c.GetString("utype")
+
+	if isAdmin(uid, groups, utype) {
+		data, err := Db.SelectReviewCampaigns(ctx)
+		if err != nil {
+			c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
+			return
+		}
+
+		c.JSON(200, data)
+		return
+	}
+
+	campaigns, err := Db.SelectReviewCampaignsWithItems(ctx)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
+		return
+	}
+
+	data := []models.ReviewCampaign{}
+	for _, campaign := range campaigns {
+		if campaign.CanReview(uid, groups, utype) {
+			campaign.Items = nil
+			data = append(data, campaign)
+		}
+	}
+
+	c.JSON(200, data)
+}
+
+// @Security JWT
+// @Summary Get review campaign
+// @Schemes
+// @Description Get review campaign. Admins receive all items, reviewers receive items assigned to them
+// @Tags Access reviews
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.ReviewCampaign
+// @Router /reviews/campaigns/{ID} [get]
+// @Param ID path string true "ReviewCampaign id" default(xxxx-xxxx-xxxx)
+func (r *ReviewCampaignController) Get(c *gin.Context) {
+
+	ctx, span := tracing.NewSpanWrapper(c.Request.Context(), "controllers.ReviewCampaignController.Get")
+	defer span.End()
+
+	id := c.Param("ID")
+	uid := c.GetString("uid")
+	groups := c.GetStringSlice("groups")
+	utype := c.GetString("utype")
+
+	data, err := Db.SelectReviewCampaign(ctx, models.ReviewCampaign{Id: id})
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseRecordNotFound())
+		return
+	}
+
+	if !isAdmin(uid, groups, utype) {
+		items := []models.ReviewItem{}
+		for _, item := range data.Items {
+			if item.CanReview(uid, groups, utype) {
+				items = append(items, item)
+			}
+		}
+		data.Items = items
+	}
+
+	c.JSON(200, data)
+}
+
+// @Security JWT
+// @Summary Decide review item
+// @Schemes
+// @Description Record keep or revoke decision. Revoke decisions remove access through role providers
+// @Tags Access reviews
+// @Accept json
+// @Produce json
+// @Param decision body models.ReviewDecision true "Review decision"
+// @Success 200 {object} models.ReviewItem
+// @Router /reviews/campaigns/{ID}/items/{ItemID}/decision [post]
+// @Param ID path string true "ReviewCampaign id" default(xxxx-xxxx-xxxx)
+// @Param ItemID path string true "ReviewItem id" default(xxxx-xxxx-xxxx)
+func (r *ReviewCampaignController) Decide(c *gin.Context) {
+
+	ctx, span := tracing.NewSpanWrapper(c.Request.Context(), "controllers.ReviewCampaignController.Decide")
+	defer span.End()
+
+	id := c.Param("ID")
+	itemId := c.Param("ItemID")
+	uid := c.GetString("uid")
+	groups := c.GetStringSlice("groups")
+	utype := c.GetString("utype")
+
+	decision := models.ReviewDecision{}
+	err := c.ShouldBindBodyWith(&decision, binding.JSON)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(err))
+		return
+	}
+
+	campaign, err := Db.SelectReviewCampaign(ctx, models.ReviewCampaign{Id: id})
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseRecordNotFound())
+		return
+	}
+
+	if campaign.Status.Status == models.ReviewCampaignCompleted {
+		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(fmt.Errorf("review campaign %s is completed", id)))
+		return
+	}
+
+	item, err := Db.SelectReviewItem(ctx, models.ReviewItem{Id: itemId, CampaignId: id})
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseRecordNotFound())
+		return
+	}
+
+	// Check if user is allowed
+	if !item.CanReview(uid, groups, utype) {
+		c.AbortWithStatusJSON(errors.StatusDenied())
+		return
+	}
+
+	// Decisions are final unless revocation failed
+	if item.Decision != models.ReviewDecisionPending && item.Error == "" {
+		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(fmt.Errorf("review item %s is already decided", itemId)))
+		return
+	}
+
+	item.SetDecision(decision, uid, time.Now())
+
+	if item.Decision == models.ReviewDecisionRevoke {
+		if err := r.revoke(ctx, item); err != nil {
+			item.SetError(err)
+		}
+	}
+
+	if err := Db.UpdateReviewItem(ctx, item); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseUpdate(err))
+		return
+	}
+
+	if item.Error != "" {
+		c.AbortWithStatusJSON(errors.AccessProviderCallFailed(fmt.Errorf("%s", item.Error)))
+		return
+	}
+
+	// Complete campaign once all items are decided
+	for i := range campaign.Items {
+		if campaign.Items[i].Id == item.Id {
+			campaign.Items[i] = *item
+		}
+	}
+	if campaign.Complete(time.Now()) {
+		if err := Db.UpdateReviewCampaign(ctx, campaign); err != nil {
+			c.AbortWithStatusJSON(errors.ErrorDatabaseUpdate(err))
+			return
+		}
+
+		if err := Event.ReviewCampaignCompleted(ctx, *campaign); err != nil {
+			log.Error().Err(err).Msg("failed to fire ReviewCampaignCompleted event")
+		}
+	}
+
+	c.JSON(200, item)
+}
+
+// @Security JWT
+// @Summary Review campaign report
+// @Schemes
+// @Description Export review campaign completion report as JSON or CSV. Admin only
+// @Tags Access reviews
+// @Accept json
+// @Produce json,text/csv
+// @Param format query string false "Report format" Enums(json, csv)
+// @Success 200 {object} models.ReviewCampaignReport
+// @Router /reviews/campaigns/{ID}/report [get]
+// @Param ID path string true "ReviewCampaign id" default(xxxx-xxxx-xxxx)
+func (r *ReviewCampaignController) Report(c *gin.Context) {
+
+	ctx, span := tracing.NewSpanWrapper(c.Request.Context(), "controllers.ReviewCampaignController.Report")
+	defer span.End()
+
+	id := c.Param("ID")
+	uid := c.GetString("uid")
+	groups := c.GetStringSlice("groups")
+	utype := c.GetString("utype")
+
+	if !isAdmin(uid, groups, utype) {
+		c.AbortWithStatusJSON(errors.StatusDenied())
+		return
+	}
+
+	campaign, err := Db.SelectReviewCampaign(ctx, models.ReviewCampaign{Id: id})
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseRecordNotFound())
+		return
+	}
+
+	if c.Query("format") != "csv" {
+		c.JSON(200, campaign.Report())
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=review-campaign-%s.csv", campaign.Id))
+	c.Header("Content-Type", "text/csv")
+	c.Status(200)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(models.ReviewItem{}.CSVHeader())
+	for _, item := range campaign.Items {
+		_ = w.Write(item.CSVRecord())
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Error().Err(err).Msg("failed to write review campaign report")
+	}
+}
+
+// snapshot collects current grants of roles in campaign scope. Provider grants shared by composite and
+// constituent roles are reviewed once, under the constituent role when it is in scope
+func (r *ReviewCampaignController) snapshot(ctx context.Context, campaign *models.ReviewCampaign) error {
+
+	ctx, span := tracing.NewSpanWrapper(ctx, "controllers.ReviewCampaignController.snapshot")
+	defer span.End()
+
+	roles := []models.AccessRole{}
+	for _, role := range r.Requests.Roles {
+		role, err := role.Resolve(r.Requests.Roles)
+		if err != nil {
+			campaign.AddWarning(err.Error())
+			continue
+		}
+		roles = append(roles, role)
+	}
+	slices.SortStableFunc(roles, func(a, b models.AccessRole) int {
+		return cmp.Compare(len(a.Roles), len(b.Roles))
+	})
+
+	// Roles granting each provider
+	grantedBy := map[string][]string{}
+	for _, role := range roles {
+		for _, config := range role.Providers {
+			key := providerKey(role, config)
+			grantedBy[key] = append(grantedBy[key], role.Name)
+		}
+	}
+
+	approved := map[string][]models.AccessRequest{}
+	approvedRequests := func(role string) ([]models.AccessRequest, error) {
+		if requests, ok := approved[role]; ok {
+			return requests, nil
+		}
+		requests, _, err := Db.SelectFilteredAccessRequests(ctx, models.AccessRequestFilter{
+			Status: models.AccessRequestApproved,
+			Role:   role,
+		}, models.Pagination{})
+		approved[role] = requests
+		return requests, err
+	}
+
+	listed := map[string]bool{}
+	for _, role := range roles {
+
+		if !campaign.InScope(role) {
+			continue
+		}
+
+		reviewers := role.GetApprovalRule(r.Requests.ApprovalRules)
+
+		requests, err := approvedRequests(role.Name)
+		if err != nil {
+			return err
+		}
+
+		for _, request := range requests {
+			campaign.AddItem(models.ReviewItem{
+				Role:            role.Name,
+				Username:        request.Status.RequestedBy,
+				Requester:       request.Status.RequestedBy,
+				AccessRequestId: request.Id,
+				Reviewers:       reviewers,
+			})
+		}
+
+		// Provider grants not backed by an approved request
+		for _, config := range role.Providers {
+
+			key := providerKey(role, config)
+			if listed[key] || !campaign.ProviderInScope(config) {
+				continue
+			}
+			listed[key] = true
+
+			usernames, err := r.listUsersWithAccess(ctx, role, config)
+			if err != nil {
+				log.Warn().
+					Err(err).
+					Str("Campaign", campaign.Id).
+					Str("Role", role.Name).
+					Str("Provider", config.Name).
+					Msg("Failed to list users with access")
+				campaign.AddWarning(fmt.Sprintf("role %s provider %s: %s", role.Name, config.Name, err.Error()))
+				continue
+			}
+
+			// Requests of any role granting the provider back the grant
+			backing := []models.AccessRequest{}
+			for _, name := range grantedBy[key] {
+				requests, err := approvedRequests(name)
+				if err != nil {
+					return err
+				}
+				backing = append(backing, requests...)
+			}
+
+			for _, username := range usernames {
+				if hasProviderUsername(backing, config.Provider, username) {
+					continue
+				}
+				campaign.AddItem(models.ReviewItem{
+					Role:         role.Name,
+					Provider:     config.Name,
+					ProviderKind: config.Provider,
+					Username:     username,
+					Reviewers:    reviewers,
+				})
+			}
+		}
+	}
+
+	return nil
+}
+
+// providerKey identifies provider of a constituent role regardless of composite roles including it
+func providerKey(role models.AccessRole, config models.ProviderConfig) string {
+	source := config.SourceRole
+	if source == "" {
+		source = role.Name
+	}
+	return source + "/" + config.BaseName()
+}
+
+func (r *ReviewCampaignController) listUsersWithAccess(ctx context.Context, role models.AccessRole, config models.ProviderConfig) ([]string, error) {
+
+	config.Parameters = maps.Clone(config.Parameters)
+
+	provider, err := providers.NewProvider(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.ListUsersWithAccess(ctx, models.AccessRoleRef{Name: role.Name})
+}
+
+// revoke removes access under review. Request backed items are expired, provider only items are revoked at the single provider
+func (r *ReviewCampaignController) revoke(ctx context.Context, item *models.ReviewItem) error {
+
+	ctx, span := tracing.NewSpanWrapper(ctx, "controllers.ReviewCampaignController.revoke")
+	defer span.End()
+
+	// Synthetic request carrying the provider username
+	request := models.AccessRequest{
+		Id:      item.Id,
+		RoleRef: models.AccessRoleRef{Name: item.Role},
+	}
+
+	role, err := r.Requests.getRole(&request)
+	if err != nil {
+		return err
+	}
+
+	if !item.IsProviderOnly() {
+
+		accessRequest, err := Db.SelectAccessRequest(ctx, models.AccessRequest{Id: item.AccessRequestId})
+		if err != nil {
+			return fmt.Errorf("access request %s not found: %w", item.AccessRequestId, err)
+		}
+
+		// Already revoked since the snapshot
+		if !accessRequest.IsActive() {
+			return nil
+		}
+
+		err = r.Requests.callRoleProvidersAsync(ctx, providerMethodExpire, accessRequest, role)
+		if err != nil {
+			return err
+		}
+
+		accessRequest.
+			SetStatusExpired().
+			SetTraceId(ctx)
+
+		if err := Db.UpdateAccessRequest(ctx, accessRequest); err != nil {
+			return err
+		}
+
+		if err := Event.AccessRequestExpired(ctx, *accessRequest); err != nil {
+			log.Error().Err(err).Msg("failed to fire AccessRequestExpired event")
+		}
+		return nil
+	}
+
+	for _, config := range role.Providers {
+		if config.Name != item.Provider {
+			continue
+		}
+
+		request.Status.RequestedBy = item.Username
+		request.SetProviderUsername(config.Provider, item.Username)
+
+		config.Parameters = maps.Clone(config.Parameters)
+		if config.Parameters == nil {
+			config.Parameters = map[string]string{}
+		}
+		config.Parameters["username"] = item.Username
+
+		provider, err := providers.NewProvider(ctx, config)
+		if err != nil {
+			return err
+		}
+
+		// Synthetic request id does not match any grant of request scoped providers
+		if scoped, ok := provider.(providers.RequestScoped); ok && scoped.IsRequestScoped() {
+			return fmt.Errorf("provider %s: %w", config.Name, providers.ErrRevokeUnsupported)
+		}
+
+		log.Info().
+			Str("ReviewItem", item.Id).
+			Str("Role", role.Name).
+			Str("Provider", config.Name).
+			Str("Username", item.Username).
+			Msg("Revoking access found during review")
+
+		return provider.RevokeAccess(ctx, &request)
+	}
+
+	return fmt.Errorf("provider %s not found in role %s", item.Provider, item.Role)
+}
+
+func hasProviderUsername(requests []models.AccessRequest, provider string, username string) bool {
+	for _, request := range requests {
+		if request.GetProviderUsername(provider) == username {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/CTO2BPublic/passage-server/pkg/config"
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReviewController() *ReviewCampaignController {
+	return NewReviewCampaignController(&AccessRequestController{
+		Roles: []models.AccessRole{{
+			Name: "Cluster Admin",
+			Providers: []models.ProviderConfig{
+				{Name: "Cluster", Provider: testScopedProviderKind},
+				{Name: "Directory", Provider: testProviderKind},
+			},
+		}},
+	})
+}
+
+func testCampaign(name string, reviewers models.ApprovalRule, items ...models.ReviewItem) models.ReviewCampaign {
+	campaign := models.ReviewCampaign{Name: name}
+	campaign.Admit()
+	for _, item := range items {
+		item.Reviewers = reviewers
+		campaign.AddItem(item)
+	}
+	return campaign
+}
+
+func TestReviewCampaignList(t *testing.T) {
+	testDatabase(t)
+	admins := Config.Auth.Admins
+	Config.Auth.Admins = config.AuthAdmins{Users: []string{"admin"}}
+	t.Cleanup(func() { Config.Auth.Admins = admins })
+
+	ctx := context.Background()
+	item := models.ReviewItem{Role: "Cluster Admin", Provider: "Directory", ProviderKind: testProviderKind, Username: "alice"}
+	require.NoError(t, Db.InsertReviewCampaign(ctx, testCampaign("Q1", models.ApprovalRule{Users: []string{"bob"}}, item)))
+	require.NoError(t, Db.InsertReviewCampaign(ctx, testCampaign("Q2", models.ApprovalRule{Users: []string{"carol"}}, item)))
+
+	r := testReviewController()
+	names := func(caller testCaller) []string {
+		campaigns := decode[[]models.ReviewCampaign](t, serve(r.List, caller, http.MethodGet, nil, nil))
+		result := []string{}
+		for _, campaign := range campaigns {
+			assert.Empty(t, campaign.Items)
+			result = append(result, campaign.Name)
+		}
+		return result
+	}
+
+	assert.ElementsMatch(t, []string{"Q1", "Q2"}, names(testCaller{uid: "admin"}))
+	assert.ElementsMatch(t, []string{"Q1", "Q2"}, names(testCaller{uid: "cron", utype: "token"}))
+	assert.Equal(t, []string{"Q1"}, names(testCaller{uid: "bob"}))
+	assert.Empty(t, names(testCaller{uid: "dave"}))
+}
+
+func TestReviewCampaignDecideProviderOnly(t *testing.T) {
+	testDatabase(t)
+	ctx := context.Background()
+
+	campaign := testCampaign("Q1", models.ApprovalRule{Users: []string{"bob"}},
+		models.ReviewItem{Role: "Cluster Admin", Provider: "Cluster", ProviderKind: testScopedProviderKind, Username: "alice"},
+		models.ReviewItem{Role: "Cluster Admin", Provider: "Directory", ProviderKind: testProviderKind, Username: "alice"},
+	)
+	require.NoError(t, Db.InsertReviewCampaign(ctx, campaign))
+
+	r := testReviewController()
+	revokedUsernames()
+
+	decide := func(item models.ReviewItem, caller testCaller) *models.ReviewItem {
+		w := serve(r.Decide, caller, http.MethodPost, gin.Params{{Key: "ID", Value: campaign.Id}, {Key: "ItemID", Value: item.Id}}, models.ReviewDecision{Decision: models.ReviewDecisionRevoke})
+		stored, err := Db.SelectReviewItem(ctx, models.ReviewItem{Id: item.Id, CampaignId: campaign.Id})
+		require.NoError(t, err)
+		if stored.Error == "" {
+			assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		} else {
+			assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		}
+		return stored
+	}
+
+	// Grants keyed on request id cannot be found by username
+	scoped := decide(campaign.Items[0], testCaller{uid: "bob"})
+	assert.Contains(t, scoped.Error, "not supported")
+	assert.Empty(t, revokedUsernames())
+
+	plain := decide(campaign.Items[1], testCaller{uid: "bob"})
+	assert.Empty(t, plain.Error)
+	assert.Equal(t, models.ReviewDecisionRevoke, plain.Decision)
+	assert.Equal(t, []string{"alice"}, revokedUsernames())
+
+	// Not a reviewer
+	w := serve(r.Decide, testCaller{uid: "dave"}, http.MethodPost, gin.Params{{Key: "ID", Value: campaign.Id}, {Key: "ItemID", Value: campaign.Items[0].Id}}, models.ReviewDecision{Decision: models.ReviewDecisionKeep})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestReviewCampaignSnapshotComposite(t *testing.T) {
+	testDatabase(t)
+	ctx := context.Background()
+
+	r := NewReviewCampaignController(&AccessRequestController{
+		Roles: []models.AccessRole{
+			{Name: "Platform", Roles: []models.AccessRoleRef{{Name: "Directory Admin"}}},
+			{Name: "Directory Admin", Providers: []models.ProviderConfig{
+				{Name: "Directory", Provider: testProviderKind, Parameters: map[string]string{"users": "alice,bob,carol"}},
+			}},
+		},
+	})
+
+	for role, requester := range map[string]string{"Directory Admin": "alice", "Platform": "bob"} {
+		request := models.AccessRequest{Id: "req-" + requester, RoleRef: models.AccessRoleRef{Name: role}}
+		request.SetRequester(requester).SetProviderUsername(testProviderKind, requester)
+		request.SetStatusApprove("admin")
+		require.NoError(t, Db.InsertAccessRequest(ctx, request))
+	}
+
+	campaign := models.ReviewCampaign{Name: "Q1"}
+	campaign.Admit()
+	require.NoError(t, r.snapshot(ctx, &campaign))
+
+	items := []string{}
+	for _, item := range campaign.Items {
+		items = append(items, item.Role+"|"+item.AccessRequestId+"|"+item.Provider+"|"+item.Username)
+	}
+
+	// Grant backed by the composite role request is not reported, unbacked grant is reviewed once
+	assert.ElementsMatch(t, []string{
+		"Directory Admin|req-alice||alice",
+		"Platform|req-bob||bob",
+		"Directory Admin||Directory|carol",
+	}, items)
+}
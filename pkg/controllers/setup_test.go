@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const (
+	testProviderKind       = "controllertest"
+	testScopedProviderKind = "controllertestscoped"
+)
+
+// testProvider records usernames revoked by controllers and lists users set in the users parameter
+type testProvider struct {
+	scoped bool
+	users  []string
+}
+
+var (
+	revokedMu sync.Mutex
+	revoked   []string
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+
+	for _, scoped := range []bool{false, true} {
+		kind := testProviderKind
+		if scoped {
+			kind = testScopedProviderKind
+		}
+		registry.Register(registry.Descriptor{Kind: kind, RequiresUsername: true}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+			users := []string{}
+			if config.Parameters["users"] != "" {
+				users = strings.Split(config.Parameters["users"], ",")
+			}
+			return &testProvider{scoped: scoped, users: users}, nil
+		})
+	}
+}
+
+func (p *testProvider) GrantAccess(ctx context.Context, request *models.AccessRequest) error {
+	return nil
+}
+
+func (p *testProvider) RevokeAccess(ctx context.Context, request *models.AccessRequest) error {
+	revokedMu.Lock()
+	defer revokedMu.Unlock()
+	revoked = append(revoked, request.Status.RequestedBy)
+	return nil
+}
+
+func (p *testProvider) ListUsersWithAccess(ctx context.Context, role models.AccessRoleRef) ([]string, error) {
+	return p.users, nil
+}
+
+func (p *testProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
+	return false, nil
+}
+
+func (p *testProvider) IsRequestScoped() bool {
+	return p.scoped
+}
+
+// revokedUsernames returns and clears usernames revoked by test providers
+func revokedUsernames() []string {
+	revokedMu.Lock()
+	defer revokedMu.Unlock()
+	result := slices.Clone(revoked)
+	revoked = nil
+	return result
+}
+
+// testDatabase points controllers to an empty sqlite database for the duration of the test
+func testDatabase(t *testing.T) {
+	engine, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	require.NoError(t, err)
+
+	previous := Db.Engine
+	Db.Engine = engine
+	Db.AutoMigrate()
+	t.Cleanup(func() { Db.Engine = previous })
+}
+
+// testCaller identifies the user calling a handler
+type testCaller struct {
+	uid    string
+	groups []string
+	utype  string
+}
+
+// serve calls handler as caller and returns the response
+func serve(handler gin.HandlerFunc, caller testCaller, method string, params gin.Params, body any) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	var data []byte
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+	c.Request = httptest.NewRequest(method, "/", bytes.NewReader(data))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+
+	utype := caller.utype
+	if utype == "" {
+		utype = "user"
+	}
+	c.Set("uid", caller.uid)
+	c.Set("groups", caller.groups)
+	c.Set("utype", utype)
+
+	handler(c)
+	return w
+}
+
+// decode unmarshals response body
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	var result T
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	return result
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"slices"
+	"time"
 
 	"github.com/CTO2BPublic/passage-server/pkg/errors"
 	"github.com/CTO2BPublic/passage-server/pkg/models"
@@ -48,7 +49,7 @@ func (r *UserController) UserInfo(c *gin.Context) {
 // @Security JWT
 // @Summary User profile
 // @Schemes
-// @Description Returns curent user's profile
+// @Description Returns curent user's profile including current quota usage
 // @Tags User
 // @Accept json
 // @Produce json
@@ -84,6 +85,12 @@ func (r *UserController) GetProfile(c *gin.Context) {
 		return
 	}
 
+	profile.Quotas, err = getQuotaUsage(ctx, uid, nil)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
+		return
+	}
+
 	c.JSON(200, profile)
 }
 
@@ -215,6 +222,10 @@ func (r *UserController) UpdateProfileSettings(c *gin.Context) {
 		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(err))
 		return
 	}
+	if err := data.Validate(); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(err))
+		return
+	}
 
 	err = Db.UpdateUserProfile(ctx, models.UserProfile{
 		Id:       uid,
@@ -228,3 +239,119 @@ func (r *UserController) UpdateProfileSettings(c *gin.Context) {
 
 	c.JSON(errors.StatusUpdated())
 }
+
+// @Security JWT
+// @Summary List approval delegations
+// @Schemes
+// @Description Lists approval delegations registered by current user
+// @Tags User
+// @Accept json
+// @Produce json
+// @Success 200 {object} []models.Delegation
+// @Router /user/profile/delegations [get]
+func (r *UserController) ListDelegations(c *gin.Context) {
+
+	ctx, span := tracing.NewSpanWrapper(c.Request.Context(), "controllers.UserController.ListDelegations")
+	defer span.End()
+
+	uid := c.GetString("uid")
+
+	delegations, err := Db.SelectDelegations(ctx, uid)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
+		return
+	}
+	if delegations == nil {
+		delegations = []models.Delegation{}
+	}
+
+	c.JSON(200, delegations)
+}
+
+// @Security JWT
+// @Summary Create approval delegation
+// @Schemes
+// @Description Delegates current user's approval permissions to another user for a limited time. Approvals are recorded as made on behalf of current user
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param delegation body models.Delegation true "Delegation"
+// @Success 200 {object} models.Delegation
+// @Router /user/profile/delegations [post]
+func (r *UserController) CreateDelegation(c *gin.Context) {
+
+	ctx, span := tracing.NewSpanWrapper(c.Request.Context(), "controllers.UserController.CreateDelegation")
+	defer span.End()
+
+	uid := c.GetString("uid")
+
+	data := models.Delegation{}
+	err := c.ShouldBindBodyWith(&data, binding.JSON)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(err))
+		return
+	}
+
+	if err := data.Admit(uid, time.Now()); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorSchemaValidation(err))
+		return
+	}
+
+	// Delegations reference the delegator's profile
+	if _, err := r.getOrCreateProfile(ctx, uid); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseSelect(err))
+		return
+	}
+
+	if err := Db.InsertDelegation(ctx, data); err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseInsert(err))
+		return
+	}
+
+	c.JSON(200, data)
+}
+
+// @Security JWT
+// @Summary Delete approval delegation
+// @Schemes
+// @Description Revokes approval delegation registered by current user
+// @Tags User
+// @Accept json
+// @Produce json
+// @Success 200 {object} ResponseSuccessDeleted
+// @Router /user/profile/delegations/{ID} [delete]
+// @Param ID path string true "Delegation id" default(xxxx-xxxx-xxxx)
+func (r *UserController) DeleteDelegation(c *gin.Context) {
+
+	ctx, span := tracing.NewSpanWrapper(c.Request.Context(), "controllers.UserController.DeleteDelegation")
+	defer span.End()
+
+	id := c.Param("ID")
+	uid := c.GetString("uid")
+
+	found, err := Db.DeleteDelegation(ctx, models.Delegation{Id: id, Delegator: uid})
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseUpdate(err))
+		return
+	}
+	if !found {
+		c.AbortWithStatusJSON(errors.ErrorDatabaseRecordNotFound())
+		return
+	}
+
+	c.JSON(errors.StatusDeleted())
+}
+
+func (r *UserController) getOrCreateProfile(ctx context.Context, uid string) (models.UserProfile, error) {
+
+	exists, err := Db.UserProfileExists(ctx, models.UserProfile{Id: uid})
+	if err != nil {
+		return models.UserProfile{}, err
+	}
+
+	if !exists {
+		return r.newDefaultProfile(ctx, uid)
+	}
+
+	return Db.SelectUserProfile(ctx, models.UserProfile{Id: uid})
+}
@@ -1,6 +1,7 @@
 package crondriver
 
 import (
+	"sync"
 	"time"
 
 	"github.com/CTO2BPublic/passage-server/pkg/client"
@@ -10,6 +11,57 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+const (
+	// revokeAlertAttempts is the number of failed revocations after which every failure raises an alert
+	revokeAlertAttempts = 5
+	// maxRevokeBackoff caps the delay between revocation retries
+	maxRevokeBackoff = time.Hour
+)
+
+// revokeBackoff delays revocation retries of access requests which providers keep failing to revoke
+type revokeBackoff struct {
+	mu       sync.Mutex
+	attempts map[string]revokeAttempt
+}
+
+type revokeAttempt struct {
+	failures int
+	next     time.Time
+}
+
+var revokeRetries = &revokeBackoff{attempts: map[string]revokeAttempt{}}
+
+// due reports whether revocation of the access request should be attempted
+func (b *revokeBackoff) due(id string, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !now.Before(b.attempts[id].next)
+}
+
+// failed records failed revocation and returns the number of consecutive failures
+func (b *revokeBackoff) failed(id string, now time.Time) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	attempt := b.attempts[id]
+	attempt.failures++
+
+	delay := maxRevokeBackoff
+	if attempt.failures < 6 {
+		delay = time.Minute << attempt.failures
+	}
+	attempt.next = now.Add(delay)
+
+	b.attempts[id] = attempt
+	return attempt.failures
+}
+
+func (b *revokeBackoff) reset(id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.attempts, id)
+}
+
 func (c *Cron) Start() {
 
 	apiClient := client.NewApiClient(client.ApiClientOpts{
@@ -33,9 +85,51 @@ func (c *Cron) Start() {
 		log.Error().Str("/errors/cron", "Failed to schedule cron entry").Msg(err.Error())
 	}
 
+	if Config.Archive.Retention != "" {
+		retention, err := time.ParseDuration(Config.Archive.Retention)
+		if err != nil {
+			log.Error().Str("/errors/cron", "Invalid archive retention").Msg(err.Error())
+		} else {
+			_, err = cron.AddFunc("@every 1h", func() {
+				Requests, _, err := apiClient.GetArchivedAccessRequests()
+				if err != nil {
+					log.Err(err).Msg("Failed to fetch archived access requests")
+					return
+				}
+
+				purgeArchivedAccessRequests(apiClient, Requests, retention)
+			})
+			if err != nil {
+				log.Error().Str("/errors/cron", "Failed to schedule cron entry").Msg(err.Error())
+			}
+		}
+	}
+
 	go cron.Start()
 }
 
+func purgeArchivedAccessRequests(apiClient *client.ApiClient, Requests []models.AccessRequest, retention time.Duration) {
+	threshold := time.Now().Add(-retention)
+	for _, request := range Requests {
+		if request.IsArchivedBefore(threshold) {
+
+			log.Info().
+				Str("Request", request.Id).
+				Str("Role", request.RoleRef.Name).
+				Str("Requester", request.Status.RequestedBy).
+				Str("Archived", request.DeletedAt.Time.Local().String()).
+				Msg("Purging archived access request")
+
+			_, _, err := apiClient.PurgeAccessRequest(client.PurgeAccessRequestOpts{
+				Id: request.Id,
+			})
+			if err != nil {
+				log.Err(err).Msgf("Failed to purge access request %s", request.Id)
+			}
+		}
+	}
+}
+
 func processAccessRequests(apiClient *client.ApiClient, Requests []models.AccessRequest) {
 	now := time.Now()
 	for _, request := range Requests {
@@ -56,6 +150,57 @@ func processAccessRequests(apiClient *client.ApiClient, Requests []models.Access
 			if err != nil {
 				log.Err(err).Msgf("Failed to expire access request %s", request.Id)
 			}
+			continue
+		}
+
+		if request.IsRecertificationOverdue(now) {
+			if !revokeRetries.due(request.Id, now) {
+				continue
+			}
+
+			log.Info().
+				Str("Request", request.Id).
+				Str("Role", request.RoleRef.Name).
+				Str("Requester", request.Status.RequestedBy).
+				Str("Due", request.Status.Recertification.DueAt.Local().String()).
+				Str("GracePeriod", request.Status.Recertification.GracePeriod).
+				Msg("Revoking standing access request. Recertification overdue")
+
+			_, _, err := apiClient.ExpireAccessRequest(client.ExpireAccessRequestOpts{
+				Id: request.Id,
+			})
+			if err != nil {
+				log.Err(err).Msgf("Failed to expire access request %s", request.Id)
+
+				// Access stays active until providers revoke it. Keep retrying and alert
+				if failures := revokeRetries.failed(request.Id, now); failures >= revokeAlertAttempts {
+					log.Error().
+						Str("/errors/cron", "Recertification overdue access could not be revoked").
+						Str("Request", request.Id).
+						Int("Attempts", failures).
+						Msg("Providers keep failing to revoke access. Check provider statuses of the access request")
+				}
+				continue
+			}
+			revokeRetries.reset(request.Id)
+			continue
+		}
+
+		if request.IsRecertificationDue(now) {
+
+			log.Info().
+				Str("Request", request.Id).
+				Str("Role", request.RoleRef.Name).
+				Str("Requester", request.Status.RequestedBy).
+				Str("Due", request.Status.Recertification.DueAt.Local().String()).
+				Msg("Requesting recertification")
+
+			_, _, err := apiClient.RequestRecertification(client.RequestRecertificationOpts{
+				Id: request.Id,
+			})
+			if err != nil {
+				log.Err(err).Msgf("Failed to request recertification of access request %s", request.Id)
+			}
 		}
 	}
 }
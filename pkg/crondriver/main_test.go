@@ -0,0 +1,57 @@
+package crondriver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/client"
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessAccessRequestsRevokeRetries(t *testing.T) {
+	calls := 0
+	failing := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+
+	apiClient := client.NewApiClient(client.ApiClientOpts{Url: server.URL})
+
+	// Standing request with recertification overdue
+	now := time.Now()
+	due := now.Add(-2 * time.Hour)
+	request := models.AccessRequest{Id: "req-1"}
+	request.SetStatusApprove("jane.doe")
+	request.Status.Recertification = &models.AccessRequestRecertification{GracePeriod: "1h", DueAt: &due, RequestedAt: &due}
+
+	// Failed revocation is retried after backoff, request is never expired without providers
+	processAccessRequests(apiClient, []models.AccessRequest{request})
+	processAccessRequests(apiClient, []models.AccessRequest{request})
+	assert.Equal(t, 1, calls)
+	assert.False(t, revokeRetries.due("req-1", now))
+	assert.True(t, revokeRetries.due("req-1", now.Add(3*time.Minute)))
+
+	// Backoff grows with consecutive failures and is capped
+	for range 10 {
+		revokeRetries.failed("req-1", now)
+	}
+	assert.False(t, revokeRetries.due("req-1", now.Add(maxRevokeBackoff-time.Second)))
+	assert.True(t, revokeRetries.due("req-1", now.Add(maxRevokeBackoff)))
+
+	// Successful revocation clears backoff
+	failing = false
+	revokeRetries.reset("req-1")
+	processAccessRequests(apiClient, []models.AccessRequest{request})
+	assert.Equal(t, 2, calls)
+	assert.True(t, revokeRetries.due("req-1", now))
+}
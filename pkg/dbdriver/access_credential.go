@@ -0,0 +1,47 @@
+package dbdriver
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"gorm.io/gorm"
+)
+
+func (d *Database) InsertAccessCredentials(ctx context.Context, data []models.AccessCredential) error {
+	if len(data) == 0 {
+		return nil
+	}
+	result := d.Engine.WithContext(ctx).Create(&data)
+	return result.Error
+}
+
+// PopAccessCredentials returns unexpired credentials of access request and deletes all of them, so they can be retrieved only once
+func (d *Database) PopAccessCredentials(ctx context.Context, data models.AccessRequest) (result []models.AccessCredential, err error) {
+	err = d.Engine.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("access_request_id = ?", data.Id).Order("created_at").Find(&result).Error; err != nil {
+			return err
+		}
+		return tx.Where("access_request_id = ?", data.Id).Delete(&models.AccessCredential{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result = slices.DeleteFunc(result, func(c models.AccessCredential) bool {
+		return c.IsExpired(now)
+	})
+	if len(result) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return result, nil
+}
+
+// DeleteAccessCredentials removes undelivered credentials of access request
+func (d *Database) DeleteAccessCredentials(ctx context.Context, data models.AccessRequest) error {
+	result := d.Engine.WithContext(ctx).Where("access_request_id = ?", data.Id).Delete(&models.AccessCredential{})
+	return result.Error
+}
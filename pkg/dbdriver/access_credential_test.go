@@ -0,0 +1,68 @@
+package dbdriver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestPopAccessCredentials(t *testing.T) {
+	d := testDatabase(t)
+	ctx := context.Background()
+	expiredAt := time.Now().Add(-time.Minute)
+
+	request := models.AccessRequest{Id: "req-1"}
+	request.AddCredential("PostgresRead", models.AccessCredentialPassword, map[string]string{"password": "s3cret"}, nil)
+	request.AddCredential("Vault", models.AccessCredentialToken, map[string]string{"token": "hvs.expired"}, &expiredAt)
+	require.NoError(t, d.InsertAccessCredentials(ctx, request.Credentials))
+
+	// Secrets are not stored in plain text
+	var stored []string
+	require.NoError(t, d.Engine.Model(&models.AccessCredential{}).Pluck("data", &stored).Error)
+	require.Len(t, stored, 2)
+	for _, data := range stored {
+		assert.NotContains(t, data, "s3cret")
+		assert.NotContains(t, data, "hvs.expired")
+	}
+
+	credentials, err := d.PopAccessCredentials(ctx, request)
+	require.NoError(t, err)
+	require.Len(t, credentials, 1)
+	assert.Equal(t, map[string]string{"password": "s3cret"}, credentials[0].Data)
+
+	// Delivered once, expired credentials are dropped as well
+	_, err = d.PopAccessCredentials(ctx, request)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// Only expired credentials left
+	expired := models.AccessRequest{Id: "req-2"}
+	expired.AddCredential("Vault", models.AccessCredentialToken, map[string]string{"token": "hvs.expired"}, &expiredAt)
+	require.NoError(t, d.InsertAccessCredentials(ctx, expired.Credentials))
+	_, err = d.PopAccessCredentials(ctx, expired)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	var count int64
+	require.NoError(t, d.Engine.Model(&models.AccessCredential{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestEncryptedSerializerLegacyValues(t *testing.T) {
+	d := testDatabase(t)
+	ctx := context.Background()
+
+	require.NoError(t, d.Engine.Exec(
+		"INSERT INTO access_credentials (id, access_request_id, provider, kind, data) VALUES (?, ?, ?, ?, ?)",
+		"cred-1", "req-1", "PostgresRead", models.AccessCredentialPassword, `{"password":"legacy"}`,
+	).Error)
+
+	credentials, err := d.PopAccessCredentials(ctx, models.AccessRequest{Id: "req-1"})
+	require.NoError(t, err)
+	require.Len(t, credentials, 1)
+	assert.Equal(t, map[string]string{"password": "legacy"}, credentials[0].Data)
+}
@@ -2,10 +2,12 @@ package dbdriver
 
 import (
 	"context"
+	"time"
 
 	"github.com/CTO2BPublic/passage-server/pkg/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func (d *Database) InsertAccessRequest(ctx context.Context, data models.AccessRequest) error {
@@ -18,11 +20,48 @@ func (d *Database) UpdateAccessRequest(ctx context.Context, data *models.AccessR
 	return result.Error
 }
 
+// DeleteAccessRequest archives access request. Archived requests can be restored or purged
 func (d *Database) DeleteAccessRequest(ctx context.Context, data models.AccessRequest) error {
-	result := d.Engine.WithContext(ctx).Where("id = ?", data.Id).Unscoped().Delete(models.AccessRequest{})
+	result := d.Engine.WithContext(ctx).Where("id = ?", data.Id).Delete(&models.AccessRequest{})
 	return result.Error
 }
 
+// PurgeAccessRequest permanently removes archived access request
+func (d *Database) PurgeAccessRequest(ctx context.Context, data models.AccessRequest) error {
+	return d.Engine.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		archived := tx.Unscoped().Model(&models.AccessRequest{}).Select("id").Where("id = ? AND deleted_at IS NOT NULL", data.Id)
+		if err := tx.Where("access_request_id IN (?)", archived).Delete(&models.AccessRequestApprover{}).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", data.Id).Delete(&models.AccessRequest{})
+		if result.Error == nil && result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return result.Error
+	})
+}
+
+// RestoreAccessRequest brings archived access request back
+func (d *Database) RestoreAccessRequest(ctx context.Context, data models.AccessRequest) error {
+	result := d.Engine.WithContext(ctx).Unscoped().Model(&models.AccessRequest{}).Where("id = ? AND deleted_at IS NOT NULL", data.Id).Update("deleted_at", nil)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+func (d *Database) SelectArchivedAccessRequest(ctx context.Context, data models.AccessRequest) (*models.AccessRequest, error) {
+	var result models.AccessRequest
+	q := d.Engine.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").First(&result, models.AccessRequest{Id: data.Id})
+	return &result, q.Error
+}
+
+func (d *Database) SelectArchivedAccessRequests(ctx context.Context) (result []models.AccessRequest, err error) {
+	q := d.Engine.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at desc").Find(&result)
+
+	return result, q.Error
+}
+
 func (d *Database) SelectAccessRequest(ctx context.Context, data models.AccessRequest) (*models.AccessRequest, error) {
 	var result models.AccessRequest
 	q := d.Engine.WithContext(ctx).First(&result, models.AccessRequest{Id: data.Id})
@@ -35,6 +74,66 @@ func (d *Database) SelectAccessRequests(ctx context.Context) (result []models.Ac
 	return result, q.Error
 }
 
+var accessRequestSortColumns = map[string]string{
+	"createdAt": "created_at",
+	"updatedAt": "updated_at",
+}
+
+// SelectFilteredAccessRequests returns a single page of access requests matching the filter
+func (d *Database) SelectFilteredAccessRequests(ctx context.Context, filter models.AccessRequestFilter, page models.Pagination) (result []models.AccessRequest, info models.Page, err error) {
+
+	q := d.Engine.WithContext(ctx).Model(&models.AccessRequest{})
+
+	q = equals(q, "status_status", filter.Status)
+	q = equals(q, "roleRef_name", filter.Role)
+	q = equals(q, "status_requested_by", filter.Requester)
+	q = equals(q, "status_approved_by", filter.Approver)
+	q = timeRange(q, "created_at", filter.CreatedAfter, filter.CreatedBefore)
+	q = timeRange(q, "status_expires_at", filter.ExpiresAfter, filter.ExpiresBefore)
+
+	if filter.RoleNames != nil {
+		q = q.Where(clause.IN{Column: clause.Column{Name: "roleRef_name"}, Values: toValues(filter.RoleNames)})
+	}
+
+	if filter.Visibility != nil {
+		// Approvers are matched against the rule snapshotted on the request, not the current config
+		approvable := d.Engine.WithContext(ctx).Model(&models.AccessRequestApprover{}).Select("access_request_id").Where(clause.Or(
+			clause.And(
+				clause.Eq{Column: clause.Column{Name: "kind"}, Value: models.ApproverKindUser},
+				clause.IN{Column: clause.Column{Name: "name"}, Values: toValues(filter.Visibility.Users)},
+			),
+			clause.And(
+				clause.Eq{Column: clause.Column{Name: "kind"}, Value: models.ApproverKindGroup},
+				clause.IN{Column: clause.Column{Name: "name"}, Values: toValues(filter.Visibility.Groups)},
+			),
+		))
+		q = q.Where("status_requested_by = ? OR id IN (?)", filter.Visibility.Requester, approvable)
+	}
+
+	if err := q.Session(&gorm.Session{}).Count(&info.Total).Error; err != nil {
+		return nil, info, err
+	}
+
+	q, err = paginate(q, page, accessRequestSortColumns, "createdAt")
+	if err != nil {
+		return nil, info, err
+	}
+
+	if err := q.Find(&result).Error; err != nil {
+		return nil, info, err
+	}
+
+	field, _ := page.SortField("createdAt")
+	result, info.NextCursor = nextCursor(result, page, func(r models.AccessRequest) (time.Time, string) {
+		if field == "updatedAt" {
+			return r.UpdatedAt, r.Id
+		}
+		return r.CreatedAt, r.Id
+	})
+
+	return result, info, nil
+}
+
 func (d *Database) AccessRequestExists(ctx context.Context, data models.AccessRequest) (bool, error) {
 	var count int64
 	err := d.Engine.WithContext(ctx).Model(&models.AccessRequest{}).Where("id = ?", data.Id).Count(&count).Error
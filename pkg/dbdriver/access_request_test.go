@@ -0,0 +1,170 @@
+package dbdriver
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func testDatabase(t *testing.T) *Database {
+	engine, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	require.NoError(t, err)
+
+	d := &Database{Engine: engine}
+	d.AutoMigrate()
+	return d
+}
+
+func testAccessRequest(id string, requester string, rule models.ApprovalRule, createdAt time.Time) models.AccessRequest {
+	request := models.AccessRequest{Id: id, CreatedAt: createdAt}
+	request.SetRequester(requester).SetApprovalRule(rule)
+	return request
+}
+
+func ids(requests []models.AccessRequest) []string {
+	result := []string{}
+	for _, r := range requests {
+		result = append(result, r.Id)
+	}
+	return result
+}
+
+func TestSelectFilteredAccessRequestsVisibility(t *testing.T) {
+	d := testDatabase(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, r := range []models.AccessRequest{
+		testAccessRequest("req-1", "alice", models.ApprovalRule{Users: []string{"bob"}}, now.Add(-3*time.Minute)),
+		testAccessRequest("req-2", "alice", models.ApprovalRule{Name: "sre", Groups: []string{"sre"}}, now.Add(-2*time.Minute)),
+		testAccessRequest("req-3", "carol", models.ApprovalRule{Groups: []string{"sre"}}, now.Add(-time.Minute)),
+	} {
+		require.NoError(t, d.InsertAccessRequest(ctx, r))
+	}
+
+	visible := func(access models.AccessRequestAccess) []string {
+		result, info, err := d.SelectFilteredAccessRequests(ctx, models.AccessRequestFilter{Visibility: &access}, models.Pagination{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(result)), info.Total)
+		return ids(result)
+	}
+
+	assert.Equal(t, []string{"req-2", "req-1"}, visible(models.AccessRequestAccess{Requester: "alice", Users: []string{"alice"}}))
+	assert.Equal(t, []string{"req-1"}, visible(models.AccessRequestAccess{Requester: "bob", Users: []string{"bob"}}))
+	// Groups of unnamed rules are not honoured
+	assert.Equal(t, []string{"req-3", "req-2"}, visible(models.AccessRequestAccess{Requester: "carol", Users: []string{"carol"}, Groups: []string{"sre"}}))
+	// Delegate of bob
+	assert.Equal(t, []string{"req-1"}, visible(models.AccessRequestAccess{Requester: "dave", Users: []string{"dave", "bob"}}))
+	assert.Empty(t, visible(models.AccessRequestAccess{Requester: "dave", Users: []string{"dave"}}))
+
+	// Purging archived request removes its approvers
+	require.NoError(t, d.DeleteAccessRequest(ctx, models.AccessRequest{Id: "req-1"}))
+	require.NoError(t, d.PurgeAccessRequest(ctx, models.AccessRequest{Id: "req-1"}))
+	var count int64
+	require.NoError(t, d.Engine.Model(&models.AccessRequestApprover{}).Where("access_request_id = ?", "req-1").Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestSelectFilteredAccessRequestsPagination(t *testing.T) {
+	d := testDatabase(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	// req-b and req-c share creation time and are ordered by id
+	for _, r := range []models.AccessRequest{
+		testAccessRequest("req-a", "alice", models.ApprovalRule{}, now.Add(-2*time.Minute)),
+		testAccessRequest("req-b", "alice", models.ApprovalRule{}, now.Add(-time.Minute)),
+		testAccessRequest("req-c", "alice", models.ApprovalRule{}, now.Add(-time.Minute)),
+		testAccessRequest("req-d", "alice", models.ApprovalRule{}, now),
+	} {
+		require.NoError(t, d.InsertAccessRequest(ctx, r))
+	}
+
+	pages := func(page models.Pagination) [][]string {
+		result := [][]string{}
+		for {
+			data, info, err := d.SelectFilteredAccessRequests(ctx, models.AccessRequestFilter{}, page)
+			require.NoError(t, err)
+			assert.Equal(t, int64(4), info.Total)
+			result = append(result, ids(data))
+			if info.NextCursor == "" {
+				return result
+			}
+			page.Cursor = info.NextCursor
+		}
+	}
+
+	assert.Equal(t, [][]string{{"req-d", "req-c"}, {"req-b", "req-a"}}, pages(models.Pagination{Limit: 2}))
+	assert.Equal(t, [][]string{{"req-a", "req-b", "req-c"}, {"req-d"}}, pages(models.Pagination{Limit: 3, Sort: "createdAt"}))
+	assert.Equal(t, [][]string{{"req-d", "req-c", "req-b", "req-a"}}, pages(models.Pagination{}))
+
+	_, _, err := d.SelectFilteredAccessRequests(ctx, models.AccessRequestFilter{}, models.Pagination{Sort: "roleRef"})
+	assert.Error(t, err)
+	_, _, err = d.SelectFilteredAccessRequests(ctx, models.AccessRequestFilter{}, models.Pagination{Limit: 1, Cursor: "not-a-cursor"})
+	assert.Error(t, err)
+}
+
+func TestBackfillAccessRequestApprovers(t *testing.T) {
+	d := testDatabase(t)
+	ctx := context.Background()
+
+	// Request stored before approvers were indexed
+	request := testAccessRequest("req-1", "alice", models.ApprovalRule{Users: []string{"bob"}}, time.Now())
+	request.Approvers = nil
+	require.NoError(t, d.InsertAccessRequest(ctx, request))
+
+	access := &models.AccessRequestAccess{Requester: "bob", Users: []string{"bob"}}
+	result, _, err := d.SelectFilteredAccessRequests(ctx, models.AccessRequestFilter{Visibility: access}, models.Pagination{})
+	require.NoError(t, err)
+	assert.Empty(t, result)
+
+	require.NoError(t, d.backfillAccessRequestApprovers())
+	require.NoError(t, d.backfillAccessRequestApprovers())
+
+	result, _, err = d.SelectFilteredAccessRequests(ctx, models.AccessRequestFilter{Visibility: access}, models.Pagination{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"req-1"}, ids(result))
+}
+
+func TestMigrateOnce(t *testing.T) {
+	d := testDatabase(t)
+
+	runs := 0
+	migrate := func() error {
+		runs++
+		return nil
+	}
+	require.NoError(t, d.migrateOnce("test-migration", migrate))
+	require.NoError(t, d.migrateOnce("test-migration", migrate))
+	assert.Equal(t, 1, runs)
+
+	// Failed migrations are retried on next startup
+	failing := func() error { return errors.New("failed") }
+	require.Error(t, d.migrateOnce("failing-migration", failing))
+	require.NoError(t, d.migrateOnce("failing-migration", migrate))
+	assert.Equal(t, 2, runs)
+}
+
+func TestProviderReferencesPersisted(t *testing.T) {
+	d := testDatabase(t)
+	ctx := context.Background()
+
+	request := testAccessRequest("req-1", "alice", models.ApprovalRule{}, time.Now())
+	require.NoError(t, d.InsertAccessRequest(ctx, request))
+
+	request.SetProviderReference("Vault", "tokenAccessor", "acc1")
+	require.NoError(t, d.UpdateAccessRequest(ctx, &request))
+
+	stored, err := d.SelectAccessRequest(ctx, models.AccessRequest{Id: "req-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "acc1", stored.GetProviderReference("Vault", "tokenAccessor"))
+}
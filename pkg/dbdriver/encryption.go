@@ -0,0 +1,105 @@
+package dbdriver
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+func init() {
+	schema.RegisterSerializer("encrypted", encryptedSerializer{})
+}
+
+// encryptedSerializer stores field as JSON sealed with AES-GCM. The key is derived from the shared secret,
+// so sealed values cannot be read after the secret changes or by replicas using a generated secret
+type encryptedSerializer struct{}
+
+func (encryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	fieldValue := reflect.New(field.FieldType)
+
+	var value string
+	switch v := dbValue.(type) {
+	case []byte:
+		value = string(v)
+	case string:
+		value = v
+	}
+
+	if value != "" {
+		data := []byte(value)
+		// Values stored before encryption was introduced are plain JSON
+		if !strings.HasPrefix(value, "{") {
+			var err error
+			if data, err = unseal(value); err != nil {
+				return err
+			}
+		}
+		if err := json.Unmarshal(data, fieldValue.Interface()); err != nil {
+			return err
+		}
+	}
+
+	field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+	return nil
+}
+
+func (encryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	data, err := json.Marshal(fieldValue)
+	if err != nil {
+		return nil, err
+	}
+	return seal(data)
+}
+
+func newCipher() (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(Config.SharedSecret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(data []byte) (string, error) {
+	aead, err := newCipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, data, nil)), nil
+}
+
+func unseal(value string) ([]byte, error) {
+	aead, err := newCipher()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("sealed value is too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	data, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt sealed value. Was sharedSecret changed?")
+	}
+	return data, nil
+}
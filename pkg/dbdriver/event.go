@@ -2,6 +2,7 @@ package dbdriver
 
 import (
 	"context"
+	"time"
 
 	"github.com/CTO2BPublic/passage-server/pkg/models"
 
@@ -29,3 +30,44 @@ func (d *Database) SelectEvents(ctx context.Context) (result []models.Event, err
 
 	return result, q.Error
 }
+
+var eventSortColumns = map[string]string{
+	"date":      "attributes_date",
+	"createdAt": "created_at",
+}
+
+// SelectFilteredEvents returns a single page of events matching the filter
+func (d *Database) SelectFilteredEvents(ctx context.Context, filter models.EventFilter, page models.Pagination) (result []models.Event, info models.Page, err error) {
+
+	q := d.Engine.WithContext(ctx).Model(&models.Event{}).Omit("data")
+
+	q = equals(q, "attributes_type", filter.Type)
+	q = equals(q, "attributes_source", filter.Source)
+	q = equals(q, "attributes_author", filter.Author)
+	q = equals(q, "parent_id", filter.ParentID)
+	q = equals(q, "transaction_id", filter.TransactionID)
+	q = timeRange(q, "attributes_date", filter.After, filter.Before)
+
+	if err := q.Session(&gorm.Session{}).Count(&info.Total).Error; err != nil {
+		return nil, info, err
+	}
+
+	q, err = paginate(q, page, eventSortColumns, "date")
+	if err != nil {
+		return nil, info, err
+	}
+
+	if err := q.Find(&result).Error; err != nil {
+		return nil, info, err
+	}
+
+	field, _ := page.SortField("date")
+	result, info.NextCursor = nextCursor(result, page, func(e models.Event) (time.Time, string) {
+		if field == "createdAt" {
+			return e.CreatedAt, e.ID
+		}
+		return e.Attributes.Date, e.ID
+	})
+
+	return result, info, nil
+}
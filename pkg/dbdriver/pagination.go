@@ -0,0 +1,91 @@
+package dbdriver
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// paginate applies keyset pagination and sorting. Columns maps sortable API fields to database columns
+func paginate(q *gorm.DB, page models.Pagination, columns map[string]string, defaultField string) (*gorm.DB, error) {
+
+	field, desc := page.SortField(defaultField)
+	column, ok := columns[field]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort field: %s", field)
+	}
+
+	cursor, err := page.DecodeCursor()
+	if err != nil {
+		return nil, err
+	}
+
+	if cursor != nil {
+		col := clause.Column{Name: column}
+		id := clause.Column{Name: "id"}
+
+		var after, afterId clause.Expression
+		if desc {
+			after = clause.Lt{Column: col, Value: cursor.Value}
+			afterId = clause.Lt{Column: id, Value: cursor.Id}
+		} else {
+			after = clause.Gt{Column: col, Value: cursor.Value}
+			afterId = clause.Gt{Column: id, Value: cursor.Id}
+		}
+
+		q = q.Where(clause.Or(after, clause.And(clause.Eq{Column: col, Value: cursor.Value}, afterId)))
+	}
+
+	q = q.Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+		{Column: clause.Column{Name: column}, Desc: desc},
+		{Column: clause.Column{Name: "id"}, Desc: desc},
+	}})
+
+	if page.Limit > 0 {
+		// Fetch one extra record to find out if there is a next page
+		q = q.Limit(page.Limit + 1)
+	}
+
+	return q, nil
+}
+
+// nextCursor trims extra record and returns cursor to the next page
+func nextCursor[T any](result []T, page models.Pagination, key func(T) (time.Time, string)) ([]T, string) {
+	if page.Limit == 0 || len(result) <= page.Limit {
+		return result, ""
+	}
+
+	result = result[:page.Limit]
+	value, id := key(result[len(result)-1])
+
+	return result, models.Cursor{Value: value, Id: id}.Encode()
+}
+
+func timeRange(q *gorm.DB, column string, after time.Time, before time.Time) *gorm.DB {
+	if !after.IsZero() {
+		q = q.Where(clause.Gte{Column: clause.Column{Name: column}, Value: after})
+	}
+	if !before.IsZero() {
+		q = q.Where(clause.Lte{Column: clause.Column{Name: column}, Value: before})
+	}
+	return q
+}
+
+func equals(q *gorm.DB, column string, value string) *gorm.DB {
+	if value != "" {
+		q = q.Where(clause.Eq{Column: clause.Column{Name: column}, Value: value})
+	}
+	return q
+}
+
+func toValues(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package dbdriver
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"gorm.io/gorm"
+)
+
+func (d *Database) InsertReviewCampaign(ctx context.Context, data models.ReviewCampaign) error {
+	result := d.Engine.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).Create(&data)
+	return result.Error
+}
+
+func (d *Database) UpdateReviewCampaign(ctx context.Context, data *models.ReviewCampaign) error {
+	result := d.Engine.WithContext(ctx).Omit("Items").Updates(data)
+	return result.Error
+}
+
+func (d *Database) UpdateReviewItem(ctx context.Context, data *models.ReviewItem) error {
+	result := d.Engine.WithContext(ctx).Select("*").Omit("created_at").Updates(data)
+	return result.Error
+}
+
+// SelectReviewCampaign returns campaign with all its items
+func (d *Database) SelectReviewCampaign(ctx context.Context, data models.ReviewCampaign) (*models.ReviewCampaign, error) {
+	var result models.ReviewCampaign
+	q := d.Engine.WithContext(ctx).Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("role, provider, username")
+	}).First(&result, models.ReviewCampaign{Id: data.Id})
+	return &result, q.Error
+}
+
+// SelectReviewCampaigns returns campaigns without items
+func (d *Database) SelectReviewCampaigns(ctx context.Context) (result []models.ReviewCampaign, err error) {
+	q := d.Engine.WithContext(ctx).Order("created_at desc").Find(&result)
+
+	return result, q.Error
+}
+
+// SelectReviewCampaignsWithItems returns campaigns with all their items
+func (d *Database) SelectReviewCampaignsWithItems(ctx context.Context) (result []models.ReviewCampaign, err error) {
+	q := d.Engine.WithContext(ctx).Preload("Items").Order("created_at desc").Find(&result)
+
+	return result, q.Error
+}
+
+func (d *Database) SelectReviewItem(ctx context.Context, data models.ReviewItem) (*models.ReviewItem, error) {
+	var result models.ReviewItem
+	q := d.Engine.WithContext(ctx).First(&result, models.ReviewItem{Id: data.Id, CampaignId: data.CampaignId})
+	return &result, q.Error
+}
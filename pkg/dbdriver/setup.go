@@ -2,6 +2,7 @@ package dbdriver
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/CTO2BPublic/passage-server/pkg/config"
 	"github.com/CTO2BPublic/passage-server/pkg/models"
@@ -12,6 +13,7 @@ import (
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/plugin/opentelemetry/tracing"
 )
 
@@ -78,13 +80,63 @@ func (d *Database) AutoMigrate() {
 		models.UserProfile{},
 		models.Event{},
 		models.ActivityLog{},
+		models.ReviewCampaign{},
+		models.ReviewItem{},
+		models.AccessCredential{},
+		models.AccessRequestApprover{},
+		models.Delegation{},
+		migration{},
 	)
 	if err != nil {
 		log.Fatal().Msg(err.Error())
 	}
+
+	if err := d.migrateOnce("backfill-access-request-approvers", d.backfillAccessRequestApprovers); err != nil {
+		log.Fatal().Msg(err.Error())
+	}
 	log.Info().Msg("Completed db migrations")
 }
 
+// migration records data migrations which must run only once
+type migration struct {
+	Id        string `gorm:"primaryKey"`
+	CreatedAt time.Time
+}
+
+// migrateOnce runs data migration unless it was already applied
+func (d *Database) migrateOnce(id string, migrate func() error) error {
+	var count int64
+	if err := d.Engine.Model(&migration{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	if err := migrate(); err != nil {
+		return err
+	}
+	return d.Engine.Create(&migration{Id: id}).Error
+}
+
+// backfillAccessRequestApprovers indexes approvers of requests created before the approvers table existed
+func (d *Database) backfillAccessRequestApprovers() error {
+	var requests []models.AccessRequest
+	indexed := d.Engine.Model(&models.AccessRequestApprover{}).Select("access_request_id")
+	if err := d.Engine.Unscoped().Where("id NOT IN (?)", indexed).Find(&requests).Error; err != nil {
+		return err
+	}
+
+	approvers := []models.AccessRequestApprover{}
+	for _, request := range requests {
+		approvers = append(approvers, request.GetApprovers()...)
+	}
+	if len(approvers) == 0 {
+		return nil
+	}
+	return d.Engine.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(approvers, 100).Error
+}
+
 func GetDriver() *Database {
 	return Driver
 }
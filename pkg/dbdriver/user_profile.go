@@ -2,10 +2,12 @@ package dbdriver
 
 import (
 	"context"
+	"time"
 
 	"github.com/CTO2BPublic/passage-server/pkg/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func (d *Database) InsertUserProfile(ctx context.Context, data models.UserProfile) error {
@@ -20,7 +22,7 @@ func (d *Database) UpdateUserProfile(ctx context.Context, data models.UserProfil
 
 func (d *Database) SelectUserProfile(ctx context.Context, data models.UserProfile) (models.UserProfile, error) {
 	var result models.UserProfile
-	q := d.Engine.WithContext(ctx).First(&result, models.UserProfile{Id: data.Id})
+	q := d.Engine.WithContext(ctx).Preload("Delegations").First(&result, models.UserProfile{Id: data.Id})
 	return result, q.Error
 }
 
@@ -38,3 +40,30 @@ func (d *Database) UserProfileExists(ctx context.Context, data models.UserProfil
 	}
 	return count > 0, nil
 }
+
+func (d *Database) InsertDelegation(ctx context.Context, data models.Delegation) error {
+	result := d.Engine.WithContext(ctx).Create(&data)
+	return result.Error
+}
+
+// DeleteDelegation removes delegation of the delegator by id. Reports whether delegation was found
+func (d *Database) DeleteDelegation(ctx context.Context, data models.Delegation) (bool, error) {
+	result := d.Engine.WithContext(ctx).Where("id = ? AND delegator = ?", data.Id, data.Delegator).Delete(&models.Delegation{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// SelectDelegations returns delegations registered by the delegator
+func (d *Database) SelectDelegations(ctx context.Context, delegator string) (result []models.Delegation, err error) {
+	q := d.Engine.WithContext(ctx).Where("delegator = ?", delegator).Order(clause.OrderByColumn{Column: clause.Column{Name: "starts_at"}}).Find(&result)
+	return result, q.Error
+}
+
+// SelectActiveDelegations returns delegations to the delegate in effect at the given time
+func (d *Database) SelectActiveDelegations(ctx context.Context, delegate string, now time.Time) (result []models.Delegation, err error) {
+	q := d.Engine.WithContext(ctx).Where(clause.And(
+		clause.Eq{Column: clause.Column{Name: "delegate"}, Value: delegate},
+		clause.Lte{Column: clause.Column{Name: "starts_at"}, Value: now},
+		clause.Gt{Column: clause.Column{Name: "until"}, Value: now},
+	)).Find(&result)
+	return result, q.Error
+}
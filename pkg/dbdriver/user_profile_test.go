@@ -0,0 +1,44 @@
+package dbdriver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectActiveDelegations(t *testing.T) {
+	d := testDatabase(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, delegation := range []models.Delegation{
+		{Id: "active", Delegator: "jane.doe", Delegate: "john.doe", StartsAt: now.Add(-time.Hour), Until: now.Add(time.Hour)},
+		{Id: "ended", Delegator: "bob", Delegate: "john.doe", StartsAt: now.Add(-2 * time.Hour), Until: now.Add(-time.Hour)},
+		{Id: "future", Delegator: "jane.doe", Delegate: "john.doe", StartsAt: now.Add(time.Hour), Until: now.Add(2 * time.Hour)},
+	} {
+		require.NoError(t, d.InsertDelegation(ctx, delegation))
+	}
+
+	delegations, err := d.SelectActiveDelegations(ctx, "john.doe", now)
+	require.NoError(t, err)
+	require.Len(t, delegations, 1)
+	assert.Equal(t, "active", delegations[0].Id)
+
+	delegations, err = d.SelectDelegations(ctx, "jane.doe")
+	require.NoError(t, err)
+	require.Len(t, delegations, 2)
+	assert.Equal(t, []string{"active", "future"}, []string{delegations[0].Id, delegations[1].Id})
+
+	// Only delegator can remove delegation
+	found, err := d.DeleteDelegation(ctx, models.Delegation{Id: "active", Delegator: "bob"})
+	require.NoError(t, err)
+	assert.False(t, found)
+	found, err = d.DeleteDelegation(ctx, models.Delegation{Id: "active", Delegator: "jane.doe"})
+	require.NoError(t, err)
+	assert.True(t, found)
+}
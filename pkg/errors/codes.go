@@ -298,6 +298,40 @@ func AccessProviderCallPartiallyFailed(err error) (code int, body gin.H) {
 	return http.StatusMultiStatus, body
 }
 
+//	{
+//		"type":   "/errors/providers",
+//		"title":  "Active access could not be revoked. Deletion refused",
+//		"status": http.StatusConflict,
+//		"error":  err.Error(),
+//	}
+func ErrorActiveAccessRevocation(err error) (code int, body gin.H) {
+	body = gin.H{
+		"type":   "/errors/providers",
+		"title":  "Active access could not be revoked. Deletion refused",
+		"status": http.StatusConflict,
+		"error":  err.Error(),
+	}
+	log.Error().Msg(fmt.Sprintf("%+v", body))
+	return http.StatusConflict, body
+}
+
+//	{
+//		"type":   "/errors/quota",
+//		"title":  "Access quota exceeded",
+//		"status": http.StatusTooManyRequests,
+//		"error":  err.Error(),
+//	}
+func ErrorQuotaExceeded(err error) (code int, body gin.H) {
+	body = gin.H{
+		"type":   "/errors/quota",
+		"title":  "Access quota exceeded",
+		"status": http.StatusTooManyRequests,
+		"error":  err.Error(),
+	}
+	log.Error().Msg(fmt.Sprintf("%+v", body))
+	return http.StatusTooManyRequests, body
+}
+
 //	{
 //		"type":   "/status/denied",
 //		"title":  "You are not authorized to perform this action",
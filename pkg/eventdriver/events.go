@@ -3,6 +3,7 @@ package eventdriver
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/CTO2BPublic/passage-server/pkg/models"
@@ -43,6 +44,11 @@ func (e *Events) AccessRequestApproved(ctx context.Context, data models.AccessRe
 	txid, _ := shared.GetTransactionID(ctx)
 	uid, _ := shared.GetUserID(ctx)
 
+	approvedBy := uid
+	if data.Status.ApprovedOnBehalf != "" {
+		approvedBy = fmt.Sprintf("%s on behalf of %s", uid, data.Status.ApprovedOnBehalf)
+	}
+
 	msg := models.Event{
 		ID:            uuid.New().String(),
 		ParentID:      data.Id,
@@ -55,7 +61,7 @@ func (e *Events) AccessRequestApproved(ctx context.Context, data models.AccessRe
 			Date:   time.Now(),
 			Author: uid,
 		},
-		Message: fmt.Sprintf("[%s] [%s] Approved AccessRequest [%s] Role [%s] added to user [%s]", Config.Events.Data.Tenant, uid, data.Id, data.RoleRef.Name, data.Status.RequestedBy),
+		Message: fmt.Sprintf("[%s] [%s] Approved AccessRequest [%s] Role [%s] added to user [%s]", Config.Events.Data.Tenant, approvedBy, data.Id, data.RoleRef.Name, data.Status.RequestedBy),
 		Data: map[string]interface{}{
 			"resource": data,
 		},
@@ -174,6 +180,208 @@ func (e *Events) AccessRequestDeleted(ctx context.Context, data models.AccessReq
 	return e.handleEvent(ctx, msg)
 }
 
+func (e *Events) AccessRequestRestored(ctx context.Context, data models.AccessRequest) error {
+
+	ctx = shared.WithTransactionID(ctx)
+	txid, _ := shared.GetTransactionID(ctx)
+	uid, _ := shared.GetUserID(ctx)
+
+	msg := models.Event{
+		ID:            uuid.New().String(),
+		ParentID:      data.Id,
+		ParentType:    models.EventParentSystem,
+		TransactionID: txid,
+		Tenant:        Config.Events.Data.Tenant,
+		Attributes: models.EventAttributes{
+			Source: "passage-server",
+			Type:   fmt.Sprintf("%s.passage.accessRequest.restored", Config.Events.Data.TypePrefix),
+			Date:   time.Now(),
+			Author: uid,
+		},
+		Message: fmt.Sprintf("[%s] [%s] Restored AccessRequest [%s] Role [%s] User [%s]", Config.Events.Data.Tenant, uid, data.Id, data.RoleRef.Name, data.Status.RequestedBy),
+		Data: map[string]interface{}{
+			"resource": data,
+		},
+	}
+
+	return e.handleEvent(ctx, msg)
+}
+
+func (e *Events) AccessRequestPurged(ctx context.Context, data models.AccessRequest) error {
+
+	ctx = shared.WithTransactionID(ctx)
+	txid, _ := shared.GetTransactionID(ctx)
+	uid, _ := shared.GetUserID(ctx)
+
+	msg := models.Event{
+		ID:            uuid.New().String(),
+		ParentID:      data.Id,
+		ParentType:    models.EventParentSystem,
+		TransactionID: txid,
+		Tenant:        Config.Events.Data.Tenant,
+		Attributes: models.EventAttributes{
+			Source: "passage-server",
+			Type:   fmt.Sprintf("%s.passage.accessRequest.purged", Config.Events.Data.TypePrefix),
+			Date:   time.Now(),
+			Author: uid,
+		},
+		Message: fmt.Sprintf("[%s] [%s] Purged AccessRequest [%s] Role [%s] User [%s]", Config.Events.Data.Tenant, uid, data.Id, data.RoleRef.Name, data.Status.RequestedBy),
+		Data: map[string]interface{}{
+			"resource": data,
+		},
+	}
+
+	return e.handleEvent(ctx, msg)
+}
+
+func (e *Events) AccessRequestRecertificationRequested(ctx context.Context, data models.AccessRequest) error {
+
+	ctx = shared.WithTransactionID(ctx)
+	txid, _ := shared.GetTransactionID(ctx)
+	uid, _ := shared.GetUserID(ctx)
+
+	msg := models.Event{
+		ID:            uuid.New().String(),
+		ParentID:      data.Id,
+		ParentType:    models.EventParentSystem,
+		TransactionID: txid,
+		Tenant:        Config.Events.Data.Tenant,
+		Attributes: models.EventAttributes{
+			Source: "passage-server",
+			Type:   fmt.Sprintf("%s.passage.accessRequest.recertificationRequested", Config.Events.Data.TypePrefix),
+			Date:   time.Now(),
+			Author: uid,
+		},
+		Message: fmt.Sprintf("[%s] AccessRequest [%s] requires recertification. Role [%s] User [%s] Approvers [%s]", Config.Events.Data.Tenant, data.Id, data.RoleRef.Name, data.Status.RequestedBy, strings.Join(append(data.Status.ApprovalRule.Users, data.Status.ApprovalRule.Groups...), ", ")),
+		Data: map[string]interface{}{
+			"resource": data,
+		},
+	}
+
+	return e.handleEvent(ctx, msg)
+}
+
+func (e *Events) AccessRequestRecertified(ctx context.Context, data models.AccessRequest) error {
+
+	ctx = shared.WithTransactionID(ctx)
+	txid, _ := shared.GetTransactionID(ctx)
+	uid, _ := shared.GetUserID(ctx)
+
+	msg := models.Event{
+		ID:            uuid.New().String(),
+		ParentID:      data.Id,
+		ParentType:    models.EventParentSystem,
+		TransactionID: txid,
+		Tenant:        Config.Events.Data.Tenant,
+		Attributes: models.EventAttributes{
+			Source: "passage-server",
+			Type:   fmt.Sprintf("%s.passage.accessRequest.recertified", Config.Events.Data.TypePrefix),
+			Date:   time.Now(),
+			Author: uid,
+		},
+		Message: fmt.Sprintf("[%s] [%s] Recertified AccessRequest [%s] Role [%s] User [%s]", Config.Events.Data.Tenant, uid, data.Id, data.RoleRef.Name, data.Status.RequestedBy),
+		Data: map[string]interface{}{
+			"resource": data,
+		},
+	}
+
+	return e.handleEvent(ctx, msg)
+}
+
+// AccessRequestCredentialsRetrieved records credential delivery. Secrets are not included
+func (e *Events) AccessRequestCredentialsRetrieved(ctx context.Context, data models.AccessRequest, credentials []models.AccessCredential) error {
+
+	ctx = shared.WithTransactionID(ctx)
+	txid, _ := shared.GetTransactionID(ctx)
+	uid, _ := shared.GetUserID(ctx)
+
+	providers := []string{}
+	for _, credential := range credentials {
+		providers = append(providers, credential.Provider)
+	}
+
+	msg := models.Event{
+		ID:            uuid.New().String(),
+		ParentID:      data.Id,
+		ParentType:    models.EventParentSystem,
+		TransactionID: txid,
+		Tenant:        Config.Events.Data.Tenant,
+		Attributes: models.EventAttributes{
+			Source: "passage-server",
+			Type:   fmt.Sprintf("%s.passage.accessRequest.credentialsRetrieved", Config.Events.Data.TypePrefix),
+			Date:   time.Now(),
+			Author: uid,
+		},
+		Message: fmt.Sprintf("[%s] [%s] Retrieved credentials of AccessRequest [%s] Role [%s] Providers [%s]", Config.Events.Data.Tenant, uid, data.Id, data.RoleRef.Name, strings.Join(providers, ", ")),
+		Data: map[string]interface{}{
+			"resource":  data,
+			"providers": providers,
+		},
+	}
+
+	return e.handleEvent(ctx, msg)
+}
+
+func (e *Events) ReviewCampaignCreated(ctx context.Context, data models.ReviewCampaign) error {
+
+	ctx = shared.WithTransactionID(ctx)
+	txid, _ := shared.GetTransactionID(ctx)
+	uid, _ := shared.GetUserID(ctx)
+
+	// Items are large and available via API
+	data.Items = nil
+
+	msg := models.Event{
+		ID:            uuid.New().String(),
+		ParentID:      data.Id,
+		ParentType:    models.EventParentSystem,
+		TransactionID: txid,
+		Tenant:        Config.Events.Data.Tenant,
+		Attributes: models.EventAttributes{
+			Source: "passage-server",
+			Type:   fmt.Sprintf("%s.passage.reviewCampaign.created", Config.Events.Data.TypePrefix),
+			Date:   time.Now(),
+			Author: uid,
+		},
+		Message: fmt.Sprintf("[%s] [%s] Created ReviewCampaign [%s] Name [%s]", Config.Events.Data.Tenant, uid, data.Id, data.Name),
+		Data: map[string]interface{}{
+			"resource": data,
+		},
+	}
+
+	return e.handleEvent(ctx, msg)
+}
+
+func (e *Events) ReviewCampaignCompleted(ctx context.Context, data models.ReviewCampaign) error {
+
+	ctx = shared.WithTransactionID(ctx)
+	txid, _ := shared.GetTransactionID(ctx)
+	uid, _ := shared.GetUserID(ctx)
+
+	// Items are large and available via API
+	data.Items = nil
+
+	msg := models.Event{
+		ID:            uuid.New().String(),
+		ParentID:      data.Id,
+		ParentType:    models.EventParentSystem,
+		TransactionID: txid,
+		Tenant:        Config.Events.Data.Tenant,
+		Attributes: models.EventAttributes{
+			Source: "passage-server",
+			Type:   fmt.Sprintf("%s.passage.reviewCampaign.completed", Config.Events.Data.TypePrefix),
+			Date:   time.Now(),
+			Author: uid,
+		},
+		Message: fmt.Sprintf("[%s] ReviewCampaign [%s] Name [%s] completed", Config.Events.Data.Tenant, data.Id, data.Name),
+		Data: map[string]interface{}{
+			"resource": data,
+		},
+	}
+
+	return e.handleEvent(ctx, msg)
+}
+
 func (e *Events) UserLoggedIn(ctx context.Context, claims models.ClaimsMap) error {
 
 	ctx = shared.WithTransactionID(ctx)
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Access credential kinds
+const (
+	AccessCredentialPassword = "Password"
+	AccessCredentialToken    = "Token"
+	AccessCredentialAWS      = "AWS"
+	AccessCredentialSSH      = "SSHCertificate"
+)
+
+// Short-lived secret issued by a provider on grant. Stored encrypted with a key derived from sharedSecret until the requester retrieves it once
+type AccessCredential struct {
+	Id              string            `gorm:"primaryKey" json:"id"`
+	CreatedAt       time.Time         `json:"createdAt"`
+	AccessRequestId string            `gorm:"index" json:"accessRequestId"`
+	Provider        string            `json:"provider" example:"PostgresRead"`
+	Kind            string            `json:"kind" example:"Password"`
+	Data            map[string]string `gorm:"serializer:encrypted" json:"data"`
+	ExpiresAt       *time.Time        `json:"expiresAt"`
+}
+
+// AddCredential attaches credential issued by provider. Credentials are not part of the request record
+func (s *AccessRequest) AddCredential(provider string, kind string, data map[string]string, expiresAt *time.Time) *AccessRequest {
+	s.Credentials = append(s.Credentials, AccessCredential{
+		Id:              uuid.New().String(),
+		AccessRequestId: s.Id,
+		Provider:        provider,
+		Kind:            kind,
+		Data:            data,
+		ExpiresAt:       expiresAt,
+	})
+	return s
+}
+
+// IsExpired checks whether credential is no longer valid
+func (c *AccessCredential) IsExpired(now time.Time) bool {
+	return c.ExpiresAt != nil && now.After(*c.ExpiresAt)
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
 )
 
 // Access request status constants
@@ -26,10 +28,27 @@ type AccessRequest struct {
 	Id        string               `gorm:"primaryKey" json:"id"`
 	CreatedAt time.Time            `gorm:"index" swaggerignore:"true" json:"createdAt"`
 	UpdatedAt time.Time            `swaggerignore:"true" json:"updatedAt"`
-	DeletedAt *time.Time           `gorm:"index" swaggerignore:"true" json:"deletedAt,omitempty"`
+	DeletedAt gorm.DeletedAt       `gorm:"index" swaggerignore:"true" json:"deletedAt,omitzero"`
 	RoleRef   AccessRoleRef        `gorm:"embedded;embeddedPrefix:roleRef_" json:"roleRef"`
 	Details   AccessRequestDetails `gorm:"embedded;embeddedPrefix:details_" json:"details"`
 	Status    AccessRequestStatus  `swaggerignore:"true" gorm:"embedded;embeddedPrefix:status_" json:"status"`
+	// Credentials issued by providers during the current call. Persisted separately for one-time delivery
+	Credentials []AccessCredential `gorm:"-" json:"-" swaggerignore:"true"`
+	// Approvers from approval rule snapshot, indexed for visibility queries
+	Approvers []AccessRequestApprover `gorm:"foreignKey:AccessRequestId" json:"-" swaggerignore:"true"`
+}
+
+// Access request approver kinds
+const (
+	ApproverKindUser  = "user"
+	ApproverKindGroup = "group"
+)
+
+// AccessRequestApprover is a user or group allowed to approve the access request
+type AccessRequestApprover struct {
+	AccessRequestId string `gorm:"primaryKey"`
+	Kind            string `gorm:"primaryKey;index:idx_access_request_approver"`
+	Name            string `gorm:"primaryKey;index:idx_access_request_approver"`
 }
 
 type AccessRoleRef struct {
@@ -48,9 +67,29 @@ type AccessRequestStatus struct {
 	RequestedBy       string                    `json:"requestedBy"`
 	ApprovalRule      ApprovalRule              `json:"approvalRule" gorm:"serializer:json"`
 	ProviderUsernames map[string]string         `json:"providerUsernames" gorm:"serializer:json"`
+	SSHPublicKey      string                    `json:"sshPublicKey,omitempty"` // Requester's key from UserProfile at creation time
 	ProviderStatuses  map[string]ProviderStatus `json:"providerStatuses" gorm:"serializer:json"`
+	RoleStatuses      map[string]ProviderStatus `json:"roleStatuses,omitempty" gorm:"serializer:json"` // Constituent role statuses of composite roles
 	ExpiresAt         *time.Time
-	Trace             string `json:"trace"`
+	ApprovedAt        *time.Time                    `json:"approvedAt,omitempty"`
+	ApprovedOnBehalf  string                        `json:"approvedOnBehalfOf,omitempty"`
+	ExpiredAt         *time.Time                    `json:"expiredAt,omitempty"`
+	DeletedBy         string                        `json:"deletedBy,omitempty"`
+	Recertification   *AccessRequestRecertification `json:"recertification,omitempty" gorm:"serializer:json"`
+	Trace             string                        `json:"trace"`
+
+	// Identifiers of resources created by providers on grant, such as issued tokens, kept for revocation
+	ProviderReferences map[string]map[string]string `json:"-" gorm:"serializer:json"`
+}
+
+// AccessRequestRecertification tracks periodic recertification of standing grants
+type AccessRequestRecertification struct {
+	Interval      string     `json:"interval" example:"2160h"`
+	GracePeriod   string     `json:"gracePeriod" example:"168h"`
+	DueAt         *time.Time `json:"dueAt"`
+	RequestedAt   *time.Time `json:"requestedAt"`
+	RecertifiedBy string     `json:"recertifiedBy"`
+	RecertifiedAt *time.Time `json:"recertifiedAt"`
 }
 
 type ProviderStatus struct {
@@ -71,8 +110,16 @@ func (a *AccessRequest) SetRequester(requester string) *AccessRequest {
 
 // Method to approve the access request
 func (a *AccessRequest) SetStatusApprove(approvedBy string) *AccessRequest {
+	now := time.Now()
 	a.Status.Status = AccessRequestApproved
 	a.Status.ApprovedBy = approvedBy
+	a.Status.ApprovedAt = &now
+	return a
+}
+
+// Method to record approver on whose behalf the request was approved via delegation
+func (a *AccessRequest) SetApprovedOnBehalfOf(delegator string) *AccessRequest {
+	a.Status.ApprovedOnBehalf = delegator
 	return a
 }
 
@@ -85,10 +132,61 @@ func (a *AccessRequest) SetStatusDenied(approvedBy string) *AccessRequest {
 
 // Method to expire the access request
 func (a *AccessRequest) SetStatusExpired() *AccessRequest {
+	now := time.Now()
 	a.Status.Status = AccessRequestExpired
+	a.Status.ExpiredAt = &now
+	return a
+}
+
+// Method to record who archived the access request
+func (a *AccessRequest) SetDeletedBy(deletedBy string) *AccessRequest {
+	a.Status.DeletedBy = deletedBy
 	return a
 }
 
+// IsActive reports whether access was granted and not yet expired
+func (a *AccessRequest) IsActive() bool {
+	return a.Status.Status == AccessRequestApproved
+}
+
+// CommittedDuration returns access time within the window starting at from, counted until expiration. Pending requests count the time they would grant if approved now
+func (a *AccessRequest) CommittedDuration(from time.Time, now time.Time) time.Duration {
+
+	var start time.Time
+	switch {
+	case a.Status.Status == AccessRequestPending:
+		start = now
+	case a.Status.ApprovedAt != nil:
+		start = *a.Status.ApprovedAt
+	default:
+		return 0
+	}
+
+	end := now
+	if a.Status.ExpiresAt != nil {
+		end = *a.Status.ExpiresAt
+	} else if a.Status.Status == AccessRequestPending {
+		ttl, _ := time.ParseDuration(a.Details.TTL)
+		end = now.Add(ttl)
+	}
+	if !a.IsActive() && a.Status.ExpiredAt != nil && a.Status.ExpiredAt.Before(end) {
+		end = *a.Status.ExpiredAt
+	}
+	if start.Before(from) {
+		start = from
+	}
+
+	if end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// IsArchivedBefore reports whether the access request was archived before the given time
+func (a *AccessRequest) IsArchivedBefore(t time.Time) bool {
+	return a.DeletedAt.Valid && a.DeletedAt.Time.Before(t)
+}
+
 // Method to set the access request to pending
 func (a *AccessRequest) SetStatusPending() *AccessRequest {
 	a.Status.Status = AccessRequestPending
@@ -102,6 +200,20 @@ func (s *AccessRequest) GetProviderUsername(provider string) string {
 	return ""
 }
 
+// ValidateProviderUsernames checks that usernames are set for provider kinds of the resolved role which require one
+func (s *AccessRequest) ValidateProviderUsernames(role AccessRole, requiresUsername func(kind string) bool) error {
+	missing := []string{}
+	for _, kind := range role.ProviderKinds() {
+		if requiresUsername(kind) && s.GetProviderUsername(kind) == "" {
+			missing = append(missing, kind)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing username: %s", missing)
+	}
+	return nil
+}
+
 func (s *AccessRequest) SetProviderUsernames(usernames map[string]string) *AccessRequest {
 
 	if s.Status.ProviderUsernames == nil {
@@ -122,12 +234,38 @@ func (s *AccessRequest) SetProviderUsername(provider string, value string) *Acce
 	return s
 }
 
+// SetSSHPublicKey copies requester's SSH public key for certificate providers
+func (s *AccessRequest) SetSSHPublicKey(key string) *AccessRequest {
+	s.Status.SSHPublicKey = strings.TrimSpace(key)
+	return s
+}
+
 func (s *AccessRequest) SetApprovalRule(rule ApprovalRule) *AccessRequest {
 
 	s.Status.ApprovalRule = rule
+	s.Approvers = s.GetApprovers()
 	return s
 }
 
+// GetApprovers returns users and groups allowed to approve by approval rule snapshot, matching HasPermissions
+func (s *AccessRequest) GetApprovers() []AccessRequestApprover {
+
+	rule := s.Status.ApprovalRule
+	approvers := []AccessRequestApprover{}
+
+	for _, user := range rule.Users {
+		approvers = append(approvers, AccessRequestApprover{AccessRequestId: s.Id, Kind: ApproverKindUser, Name: user})
+	}
+
+	// Groups are honoured only for named rules
+	if rule.Name != "" {
+		for _, group := range rule.Groups {
+			approvers = append(approvers, AccessRequestApprover{AccessRequestId: s.Id, Kind: ApproverKindGroup, Name: group})
+		}
+	}
+	return approvers
+}
+
 func (s *AccessRequest) GetApprovalRule() ApprovalRule {
 
 	return s.Status.ApprovalRule
@@ -143,6 +281,25 @@ func (s *AccessRequest) GetRole(roles []AccessRole) (AccessRole, error) {
 	return AccessRole{}, fmt.Errorf("role not found: %s", s.RoleRef.Name)
 }
 
+// SetProviderReference records identifier of resource created by provider, needed to revoke it later
+func (s *AccessRequest) SetProviderReference(provider string, key string, value string) *AccessRequest {
+
+	if s.Status.ProviderReferences == nil {
+		s.Status.ProviderReferences = make(map[string]map[string]string)
+	}
+	if s.Status.ProviderReferences[provider] == nil {
+		s.Status.ProviderReferences[provider] = make(map[string]string)
+	}
+
+	s.Status.ProviderReferences[provider][key] = value
+	return s
+}
+
+// GetProviderReference returns identifier recorded by provider or empty string
+func (s *AccessRequest) GetProviderReference(provider string, key string) string {
+	return s.Status.ProviderReferences[provider][key]
+}
+
 func (s *AccessRequest) SetProviderStatusGranted(provider string, details string, err string) *AccessRequest {
 
 	if s.Status.ProviderStatuses == nil {
@@ -185,6 +342,41 @@ func (s *AccessRequest) SetProviderStatusError(provider string, details string,
 	return s
 }
 
+// SetRoleStatuses aggregates provider statuses per constituent role of a composite role
+func (s *AccessRequest) SetRoleStatuses(role AccessRole) *AccessRequest {
+
+	if !role.IsComposite() {
+		return s
+	}
+
+	statuses := map[string]ProviderStatus{}
+	for _, config := range role.Providers {
+		if config.SourceRole == "" {
+			continue
+		}
+
+		provider, exists := s.Status.ProviderStatuses[config.Name]
+		if !exists {
+			continue
+		}
+
+		status, exists := statuses[config.SourceRole]
+		switch {
+		case !exists:
+			status = ProviderStatus{Action: provider.Action, Error: provider.Error}
+		case provider.Action == ProviderStatusError:
+			status.Action = ProviderStatusError
+			status.Error = strings.TrimPrefix(fmt.Sprintf("%s; %s", status.Error, provider.Error), "; ")
+		}
+
+		status.Details = strings.TrimPrefix(fmt.Sprintf("%s, %s", status.Details, config.Name), ", ")
+		statuses[config.SourceRole] = status
+	}
+
+	s.Status.RoleStatuses = statuses
+	return s
+}
+
 func (s *AccessRequest) HasPermissions(user string, groups []string, utype string) bool {
 
 	rule := s.Status.ApprovalRule
@@ -215,6 +407,16 @@ func (s *AccessRequest) HasPermissions(user string, groups []string, utype strin
 	return false
 }
 
+// GetDelegator returns approver listed in approval rule users who delegated approval to the caller
+func (s *AccessRequest) GetDelegator(delegations []Delegation) string {
+	for _, d := range delegations {
+		if slices.Contains(s.Status.ApprovalRule.Users, d.Delegator) {
+			return d.Delegator
+		}
+	}
+	return ""
+}
+
 func (s *AccessRequest) SetTraceId(ctx context.Context) *AccessRequest {
 	// ctx := context.Background() // Use your function's actual context here
 	span := trace.SpanFromContext(ctx)
@@ -224,8 +426,14 @@ func (s *AccessRequest) SetTraceId(ctx context.Context) *AccessRequest {
 	return s
 }
 
+// SetExpiration sets expiration time from TTL. Standing requests without TTL never expire
 func (s *AccessRequest) SetExpiration(ctx context.Context) *AccessRequest {
 
+	if s.IsStanding() {
+		s.Status.ExpiresAt = nil
+		return s
+	}
+
 	duration, _ := time.ParseDuration(s.Details.TTL)
 	expires := time.Now().Add(duration)
 
@@ -233,3 +441,135 @@ func (s *AccessRequest) SetExpiration(ctx context.Context) *AccessRequest {
 
 	return s
 }
+
+// IsStanding reports whether request has no TTL
+func (s *AccessRequest) IsStanding() bool {
+	return s.Details.TTL == ""
+}
+
+// Expiration returns when granted access ends. ExpiresAt takes precedence, otherwise TTL is counted from approval or from now when not yet approved. Standing requests return nil
+func (s *AccessRequest) Expiration(now time.Time) (*time.Time, error) {
+
+	if s.Status.ExpiresAt != nil {
+		return s.Status.ExpiresAt, nil
+	}
+	if s.IsStanding() {
+		return nil, nil
+	}
+
+	duration, err := time.ParseDuration(s.Details.TTL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ttl: %w", err)
+	}
+
+	start := now
+	if s.Status.ApprovedAt != nil {
+		start = *s.Status.ApprovedAt
+	}
+	expires := start.Add(duration)
+	return &expires, nil
+}
+
+// IsExpired reports whether granted access has expired at now. Standing requests never expire
+func (s *AccessRequest) IsExpired(now time.Time) (bool, error) {
+
+	expires, err := s.Expiration(now)
+	if err != nil || expires == nil {
+		return false, err
+	}
+	return now.After(*expires), nil
+}
+
+// ValidateTTL checks that TTL is valid. Empty TTL is allowed only for roles permitting standing access
+func (s *AccessRequest) ValidateTTL(role AccessRole) error {
+
+	if s.IsStanding() {
+		if !role.Standing.Enabled {
+			return fmt.Errorf("ttl is required. Role %s does not allow standing access", role.Name)
+		}
+		return nil
+	}
+
+	duration, err := time.ParseDuration(s.Details.TTL)
+	if err != nil {
+		return fmt.Errorf("invalid ttl: %w", err)
+	}
+	if duration <= 0 {
+		return fmt.Errorf("ttl must be positive: %s", s.Details.TTL)
+	}
+	return nil
+}
+
+// SetRecertificationPolicy copies role recertification settings to standing requests
+func (s *AccessRequest) SetRecertificationPolicy(standing StandingAccess) *AccessRequest {
+
+	if !s.IsStanding() || standing.RecertificationInterval == "" {
+		s.Status.Recertification = nil
+		return s
+	}
+
+	s.Status.Recertification = &AccessRequestRecertification{
+		Interval:    standing.RecertificationInterval,
+		GracePeriod: standing.GracePeriod,
+	}
+	return s
+}
+
+// ScheduleRecertification sets the next recertification due date
+func (s *AccessRequest) ScheduleRecertification(now time.Time) *AccessRequest {
+
+	recert := s.Status.Recertification
+	if recert == nil {
+		return s
+	}
+
+	interval, _ := time.ParseDuration(recert.Interval)
+	due := now.Add(interval)
+
+	recert.DueAt = &due
+	recert.RequestedAt = nil
+	return s
+}
+
+// SetRecertificationRequested marks that approvers were asked to recertify
+func (s *AccessRequest) SetRecertificationRequested(now time.Time) *AccessRequest {
+
+	if s.Status.Recertification != nil {
+		s.Status.Recertification.RequestedAt = &now
+	}
+	return s
+}
+
+// SetRecertified records recertification and schedules the next one
+func (s *AccessRequest) SetRecertified(recertifiedBy string, now time.Time) *AccessRequest {
+
+	if s.Status.Recertification == nil {
+		return s
+	}
+
+	s.Status.Recertification.RecertifiedBy = recertifiedBy
+	s.Status.Recertification.RecertifiedAt = &now
+	return s.ScheduleRecertification(now)
+}
+
+// IsRecertificationDue reports whether approvers should be asked to recertify
+func (s *AccessRequest) IsRecertificationDue(now time.Time) bool {
+
+	recert := s.Status.Recertification
+	if !s.IsActive() || recert == nil || recert.DueAt == nil || recert.RequestedAt != nil {
+		return false
+	}
+	return now.After(*recert.DueAt)
+}
+
+// IsRecertificationOverdue reports whether recertification was not done within the grace period
+func (s *AccessRequest) IsRecertificationOverdue(now time.Time) bool {
+
+	recert := s.Status.Recertification
+	if !s.IsActive() || recert == nil || recert.DueAt == nil || recert.RequestedAt == nil {
+		return false
+	}
+
+	grace, _ := time.ParseDuration(recert.GracePeriod)
+	return now.After(recert.DueAt.Add(grace))
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestIsActive(t *testing.T) {
+	request := &AccessRequest{}
+	request.SetStatusPending()
+	assert.False(t, request.IsActive())
+
+	request.SetStatusApprove("jane.doe")
+	assert.True(t, request.IsActive())
+	assert.NotNil(t, request.Status.ApprovedAt)
+
+	request.SetStatusExpired()
+	assert.False(t, request.IsActive())
+	assert.NotNil(t, request.Status.ExpiredAt)
+}
+
+func TestIsArchivedBefore(t *testing.T) {
+	now := time.Now()
+
+	request := &AccessRequest{}
+	request.SetDeletedBy("jane.doe")
+	assert.Equal(t, "jane.doe", request.Status.DeletedBy)
+	assert.False(t, request.IsArchivedBefore(now))
+
+	request.DeletedAt = gorm.DeletedAt{Time: now.Add(-time.Hour), Valid: true}
+	assert.True(t, request.IsArchivedBefore(now))
+	assert.False(t, request.IsArchivedBefore(now.Add(-2*time.Hour)))
+}
+
+func TestDeletedAtJSON(t *testing.T) {
+	data, err := json.Marshal(AccessRequest{})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "deletedAt")
+
+	data, err = json.Marshal(AccessRequest{DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "deletedAt")
+}
+
+func TestIsExpired(t *testing.T) {
+	now := time.Now()
+
+	// TTL is counted from approval
+	approvedAt := now.Add(-3 * time.Hour)
+	request := &AccessRequest{}
+	request.Details.TTL = "2h"
+	request.Status.ApprovedAt = &approvedAt
+	expired, err := request.IsExpired(now)
+	require.NoError(t, err)
+	assert.True(t, expired)
+
+	expires, err := (&AccessRequest{Details: request.Details}).Expiration(now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(2*time.Hour), *expires)
+
+	// Expiration time takes precedence
+	expiresAt := now.Add(-time.Minute)
+	request.Status.ExpiresAt = &expiresAt
+	expired, err = request.IsExpired(now)
+	require.NoError(t, err)
+	assert.True(t, expired)
+
+	standing := &AccessRequest{CreatedAt: now.Add(-1000 * time.Hour)}
+	expired, err = standing.IsExpired(now)
+	require.NoError(t, err)
+	assert.False(t, expired)
+	expires, err = standing.Expiration(now)
+	require.NoError(t, err)
+	assert.Nil(t, expires)
+
+	invalid := &AccessRequest{}
+	invalid.Details.TTL = "week"
+	_, err = invalid.IsExpired(now)
+	assert.Error(t, err)
+}
+
+func TestGetApprovers(t *testing.T) {
+	request := &AccessRequest{Id: "req-1"}
+	request.SetApprovalRule(ApprovalRule{Name: "sre", Users: []string{"bob"}, Groups: []string{"sre"}})
+	assert.Equal(t, []AccessRequestApprover{
+		{AccessRequestId: "req-1", Kind: ApproverKindUser, Name: "bob"},
+		{AccessRequestId: "req-1", Kind: ApproverKindGroup, Name: "sre"},
+	}, request.Approvers)
+
+	// Groups of unnamed rules are not honoured
+	request.SetApprovalRule(ApprovalRule{Groups: []string{"sre"}})
+	assert.Empty(t, request.Approvers)
+}
+
+func TestValidateProviderUsernames(t *testing.T) {
+	roles := []AccessRole{
+		{Name: "Repository", Providers: []ProviderConfig{{Name: "Github", Provider: "github"}}},
+		{Name: "Cluster", Providers: []ProviderConfig{{Name: "Teleport", Provider: "teleport"}, {Name: "Audit", Provider: "webhook"}}},
+		{Name: "Oncall", Roles: []AccessRoleRef{{Name: "Repository"}, {Name: "Cluster"}}},
+	}
+	oncall, err := roles[2].Resolve(roles)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"github", "teleport", "webhook"}, oncall.ProviderKinds())
+
+	// Webhook falls back to the requester
+	requiresUsername := func(kind string) bool { return kind != "webhook" }
+
+	// Usernames of providers outside the role are not required
+	request := &AccessRequest{}
+	request.SetProviderUsernames(map[string]string{"github": "alice", "gitlab": ""})
+	assert.NoError(t, request.ValidateProviderUsernames(roles[0], requiresUsername))
+
+	err = request.ValidateProviderUsernames(oncall, requiresUsername)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "[teleport]")
+}
+
+func TestProviderReferences(t *testing.T) {
+	request := &AccessRequest{}
+	assert.Empty(t, request.GetProviderReference("Vault", "tokenAccessor"))
+
+	request.SetProviderReference("Vault", "tokenAccessor", "acc1")
+	request.SetProviderStatusGranted("Vault", "policy payments-read", "")
+	assert.Equal(t, "acc1", request.GetProviderReference("Vault", "tokenAccessor"))
+
+	// References are internal to providers
+	data, err := json.Marshal(request)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "acc1")
+}
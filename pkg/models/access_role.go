@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
@@ -17,6 +18,40 @@ type AccessRole struct {
 	Annotations     map[string]string `json:"annotations" gorm:"serializer:json"`
 	Providers       []ProviderConfig  `json:"providers" gorm:"serializer:json"` // Multiple access mappings for the role
 	ApprovalRuleRef ApprovalRuleRef   `json:"approvalRuleRef" gorm:"embedded;embeddedPrefix:approvalRuleRef_"`
+	Standing        StandingAccess    `json:"standing" gorm:"embedded;embeddedPrefix:standing_"`
+	Roles           []AccessRoleRef   `json:"roles,omitempty" gorm:"serializer:json"` // Constituent roles of a composite role
+}
+
+// StandingAccess allows requesting the role without TTL. Standing grants are periodically recertified by approvers
+type StandingAccess struct {
+	Enabled bool `json:"enabled"`
+	// Interval between recertifications
+	RecertificationInterval string `json:"recertificationInterval" example:"2160h"`
+	// Time approvers have to recertify the grant before it is expired
+	GracePeriod string `json:"gracePeriod" example:"168h"`
+}
+
+// Validate checks that standing access is recertified periodically
+func (s StandingAccess) Validate() error {
+
+	if !s.Enabled {
+		return nil
+	}
+
+	interval, err := time.ParseDuration(s.RecertificationInterval)
+	if err != nil {
+		return fmt.Errorf("standing access requires a valid recertificationInterval: %w", err)
+	}
+	if interval <= 0 {
+		return fmt.Errorf("recertificationInterval must be positive: %s", s.RecertificationInterval)
+	}
+
+	if s.GracePeriod != "" {
+		if _, err := time.ParseDuration(s.GracePeriod); err != nil {
+			return fmt.Errorf("invalid gracePeriod: %w", err)
+		}
+	}
+	return nil
 }
 
 type ProviderConfig struct {
@@ -25,6 +60,8 @@ type ProviderConfig struct {
 	Provider      string            `json:"provider"`
 	CredentialRef CredentialRef     `json:"credentialRef" gorm:"embedded;embeddedPrefix:credentialRef_"`
 	Parameters    map[string]string `json:"parameters" gorm:"serializer:json"`
+	// Constituent role which defines the provider. Set when composite role is resolved
+	SourceRole string `json:"-" koanf:"-" gorm:"-" swaggerignore:"true"`
 }
 
 type CredentialRef struct {
@@ -88,6 +125,23 @@ func (a *AccessRole) HasAccessRolePermissions(user string, groups []string, rule
 	return false
 }
 
+func (a *AccessRole) HasTag(tag string) bool {
+	return slices.Contains(a.Tags, tag)
+}
+
+// Allows checks if user is listed in the rule directly or via group
+func (r *ApprovalRule) Allows(user string, groups []string) bool {
+	if slices.Contains(r.Users, user) {
+		return true
+	}
+	for _, userGroup := range groups {
+		if slices.Contains(r.Groups, userGroup) {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *AccessRole) GetApprovalRule(rules []ApprovalRule) ApprovalRule {
 
 	for _, r := range rules {
@@ -98,3 +152,87 @@ func (a *AccessRole) GetApprovalRule(rules []ApprovalRule) ApprovalRule {
 
 	return ApprovalRule{}
 }
+
+// ProviderKinds returns distinct provider kinds used by the role. Composite roles must be resolved first
+func (a *AccessRole) ProviderKinds() []string {
+	kinds := []string{}
+	for _, config := range a.Providers {
+		if !slices.Contains(kinds, config.Provider) {
+			kinds = append(kinds, config.Provider)
+		}
+	}
+	return kinds
+}
+
+// IsComposite reports whether role bundles other roles
+func (a *AccessRole) IsComposite() bool {
+	return len(a.Roles) > 0
+}
+
+// Resolve expands composite role into the union of constituent role providers.
+// Providers of constituent roles are prefixed with the role name to keep provider statuses apart
+func (a *AccessRole) Resolve(roles []AccessRole) (AccessRole, error) {
+
+	if !a.IsComposite() {
+		return *a, nil
+	}
+
+	resolved := *a
+	resolved.Providers = []ProviderConfig{}
+	seen := map[string]bool{}
+
+	var walk func(role AccessRole, path []string) error
+	walk = func(role AccessRole, path []string) error {
+
+		if slices.Contains(path, role.Name) {
+			return fmt.Errorf("composite role cycle: %s -> %s", strings.Join(path, " -> "), role.Name)
+		}
+		path = append(path, role.Name)
+
+		for _, config := range role.Providers {
+			if role.Name != a.Name {
+				config.SourceRole = role.Name
+				config.Name = fmt.Sprintf("%s/%s", role.Name, config.Name)
+			}
+			if seen[config.Name] {
+				continue
+			}
+			seen[config.Name] = true
+			resolved.Providers = append(resolved.Providers, config)
+		}
+
+		for _, ref := range role.Roles {
+			child, err := findRole(roles, ref.Name)
+			if err != nil {
+				return fmt.Errorf("composite role %s: %w", role.Name, err)
+			}
+			if err := walk(child, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(*a, []string{}); err != nil {
+		return AccessRole{}, err
+	}
+
+	return resolved, nil
+}
+
+// BaseName returns provider name without constituent role prefix
+func (p *ProviderConfig) BaseName() string {
+	if p.SourceRole == "" {
+		return p.Name
+	}
+	return strings.TrimPrefix(p.Name, p.SourceRole+"/")
+}
+
+func findRole(roles []AccessRole, name string) (AccessRole, error) {
+	for _, role := range roles {
+		if role.Name == name {
+			return role, nil
+		}
+	}
+	return AccessRole{}, fmt.Errorf("role not found: %s", name)
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStandingAccessValidate(t *testing.T) {
+	assert.NoError(t, StandingAccess{}.Validate())
+	assert.NoError(t, StandingAccess{Enabled: true, RecertificationInterval: "2160h", GracePeriod: "168h"}.Validate())
+	assert.NoError(t, StandingAccess{Enabled: true, RecertificationInterval: "2160h"}.Validate())
+
+	// Standing access must be recertified
+	assert.Error(t, StandingAccess{Enabled: true}.Validate())
+	assert.Error(t, StandingAccess{Enabled: true, RecertificationInterval: "quarterly"}.Validate())
+	assert.Error(t, StandingAccess{Enabled: true, RecertificationInterval: "-1h"}.Validate())
+	assert.Error(t, StandingAccess{Enabled: true, RecertificationInterval: "2160h", GracePeriod: "week"}.Validate())
+}
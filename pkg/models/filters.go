@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// AccessRequestFilter narrows down access request list results
+type AccessRequestFilter struct {
+	Status        string    `form:"status" example:"Approved"`
+	Role          string    `form:"role" example:"SRE-PU-ACCESS"`
+	Requester     string    `form:"requester" example:"john.doe"`
+	Approver      string    `form:"approver" example:"jane.doe"`
+	Tag           string    `form:"tag" example:"sre"`
+	CreatedAfter  time.Time `form:"createdAfter" example:"2025-01-01T00:00:00Z"`
+	CreatedBefore time.Time `form:"createdBefore" example:"2025-02-01T00:00:00Z"`
+	ExpiresAfter  time.Time `form:"expiresAfter" example:"2025-01-01T00:00:00Z"`
+	ExpiresBefore time.Time `form:"expiresBefore" example:"2025-02-01T00:00:00Z"`
+
+	// Resolved server side. Nil means no restriction
+	RoleNames  []string             `form:"-" swaggerignore:"true"`
+	Visibility *AccessRequestAccess `form:"-" swaggerignore:"true"`
+}
+
+// AccessRequestAccess limits results to requests created by the user or requests the user can approve.
+// Approval is checked against the approval rule snapshot taken when the request was created
+type AccessRequestAccess struct {
+	Requester string
+	// Approver users, i.e. the caller and approvers who delegated to the caller
+	Users  []string
+	Groups []string
+}
+
+// EventFilter narrows down event list results
+type EventFilter struct {
+	Type          string    `form:"type" example:"cto2b.passage.accessRequest.created"`
+	Source        string    `form:"source" example:"passage-server"`
+	Author        string    `form:"author" example:"john.doe"`
+	ParentID      string    `form:"parentId"`
+	TransactionID string    `form:"transactionId"`
+	After         time.Time `form:"after" example:"2025-01-01T00:00:00Z"`
+	Before        time.Time `form:"before" example:"2025-02-01T00:00:00Z"`
+}
+
+// GetRoleNamesByTag returns names of roles tagged with tag
+func GetRoleNamesByTag(roles []AccessRole, tag string) []string {
+	names := []string{}
+	for _, role := range roles {
+		if role.HasTag(tag) {
+			names = append(names, role.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	PaginationDefaultLimit = 100
+	PaginationMaxLimit     = 1000
+)
+
+// Pagination describes cursor based pagination and sorting of list endpoints
+type Pagination struct {
+	// Maximum number of records to return. Defaults to 100
+	Limit int `form:"limit" example:"50"`
+	// Opaque cursor returned in the Link header of the previous page
+	Cursor string `form:"cursor"`
+	// Sort field. Prefix with "-" for descending order
+	Sort string `form:"sort" example:"-createdAt"`
+}
+
+// Cursor points to the last record of the previous page
+type Cursor struct {
+	Value time.Time `json:"v"`
+	Id    string    `json:"id"`
+}
+
+// Validate checks limits and resolves defaults
+func (p *Pagination) Validate() error {
+	if p.Limit < 0 {
+		return fmt.Errorf("limit must be positive: %d", p.Limit)
+	}
+	if p.Limit > PaginationMaxLimit {
+		p.Limit = PaginationMaxLimit
+	}
+	if p.Limit == 0 {
+		p.Limit = PaginationDefaultLimit
+	}
+	return nil
+}
+
+// SortField returns sort field name and direction
+func (p *Pagination) SortField(defaultField string) (field string, desc bool) {
+	field = p.Sort
+	if field == "" {
+		field = "-" + defaultField
+	}
+	if strings.HasPrefix(field, "-") {
+		return strings.TrimPrefix(field, "-"), true
+	}
+	return strings.TrimPrefix(field, "+"), false
+}
+
+func (p *Pagination) DecodeCursor() (*Cursor, error) {
+	if p.Cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(p.Cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	cursor := &Cursor{}
+	if err := json.Unmarshal(data, cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return cursor, nil
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Page holds pagination results
+type Page struct {
+	Total      int64
+	NextCursor string
+}
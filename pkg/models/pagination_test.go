@@ -0,0 +1,64 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginationValidate(t *testing.T) {
+	page := Pagination{Limit: -1}
+	assert.Error(t, page.Validate())
+
+	page = Pagination{Limit: PaginationMaxLimit + 1}
+	require.NoError(t, page.Validate())
+	assert.Equal(t, PaginationMaxLimit, page.Limit)
+
+	// Requests without limit fall back to default page size
+	page = Pagination{Cursor: "abc"}
+	require.NoError(t, page.Validate())
+	assert.Equal(t, PaginationDefaultLimit, page.Limit)
+
+	page = Pagination{}
+	require.NoError(t, page.Validate())
+	assert.Equal(t, PaginationDefaultLimit, page.Limit)
+}
+
+func TestPaginationSortField(t *testing.T) {
+	for sort, expected := range map[string]struct {
+		field string
+		desc  bool
+	}{
+		"":           {"createdAt", true},
+		"-updatedAt": {"updatedAt", true},
+		"+updatedAt": {"updatedAt", false},
+		"updatedAt":  {"updatedAt", false},
+	} {
+		page := Pagination{Sort: sort}
+		field, desc := page.SortField("createdAt")
+		assert.Equal(t, expected.field, field, sort)
+		assert.Equal(t, expected.desc, desc, sort)
+	}
+}
+
+func TestPaginationCursor(t *testing.T) {
+	page := Pagination{}
+	cursor, err := page.DecodeCursor()
+	require.NoError(t, err)
+	assert.Nil(t, cursor)
+
+	value := time.Date(2026, 3, 14, 18, 30, 0, 0, time.UTC)
+	page.Cursor = Cursor{Value: value, Id: "req-1"}.Encode()
+	cursor, err = page.DecodeCursor()
+	require.NoError(t, err)
+	assert.True(t, value.Equal(cursor.Value))
+	assert.Equal(t, "req-1", cursor.Id)
+
+	for _, invalid := range []string{"not base64!", "bm90IGpzb24"} {
+		page.Cursor = invalid
+		_, err = page.DecodeCursor()
+		assert.Error(t, err, invalid)
+	}
+}
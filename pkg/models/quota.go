@@ -0,0 +1,155 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Quota limits access requested by a single user. Quota applies to roles matched by name, tag or approval rule
+type Quota struct {
+	Name          string   `json:"name" example:"Privileged access"`
+	Description   string   `json:"description" example:"No more than 3 simultaneously active privileged roles"`
+	Roles         []string `json:"roles" example:"SRE-PU-ACCESS"`
+	Tags          []string `json:"tags" example:"sre"`
+	ApprovalRules []string `json:"approvalRules" example:"SRE approvers"`
+	// Maximum number of simultaneously active requests. Zero means unlimited
+	MaxActive int `json:"maxActive" example:"3"`
+	// Maximum total access duration within period
+	MaxDuration string `json:"maxDuration" example:"40h"`
+	// Maximum number of requests created within period. Zero means unlimited
+	MaxRequests int `json:"maxRequests" example:"10"`
+	// Rolling window for MaxDuration and MaxRequests
+	Period string `json:"period" example:"720h"`
+}
+
+// QuotaUsage describes current quota consumption of a user
+type QuotaUsage struct {
+	Quota       string `json:"quota" example:"Privileged access"`
+	Active      int    `json:"active" example:"1"`
+	MaxActive   int    `json:"maxActive,omitempty" example:"3"`
+	Duration    string `json:"duration" example:"12h0m0s"`
+	MaxDuration string `json:"maxDuration,omitempty" example:"40h"`
+	Requests    int    `json:"requests" example:"4"`
+	MaxRequests int    `json:"maxRequests,omitempty" example:"10"`
+	Period      string `json:"period,omitempty" example:"720h"`
+
+	duration    time.Duration
+	maxDuration time.Duration
+	// committed duration by access request id
+	committed map[string]time.Duration
+}
+
+// Matches checks if quota applies to role
+func (q *Quota) Matches(role AccessRole) bool {
+
+	if slices.Contains(q.Roles, role.Name) {
+		return true
+	}
+
+	for _, tag := range q.Tags {
+		if role.HasTag(tag) {
+			return true
+		}
+	}
+
+	return slices.Contains(q.ApprovalRules, role.ApprovalRuleRef.Name)
+}
+
+// Usage calculates quota consumption from user requests
+func (q *Quota) Usage(requests []AccessRequest, roles []AccessRole, now time.Time) (QuotaUsage, error) {
+
+	usage := QuotaUsage{
+		Quota:       q.Name,
+		MaxActive:   q.MaxActive,
+		MaxDuration: q.MaxDuration,
+		MaxRequests: q.MaxRequests,
+		Period:      q.Period,
+		committed:   map[string]time.Duration{},
+	}
+
+	var period time.Duration
+	if q.Period != "" {
+		var err error
+		period, err = time.ParseDuration(q.Period)
+		if err != nil {
+			return usage, fmt.Errorf("quota %s: invalid period: %w", q.Name, err)
+		}
+	}
+
+	if q.MaxDuration != "" {
+		var err error
+		usage.maxDuration, err = time.ParseDuration(q.MaxDuration)
+		if err != nil {
+			return usage, fmt.Errorf("quota %s: invalid maxDuration: %w", q.Name, err)
+		}
+	}
+
+	windowStart := now.Add(-period)
+
+	for _, request := range requests {
+
+		role, err := request.GetRole(roles)
+		if err != nil || !q.Matches(role) {
+			continue
+		}
+
+		if request.IsActive() {
+			usage.Active++
+		}
+
+		if period == 0 || request.CreatedAt.After(windowStart) {
+			usage.Requests++
+		}
+
+		committed := request.CommittedDuration(windowStart, now)
+		usage.committed[request.Id] = committed
+		usage.duration += committed
+	}
+
+	usage.Duration = usage.duration.String()
+	return usage, nil
+}
+
+// CheckCreate validates new request against quota limits
+func (u *QuotaUsage) CheckCreate(request AccessRequest) error {
+
+	if u.MaxRequests > 0 && u.Requests+1 > u.MaxRequests {
+		return fmt.Errorf("quota %s: %d of %d requests per %s used", u.Quota, u.Requests, u.MaxRequests, u.Period)
+	}
+
+	ttl, _ := time.ParseDuration(request.Details.TTL)
+	return u.checkDuration(request, ttl)
+}
+
+// CheckApprove validates request approval against quota limits
+func (u *QuotaUsage) CheckApprove(request AccessRequest) error {
+
+	if u.MaxActive > 0 && u.Active+1 > u.MaxActive {
+		return fmt.Errorf("quota %s: %d of %d active requests", u.Quota, u.Active, u.MaxActive)
+	}
+
+	// Pending request is already counted in usage
+	var requested time.Duration
+	if _, counted := u.committed[request.Id]; !counted {
+		requested, _ = time.ParseDuration(request.Details.TTL)
+	}
+	return u.checkDuration(request, requested)
+}
+
+// checkDuration validates that usage with requested duration stays within the duration limit
+func (u *QuotaUsage) checkDuration(request AccessRequest, requested time.Duration) error {
+
+	if u.maxDuration == 0 {
+		return nil
+	}
+
+	if request.IsStanding() {
+		return fmt.Errorf("quota %s: standing access is not allowed with duration limit %s", u.Quota, u.MaxDuration)
+	}
+
+	if u.duration+requested > u.maxDuration {
+		return fmt.Errorf("quota %s: %s of %s per %s used, requested %s", u.Quota, u.duration, u.MaxDuration, u.Period, request.Details.TTL)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var quotaRoles = []AccessRole{
+	{Name: "SRE-PU-ACCESS", Tags: []string{"sre"}},
+	{Name: "Developer"},
+}
+
+func quotaRequest(id string, role string, ttl string, createdAt time.Time) AccessRequest {
+	request := AccessRequest{Id: id, CreatedAt: createdAt, RoleRef: AccessRoleRef{Name: role}}
+	request.Details.TTL = ttl
+	request.SetStatusPending()
+	if ttl != "" {
+		duration, _ := time.ParseDuration(ttl)
+		expires := createdAt.Add(duration)
+		request.Status.ExpiresAt = &expires
+	}
+	return request
+}
+
+func approved(request AccessRequest, at time.Time) AccessRequest {
+	request.SetStatusApprove("jane.doe")
+	request.Status.ApprovedAt = &at
+	return request
+}
+
+func TestQuotaUsage(t *testing.T) {
+	now := time.Now()
+	quota := Quota{Name: "Privileged", Tags: []string{"sre"}, MaxActive: 2, MaxDuration: "40h", MaxRequests: 5, Period: "720h"}
+
+	expired := approved(quotaRequest("expired", "SRE-PU-ACCESS", "8h", now.Add(-48*time.Hour)), now.Add(-48*time.Hour))
+	expired.SetStatusExpired()
+	revokedAt := now.Add(-46 * time.Hour)
+	expired.Status.ExpiredAt = &revokedAt
+
+	old := approved(quotaRequest("old", "SRE-PU-ACCESS", "8h", now.Add(-1000*time.Hour)), now.Add(-1000*time.Hour))
+	old.SetStatusExpired()
+
+	denied := quotaRequest("denied", "SRE-PU-ACCESS", "8h", now.Add(-time.Hour))
+	denied.SetStatusDenied("jane.doe")
+
+	usage, err := quota.Usage([]AccessRequest{
+		// Active until expiration, not only the elapsed hour
+		approved(quotaRequest("active", "SRE-PU-ACCESS", "8h", now.Add(-time.Hour)), now.Add(-time.Hour)),
+		// Pending requests count the time they would grant
+		quotaRequest("pending", "SRE-PU-ACCESS", "10h", now),
+		// Revoked early counts until revocation
+		expired,
+		denied,
+		// Outside the window
+		old,
+		// Role not matched
+		approved(quotaRequest("other", "Developer", "8h", now), now),
+	}, quotaRoles, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, usage.Active)
+	assert.Equal(t, 4, usage.Requests)
+	assert.Equal(t, 20*time.Hour, usage.duration.Round(time.Minute))
+
+	_, err = (&Quota{Name: "Invalid", Period: "month"}).Usage(nil, quotaRoles, now)
+	assert.Error(t, err)
+	_, err = (&Quota{Name: "Invalid", MaxDuration: "week"}).Usage(nil, quotaRoles, now)
+	assert.Error(t, err)
+}
+
+func TestQuotaCheckCreate(t *testing.T) {
+	now := time.Now()
+	quota := Quota{Name: "Privileged", Roles: []string{"SRE-PU-ACCESS"}, MaxDuration: "40h", Period: "720h"}
+
+	// Pending full length requests cannot be stacked
+	requests := []AccessRequest{}
+	for _, id := range []string{"first", "second"} {
+		usage, err := quota.Usage(requests, quotaRoles, now)
+		require.NoError(t, err)
+
+		request := quotaRequest(id, "SRE-PU-ACCESS", "20h", now)
+		require.NoError(t, usage.CheckCreate(request))
+		requests = append(requests, request)
+	}
+
+	usage, err := quota.Usage(requests, quotaRoles, now)
+	require.NoError(t, err)
+	assert.Error(t, usage.CheckCreate(quotaRequest("third", "SRE-PU-ACCESS", "1h", now)))
+	assert.Error(t, usage.CheckCreate(quotaRequest("standing", "SRE-PU-ACCESS", "", now)))
+
+	// Request count limit
+	quota = Quota{Name: "Requests", Roles: []string{"SRE-PU-ACCESS"}, MaxRequests: 2, Period: "24h"}
+	usage, err = quota.Usage(requests, quotaRoles, now)
+	require.NoError(t, err)
+	assert.Error(t, usage.CheckCreate(quotaRequest("third", "SRE-PU-ACCESS", "1h", now)))
+}
+
+func TestQuotaCheckApprove(t *testing.T) {
+	now := time.Now()
+	quota := Quota{Name: "Privileged", Roles: []string{"SRE-PU-ACCESS"}, MaxActive: 1, MaxDuration: "40h", Period: "720h"}
+
+	// Pending request being approved is not counted twice
+	pending := quotaRequest("pending", "SRE-PU-ACCESS", "40h", now)
+	usage, err := quota.Usage([]AccessRequest{pending}, quotaRoles, now)
+	require.NoError(t, err)
+	assert.NoError(t, usage.CheckApprove(pending))
+
+	// Active request limit
+	active := approved(quotaRequest("active", "SRE-PU-ACCESS", "1h", now), now)
+	usage, err = quota.Usage([]AccessRequest{active, quotaRequest("next", "SRE-PU-ACCESS", "1h", now)}, quotaRoles, now)
+	require.NoError(t, err)
+	assert.Error(t, usage.CheckApprove(quotaRequest("next", "SRE-PU-ACCESS", "1h", now)))
+
+	// Duration limit with another pending request
+	quota.MaxActive = 0
+	other := quotaRequest("other", "SRE-PU-ACCESS", "30h", now)
+	usage, err = quota.Usage([]AccessRequest{other, pending}, quotaRoles, now)
+	require.NoError(t, err)
+	assert.Error(t, usage.CheckApprove(pending))
+}
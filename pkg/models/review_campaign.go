@@ -0,0 +1,246 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Review campaign status constants
+const (
+	ReviewCampaignOpen      = "Open"
+	ReviewCampaignCompleted = "Completed"
+	ReviewDecisionPending   = "Pending"
+	ReviewDecisionKeep      = "Keep"
+	ReviewDecisionRevoke    = "Revoke"
+)
+
+// Access review campaign. Snapshots current grants and collects reviewer decisions
+type ReviewCampaign struct {
+	Id          string               `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time            `gorm:"index" swaggerignore:"true" json:"createdAt"`
+	UpdatedAt   time.Time            `swaggerignore:"true" json:"updatedAt"`
+	Name        string               `json:"name" example:"2025 Q1 access review"`
+	Description string               `json:"description" example:"Quarterly SOC2 access review"`
+	Scope       ReviewCampaignScope  `gorm:"embedded;embeddedPrefix:scope_" json:"scope"`
+	DueAt       *time.Time           `json:"dueAt"`
+	Status      ReviewCampaignStatus `swaggerignore:"true" gorm:"embedded;embeddedPrefix:status_" json:"status"`
+	Items       []ReviewItem         `swaggerignore:"true" gorm:"foreignKey:CampaignId;constraint:OnDelete:CASCADE" json:"items,omitempty"`
+}
+
+// ReviewCampaignScope selects roles included in the campaign. Empty scope includes all roles
+type ReviewCampaignScope struct {
+	Roles     []string `json:"roles" gorm:"serializer:json" example:"SRE-PU-ACCESS"`
+	Tags      []string `json:"tags" gorm:"serializer:json" example:"sre"`
+	Providers []string `json:"providers" gorm:"serializer:json" example:"GitlabSrePu"`
+}
+
+type ReviewCampaignStatus struct {
+	Status      string     `json:"status"`
+	CreatedBy   string     `json:"createdBy"`
+	CompletedAt *time.Time `json:"completedAt"`
+	Warnings    []string   `json:"warnings" gorm:"serializer:json"`
+}
+
+// Single grant under review
+type ReviewItem struct {
+	Id              string       `gorm:"primaryKey" json:"id"`
+	CreatedAt       time.Time    `json:"createdAt"`
+	UpdatedAt       time.Time    `json:"updatedAt"`
+	CampaignId      string       `gorm:"index" json:"campaignId"`
+	Role            string       `json:"role" example:"SRE-PU-ACCESS"`
+	Provider        string       `json:"provider" example:"GitlabSrePu"`
+	ProviderKind    string       `json:"providerKind" example:"gitlab"`
+	Username        string       `json:"username" example:"john.doe"`
+	Requester       string       `json:"requester" example:"john.doe"`
+	AccessRequestId string       `json:"accessRequestId"`
+	Reviewers       ApprovalRule `json:"reviewers" gorm:"serializer:json"`
+	Decision        string       `gorm:"index" json:"decision" example:"Pending"`
+	DecidedBy       string       `json:"decidedBy"`
+	DecidedAt       *time.Time   `json:"decidedAt"`
+	Comment         string       `json:"comment"`
+	Error           string       `json:"error"`
+}
+
+// ReviewDecision is submitted by reviewers
+type ReviewDecision struct {
+	Decision string `json:"decision" binding:"required,oneof=Keep Revoke" example:"Keep"`
+	Comment  string `json:"comment" example:"Still on-call for production"`
+}
+
+// ReviewCampaignReport summarizes campaign decisions
+type ReviewCampaignReport struct {
+	Campaign ReviewCampaign `json:"campaign"`
+	Total    int            `json:"total"`
+	Pending  int            `json:"pending"`
+	Kept     int            `json:"kept"`
+	Revoked  int            `json:"revoked"`
+	Failed   int            `json:"failed"`
+}
+
+func (c *ReviewCampaign) Admit() *ReviewCampaign {
+	c.Id = uuid.NewString()
+	c.Status.Status = ReviewCampaignOpen
+	return c
+}
+
+func (c *ReviewCampaign) SetCreatedBy(createdBy string) *ReviewCampaign {
+	c.Status.CreatedBy = createdBy
+	return c
+}
+
+func (c *ReviewCampaign) AddWarning(warning string) *ReviewCampaign {
+	c.Status.Warnings = append(c.Status.Warnings, warning)
+	return c
+}
+
+// InScope checks if role or any of its providers is selected by campaign scope
+func (c *ReviewCampaign) InScope(role AccessRole) bool {
+
+	scope := c.Scope
+	if len(scope.Roles) == 0 && len(scope.Tags) == 0 && len(scope.Providers) == 0 {
+		return true
+	}
+
+	if slices.Contains(scope.Roles, role.Name) {
+		return true
+	}
+
+	for _, tag := range scope.Tags {
+		if role.HasTag(tag) {
+			return true
+		}
+	}
+
+	for _, provider := range role.Providers {
+		if c.ProviderInScope(provider) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ProviderInScope checks if provider should be snapshotted. Empty provider scope includes all providers
+func (c *ReviewCampaign) ProviderInScope(provider ProviderConfig) bool {
+	if len(c.Scope.Providers) == 0 {
+		return true
+	}
+	return slices.Contains(c.Scope.Providers, provider.Name) || slices.Contains(c.Scope.Providers, provider.BaseName())
+}
+
+// AddItem adds item to the campaign. Items with the same role, request, provider and username are added once
+func (c *ReviewCampaign) AddItem(item ReviewItem) *ReviewCampaign {
+
+	for _, existing := range c.Items {
+		if existing.Role == item.Role && existing.AccessRequestId == item.AccessRequestId &&
+			existing.Provider == item.Provider && existing.Username == item.Username {
+			return c
+		}
+	}
+
+	item.Id = uuid.NewString()
+	item.CampaignId = c.Id
+	item.Decision = ReviewDecisionPending
+	c.Items = append(c.Items, item)
+	return c
+}
+
+// Complete marks campaign as completed when all items are decided
+func (c *ReviewCampaign) Complete(now time.Time) bool {
+
+	if c.Status.Status == ReviewCampaignCompleted {
+		return false
+	}
+
+	for _, item := range c.Items {
+		if item.Decision == ReviewDecisionPending || item.Error != "" {
+			return false
+		}
+	}
+
+	c.Status.Status = ReviewCampaignCompleted
+	c.Status.CompletedAt = &now
+	return true
+}
+
+func (c *ReviewCampaign) Report() ReviewCampaignReport {
+
+	report := ReviewCampaignReport{
+		Campaign: *c,
+		Total:    len(c.Items),
+	}
+
+	for _, item := range c.Items {
+		switch {
+		case item.Error != "":
+			report.Failed++
+		case item.Decision == ReviewDecisionKeep:
+			report.Kept++
+		case item.Decision == ReviewDecisionRevoke:
+			report.Revoked++
+		default:
+			report.Pending++
+		}
+	}
+
+	return report
+}
+
+// CanReview checks if user can review any of the campaign items
+func (c *ReviewCampaign) CanReview(user string, groups []string, utype string) bool {
+	for _, item := range c.Items {
+		if item.CanReview(user, groups, utype) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanReview checks if user is allowed to decide on item. Users can not review their own access
+func (i *ReviewItem) CanReview(user string, groups []string, utype string) bool {
+
+	if utype == "token" {
+		return true
+	}
+
+	if i.Requester != "" && i.Requester == user {
+		return false
+	}
+
+	return i.Reviewers.Allows(user, groups)
+}
+
+func (i *ReviewItem) SetDecision(decision ReviewDecision, decidedBy string, now time.Time) *ReviewItem {
+	i.Decision = decision.Decision
+	i.Comment = decision.Comment
+	i.DecidedBy = decidedBy
+	i.DecidedAt = &now
+	i.Error = ""
+	return i
+}
+
+func (i *ReviewItem) SetError(err error) *ReviewItem {
+	i.Error = err.Error()
+	return i
+}
+
+// IsProviderOnly reports whether grant was found at provider without matching access request
+func (i *ReviewItem) IsProviderOnly() bool {
+	return i.AccessRequestId == ""
+}
+
+// CSVHeader returns column names for report export
+func (i ReviewItem) CSVHeader() []string {
+	return []string{"id", "role", "provider", "username", "requester", "accessRequestId", "decision", "decidedBy", "decidedAt", "comment", "error"}
+}
+
+// CSVRecord returns item values for report export
+func (i ReviewItem) CSVRecord() []string {
+	decidedAt := ""
+	if i.DecidedAt != nil {
+		decidedAt = i.DecidedAt.UTC().Format(time.RFC3339)
+	}
+	return []string{i.Id, i.Role, i.Provider, i.Username, i.Requester, i.AccessRequestId, i.Decision, i.DecidedBy, decidedAt, i.Comment, i.Error}
+}
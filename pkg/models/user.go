@@ -1,34 +1,61 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/ssh"
+)
 
 type UserProfile struct {
-	Id       string              `gorm:"primaryKey" json:"id"`
-	Username string              `json:"username"`
-	Settings UserProfileSettings `json:"settings" gorm:"embedded;embeddedPrefix:settings_"`
+	Id          string              `gorm:"primaryKey" json:"id"`
+	Username    string              `json:"username"`
+	Settings    UserProfileSettings `json:"settings" gorm:"embedded;embeddedPrefix:settings_"`
+	Quotas      []QuotaUsage        `json:"quotas,omitempty" gorm:"-"` // Current usage of quotas applying to the user
+	Delegations []Delegation        `json:"delegations" gorm:"foreignKey:Delegator"`
+}
+
+// Delegation allows delegate to approve on behalf of the user for a limited time. Indexed by delegate for permission checks
+type Delegation struct {
+	Id        string    `gorm:"primaryKey" json:"id" example:"3b7af992-5a30-4ce1-821b-cac8194a230b"`
+	Delegator string    `gorm:"index" json:"delegator" example:"jane.doe"`
+	Delegate  string    `gorm:"index:idx_delegation_delegate" json:"delegate" binding:"required" example:"john.doe"`
+	StartsAt  time.Time `gorm:"index:idx_delegation_delegate" json:"startsAt" example:"2025-07-01T00:00:00Z"`
+	Until     time.Time `gorm:"index:idx_delegation_delegate" json:"until" binding:"required" example:"2025-07-14T00:00:00Z"`
+	Reason    string    `json:"reason" example:"Vacation"`
 }
 
 type UserProfileSettings struct {
 	ProviderUsernames
+	// Public key signed by SSH certificate providers, in authorized_keys format
+	SSHPublicKey string `json:"sshPublicKey,omitempty" example:"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBpDpu7t+yANYItPWECX71auIrVAL+y9SJzz1Pdnmumt alice@laptop"`
 }
 
-type ProviderUsernames struct {
-	ProviderUsernames map[string]string `json:"providerUsernames" gorm:"serializer:json"`
-}
+// Validate checks that SSH public key, when set, is a single authorized_keys entry and not a certificate
+func (s *UserProfileSettings) Validate() error {
+	if s.SSHPublicKey == "" {
+		return nil
+	}
 
-func (p *UserProfile) Validate() error {
-	missingKeys := []string{}
-	for username, value := range p.Settings.ProviderUsernames.ProviderUsernames {
-		if value == "" {
-			missingKeys = append(missingKeys, username)
-		}
+	key, _, _, rest, err := ssh.ParseAuthorizedKey([]byte(s.SSHPublicKey))
+	if err != nil {
+		return fmt.Errorf("invalid ssh public key: %w", err)
+	}
+	if strings.TrimSpace(string(rest)) != "" {
+		return fmt.Errorf("invalid ssh public key: expected a single key")
 	}
-	if len(missingKeys) > 0 {
-		return fmt.Errorf("missing username: %s", missingKeys)
+	if _, ok := key.(*ssh.Certificate); ok {
+		return fmt.Errorf("invalid ssh public key: certificates are not accepted")
 	}
 	return nil
 }
 
+type ProviderUsernames struct {
+	ProviderUsernames map[string]string `json:"providerUsernames" gorm:"serializer:json"`
+}
+
 func (p *UserProfile) GetUser() User {
 	return User{
 		Id:       p.Id,
@@ -41,3 +68,29 @@ type User struct {
 	Username string   `json:"username"`
 	Roles    []string `json:"roles,omitempty"`
 }
+
+// Admit validates delegation and assigns id
+func (d *Delegation) Admit(delegator string, now time.Time) error {
+
+	if d.Delegate == delegator {
+		return fmt.Errorf("can not delegate to yourself")
+	}
+	if d.StartsAt.IsZero() {
+		d.StartsAt = now
+	}
+	if !d.Until.After(d.StartsAt) {
+		return fmt.Errorf("delegation must end after it starts")
+	}
+	if !d.Until.After(now) {
+		return fmt.Errorf("delegation already ended")
+	}
+
+	d.Id = uuid.NewString()
+	d.Delegator = delegator
+	return nil
+}
+
+// IsActive reports whether delegation is in effect
+func (d *Delegation) IsActive(now time.Time) bool {
+	return !now.Before(d.StartsAt) && now.Before(d.Until)
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelegationAdmit(t *testing.T) {
+	now := time.Now()
+
+	delegation := Delegation{Delegate: "john.doe", Until: now.Add(time.Hour)}
+	require.NoError(t, delegation.Admit("jane.doe", now))
+	assert.NotEmpty(t, delegation.Id)
+	assert.Equal(t, "jane.doe", delegation.Delegator)
+	assert.Equal(t, now, delegation.StartsAt)
+
+	for _, invalid := range []Delegation{
+		{Delegate: "jane.doe", Until: now.Add(time.Hour)},
+		{Delegate: "john.doe", StartsAt: now.Add(2 * time.Hour), Until: now.Add(time.Hour)},
+		{Delegate: "john.doe", StartsAt: now.Add(-2 * time.Hour), Until: now.Add(-time.Hour)},
+	} {
+		assert.Error(t, invalid.Admit("jane.doe", now), invalid)
+	}
+}
+
+func TestDelegationIsActive(t *testing.T) {
+	now := time.Now()
+	delegation := Delegation{StartsAt: now, Until: now.Add(time.Hour)}
+
+	assert.False(t, delegation.IsActive(now.Add(-time.Second)))
+	assert.True(t, delegation.IsActive(now))
+	assert.True(t, delegation.IsActive(now.Add(59*time.Minute)))
+	assert.False(t, delegation.IsActive(now.Add(time.Hour)))
+}
+
+func TestGetDelegator(t *testing.T) {
+	request := &AccessRequest{}
+	request.SetApprovalRule(ApprovalRule{Users: []string{"jane.doe"}})
+
+	assert.Empty(t, request.GetDelegator([]Delegation{{Delegator: "bob", Delegate: "john.doe"}}))
+	assert.Equal(t, "jane.doe", request.GetDelegator([]Delegation{
+		{Delegator: "bob", Delegate: "john.doe"},
+		{Delegator: "jane.doe", Delegate: "john.doe"},
+	}))
+}
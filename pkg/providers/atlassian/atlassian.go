@@ -10,6 +10,7 @@ import (
 
 	"github.com/CTO2BPublic/passage-server/pkg/config"
 	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
 	"github.com/CTO2BPublic/passage-server/pkg/tracing"
 	jira "github.com/ctreminiom/go-atlassian/v2/jira/v2"
 	"go.opentelemetry.io/otel"
@@ -17,7 +18,7 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-const providerType = "cloudflare"
+const providerType = string(kinds.ProviderKindAtlassian)
 
 var Config = config.GetConfig()
 var Tracer = otel.Tracer("pkg/providers/atlassian")
@@ -184,19 +185,7 @@ func (a *AtlassianProvider) IsAccessExpired(ctx context.Context, request *models
 	_, span := startSpan(ctx, "IsAccessExpired")
 	defer span.End()
 
-	ttl := request.Details.TTL
-	if ttl == "" {
-		return false, errors.New("TTL not specified in access request")
-	}
-
-	// Validate TTL expiration (this assumes TTL is a duration like "24h")
-	expiry, err := time.ParseDuration(ttl)
-	if err != nil {
-		return false, fmt.Errorf("invalid TTL format: %w", err)
-	}
-
-	expirationTime := request.CreatedAt.Add(expiry)
-	return time.Now().After(expirationTime), nil
+	return request.IsExpired(time.Now())
 }
 
 func startSpan(ctx context.Context, name string) (context.Context, *tracing.SpanWrapper) {
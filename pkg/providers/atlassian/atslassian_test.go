@@ -3,6 +3,7 @@ package atlassian
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/CTO2BPublic/passage-server/pkg/config"
 	"github.com/CTO2BPublic/passage-server/pkg/models"
@@ -94,3 +95,31 @@ func TestGroupMembers(t *testing.T) {
 	}
 	assert.True(t, found, "the authenticated user should be in the admin group")
 }
+
+func TestIsAccessExpired(t *testing.T) {
+	p := &AtlassianProvider{}
+	ctx := context.Background()
+	approvedAt := time.Now().Add(-2 * time.Hour)
+
+	// TTL is counted from approval
+	request := &models.AccessRequest{Details: models.AccessRequestDetails{TTL: "1h"}}
+	request.Status.ApprovedAt = &approvedAt
+	expired, err := p.IsAccessExpired(ctx, request)
+	require.NoError(t, err)
+	assert.True(t, expired)
+
+	request.Details.TTL = "3h"
+	expired, err = p.IsAccessExpired(ctx, request)
+	require.NoError(t, err)
+	assert.False(t, expired)
+
+	// Standing requests never expire
+	request.Details.TTL = ""
+	expired, err = p.IsAccessExpired(ctx, request)
+	require.NoError(t, err)
+	assert.False(t, expired)
+
+	request.Details.TTL = "forever"
+	_, err = p.IsAccessExpired(ctx, request)
+	assert.Error(t, err)
+}
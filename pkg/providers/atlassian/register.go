@@ -0,0 +1,31 @@
+package atlassian
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:             string(kinds.ProviderKindAtlassian),
+		RequiresUsername: true,
+		Description:      "Manages Atlassian Jira group membership",
+		Parameters: []registry.Parameter{
+			{Name: "group", Description: "Group name", Required: true, Example: "jira-users"},
+			{Name: "siteurl", Description: "Atlassian site URL", Required: true, Example: "https://example.atlassian.net"},
+		},
+		Credentials: []registry.Parameter{
+			{Name: "token", Description: "API token", Required: true},
+			{Name: "email", Description: "Email of the API token owner", Required: true, Example: "admin@example.com"},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewAtlassianProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
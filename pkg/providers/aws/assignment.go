@@ -0,0 +1,318 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/tracing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/identitystore"
+	"github.com/aws/aws-sdk-go-v2/service/ssoadmin"
+	ssotypes "github.com/aws/aws-sdk-go-v2/service/ssoadmin/types"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const defaultProvisioningTimeout = 2 * time.Minute
+
+// Interval between provisioning status checks
+var provisioningPollInterval = 2 * time.Second
+
+var (
+	accountIDPattern        = regexp.MustCompile(`^\d{12}$`)
+	permissionSetARNPattern = regexp.MustCompile(`^arn:aws[a-z-]*:sso:::permissionSet/ssoins-[0-9a-f]+/ps-[0-9a-f]+$`)
+)
+
+// extractAssignmentParameters validates account assignment parameters
+func extractAssignmentParameters(data map[string]string, parameters AWSProviderParameters) (AWSProviderParameters, error) {
+	if !accountIDPattern.MatchString(parameters.AccountID) {
+		return AWSProviderParameters{}, fmt.Errorf("invalid accountId %q, expected 12 digits", parameters.AccountID)
+	}
+	if !permissionSetARNPattern.MatchString(parameters.PermissionSetARN) {
+		return AWSProviderParameters{}, fmt.Errorf("invalid permissionSetArn %q", parameters.PermissionSetARN)
+	}
+	if parameters.InstanceARN == "" {
+		return AWSProviderParameters{}, errors.New("instancearn not found in credentials")
+	}
+
+	parameters.ProvisioningTimeout = defaultProvisioningTimeout
+	if value := data["provisioningTimeout"]; value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return AWSProviderParameters{}, fmt.Errorf("invalid provisioningTimeout: %w", err)
+		}
+		parameters.ProvisioningTimeout = timeout
+	}
+
+	return parameters, nil
+}
+
+func (p AWSProviderParameters) isAccountAssignment() bool {
+	return p.AccountID != ""
+}
+
+// assignment returns account and permission set id for provider status
+func (p AWSProviderParameters) assignment() string {
+	return p.AccountID + "/" + path.Base(p.PermissionSetARN)
+}
+
+// createAccountAssignment assigns the permission set to the user in the account and waits for provisioning
+func (a *AWSProvider) createAccountAssignment(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := tracing.NewSpanWrapper(ctx, "providers.aws.createAccountAssignment")
+	defer span.End()
+
+	parameters := a.Parameters
+	username := a.assignmentUsername(request)
+
+	user, err := a.getUser(ctx, username)
+	if err != nil {
+		request.SetProviderStatusError(a.Name, parameters.assignment(), err.Error())
+		return err
+	}
+
+	assigned, err := a.hasAccountAssignment(ctx, *user.UserId)
+	if err != nil {
+		request.SetProviderStatusError(a.Name, parameters.assignment(), err.Error())
+		return err
+	}
+	if assigned {
+		request.SetProviderStatusGranted(a.Name, parameters.assignment(), "already granted")
+		log.Info().
+			Str("TraceID", span.GetTraceID()).
+			Str("Provider", a.Name).
+			Str("AccessRequest", request.Id).
+			Str("Username", username).
+			Str("Assignment", parameters.assignment()).
+			Msg("Account assignment already exists")
+		return nil
+	}
+
+	output, err := a.SSOAdminClient.CreateAccountAssignment(ctx, &ssoadmin.CreateAccountAssignmentInput{
+		InstanceArn:      aws.String(parameters.InstanceARN),
+		PermissionSetArn: aws.String(parameters.PermissionSetARN),
+		PrincipalId:      user.UserId,
+		PrincipalType:    ssotypes.PrincipalTypeUser,
+		TargetId:         aws.String(parameters.AccountID),
+		TargetType:       ssotypes.TargetTypeAwsAccount,
+	})
+	if err != nil {
+		request.SetProviderStatusError(a.Name, parameters.assignment(), err.Error())
+		return fmt.Errorf("failed to create account assignment: %w", err)
+	}
+
+	status, err := a.waitForProvisioning(ctx, output.AccountAssignmentCreationStatus, func(ctx context.Context, requestID *string) (*ssotypes.AccountAssignmentOperationStatus, error) {
+		output, err := a.SSOAdminClient.DescribeAccountAssignmentCreationStatus(ctx, &ssoadmin.DescribeAccountAssignmentCreationStatusInput{
+			InstanceArn:                        aws.String(parameters.InstanceARN),
+			AccountAssignmentCreationRequestId: requestID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return output.AccountAssignmentCreationStatus, nil
+	})
+	if err != nil {
+		request.SetProviderStatusError(a.Name, parameters.assignment(), err.Error())
+		return fmt.Errorf("account assignment creation: %w", err)
+	}
+
+	request.SetProviderStatusGranted(a.Name, parameters.assignment(), "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", a.Name).
+		Str("AccessRequest", request.Id).
+		Str("Username", username).
+		Str("Assignment", parameters.assignment()).
+		Str("RequestID", aws.ToString(status.RequestId)).
+		Msg("Account assignment created")
+	return nil
+}
+
+// deleteAccountAssignment removes the permission set assignment and waits for deprovisioning
+func (a *AWSProvider) deleteAccountAssignment(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := tracing.NewSpanWrapper(ctx, "providers.aws.deleteAccountAssignment")
+	defer span.End()
+
+	parameters := a.Parameters
+	username := a.assignmentUsername(request)
+
+	user, err := a.getUser(ctx, username)
+	if err != nil {
+		if strings.Contains(err.Error(), "user not found") {
+			request.SetProviderStatusRevoked(a.Name, parameters.assignment(), "user not found")
+			return nil
+		}
+		request.SetProviderStatusError(a.Name, parameters.assignment(), err.Error())
+		return err
+	}
+
+	assigned, err := a.hasAccountAssignment(ctx, *user.UserId)
+	if err != nil {
+		request.SetProviderStatusError(a.Name, parameters.assignment(), err.Error())
+		return err
+	}
+	if !assigned {
+		request.SetProviderStatusRevoked(a.Name, parameters.assignment(), "already revoked")
+		log.Info().
+			Str("TraceID", span.GetTraceID()).
+			Str("Provider", a.Name).
+			Str("AccessRequest", request.Id).
+			Str("Username", username).
+			Str("Assignment", parameters.assignment()).
+			Msg("Account assignment already deleted")
+		return nil
+	}
+
+	output, err := a.SSOAdminClient.DeleteAccountAssignment(ctx, &ssoadmin.DeleteAccountAssignmentInput{
+		InstanceArn:      aws.String(parameters.InstanceARN),
+		PermissionSetArn: aws.String(parameters.PermissionSetARN),
+		PrincipalId:      user.UserId,
+		PrincipalType:    ssotypes.PrincipalTypeUser,
+		TargetId:         aws.String(parameters.AccountID),
+		TargetType:       ssotypes.TargetTypeAwsAccount,
+	})
+	if err != nil {
+		request.SetProviderStatusError(a.Name, parameters.assignment(), err.Error())
+		return fmt.Errorf("failed to delete account assignment: %w", err)
+	}
+
+	status, err := a.waitForProvisioning(ctx, output.AccountAssignmentDeletionStatus, func(ctx context.Context, requestID *string) (*ssotypes.AccountAssignmentOperationStatus, error) {
+		output, err := a.SSOAdminClient.DescribeAccountAssignmentDeletionStatus(ctx, &ssoadmin.DescribeAccountAssignmentDeletionStatusInput{
+			InstanceArn:                        aws.String(parameters.InstanceARN),
+			AccountAssignmentDeletionRequestId: requestID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return output.AccountAssignmentDeletionStatus, nil
+	})
+	if err != nil {
+		request.SetProviderStatusError(a.Name, parameters.assignment(), err.Error())
+		return fmt.Errorf("account assignment deletion: %w", err)
+	}
+
+	request.SetProviderStatusRevoked(a.Name, parameters.assignment(), "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", a.Name).
+		Str("AccessRequest", request.Id).
+		Str("Username", username).
+		Str("Assignment", parameters.assignment()).
+		Str("RequestID", aws.ToString(status.RequestId)).
+		Msg("Account assignment deleted")
+	return nil
+}
+
+// waitForProvisioning polls operation status until it leaves IN_PROGRESS or ProvisioningTimeout passes
+func (a *AWSProvider) waitForProvisioning(ctx context.Context, status *ssotypes.AccountAssignmentOperationStatus, describe func(context.Context, *string) (*ssotypes.AccountAssignmentOperationStatus, error)) (*ssotypes.AccountAssignmentOperationStatus, error) {
+	ctx, span := tracing.NewSpanWrapper(ctx, "aws.waitForProvisioning")
+	defer span.End()
+
+	if status == nil {
+		return nil, errors.New("no provisioning status returned")
+	}
+	span.SetAttributes(attribute.String("aws.sso.request_id", aws.ToString(status.RequestId)))
+
+	deadline := time.Now().Add(a.Parameters.ProvisioningTimeout)
+	for status.Status == ssotypes.StatusValuesInProgress {
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("provisioning still in progress after %s, request id %s", a.Parameters.ProvisioningTimeout, aws.ToString(status.RequestId))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(provisioningPollInterval):
+		}
+
+		next, err := describe(ctx, status.RequestId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe provisioning status: %w", err)
+		}
+		status = next
+	}
+
+	if status.Status == ssotypes.StatusValuesFailed {
+		return nil, fmt.Errorf("provisioning failed: %s", aws.ToString(status.FailureReason))
+	}
+	return status, nil
+}
+
+// hasAccountAssignment checks whether the user is directly assigned the permission set in the account
+func (a *AWSProvider) hasAccountAssignment(ctx context.Context, userID string) (bool, error) {
+	assignments, err := a.listAccountAssignments(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return slices.ContainsFunc(assignments, func(assignment ssotypes.AccountAssignment) bool {
+		return assignment.PrincipalType == ssotypes.PrincipalTypeUser && aws.ToString(assignment.PrincipalId) == userID
+	}), nil
+}
+
+// listAccountAssignments lists all principals assigned the permission set in the account
+func (a *AWSProvider) listAccountAssignments(ctx context.Context) ([]ssotypes.AccountAssignment, error) {
+	ctx, span := tracing.NewSpanWrapper(ctx, "aws.listAccountAssignments")
+	span.SetAttributes(
+		attribute.String("peer.service", "aws"),
+		attribute.String("span.kind", "client"),
+	)
+	defer span.End()
+
+	var assignments []ssotypes.AccountAssignment
+	paginator := ssoadmin.NewListAccountAssignmentsPaginator(a.SSOAdminClient, &ssoadmin.ListAccountAssignmentsInput{
+		InstanceArn:      aws.String(a.Parameters.InstanceARN),
+		AccountId:        aws.String(a.Parameters.AccountID),
+		PermissionSetArn: aws.String(a.Parameters.PermissionSetARN),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list account assignments: %w", err)
+		}
+		assignments = append(assignments, page.AccountAssignments...)
+	}
+	return assignments, nil
+}
+
+// listAccountAssignmentUsers lists usernames of users directly assigned the permission set. Group assignments are skipped
+func (a *AWSProvider) listAccountAssignmentUsers(ctx context.Context) ([]string, error) {
+	assignments, err := a.listAccountAssignments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	usernames := []string{}
+	for _, assignment := range assignments {
+		if assignment.PrincipalType != ssotypes.PrincipalTypeUser {
+			continue
+		}
+
+		user, err := a.IdentityClient.DescribeUser(ctx, &identitystore.DescribeUserInput{
+			IdentityStoreId: aws.String(a.Parameters.IdentityStoreID),
+			UserId:          assignment.PrincipalId,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe user %s: %w", aws.ToString(assignment.PrincipalId), err)
+		}
+		usernames = append(usernames, aws.ToString(user.UserName))
+	}
+	slices.Sort(usernames)
+
+	return usernames, nil
+}
+
+// assignmentUsername returns requester username for the provider, falling back to the username parameter
+func (a *AWSProvider) assignmentUsername(request *models.AccessRequest) string {
+	if username := request.GetProviderUsername(providerType); username != "" {
+		return username
+	}
+	return a.Parameters.Username
+}
@@ -0,0 +1,218 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/identitystore"
+	"github.com/aws/aws-sdk-go-v2/service/ssoadmin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testInstanceARN      = "arn:aws:sso:::instance/ssoins-1234567890abcdef"
+	testPermissionSetARN = "arn:aws:sso:::permissionSet/ssoins-1234567890abcdef/ps-abcdef1234567890"
+	testAccountID        = "123456789012"
+)
+
+// ssoAPI is a minimal Identity Store and SSO admin JSON API. Operations stay IN_PROGRESS for pending describe calls
+type ssoAPI struct {
+	mu          sync.Mutex
+	users       map[string]string
+	assignments []string
+	pending     int
+	failure     string
+	operations  map[string]map[string]any
+	calls       []string
+}
+
+func newSSOAPI(usernames ...string) *ssoAPI {
+	api := &ssoAPI{users: map[string]string{}, operations: map[string]map[string]any{}}
+	for i, username := range usernames {
+		api.users[fmt.Sprintf("u%d", i+1)] = username
+	}
+	return api
+}
+
+func (s *ssoAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, operation, _ := strings.Cut(r.Header.Get("X-Amz-Target"), ".")
+	s.calls = append(s.calls, operation)
+
+	var in map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&in)
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	switch operation {
+	case "ListUsers":
+		users := []map[string]string{}
+		for id, username := range s.users {
+			users = append(users, map[string]string{"UserId": id, "UserName": username, "IdentityStoreId": "d-1"})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"Users": users})
+
+	case "DescribeUser":
+		_ = json.NewEncoder(w).Encode(map[string]any{"UserId": in["UserId"], "UserName": s.users[in["UserId"].(string)], "IdentityStoreId": "d-1"})
+
+	case "ListAccountAssignments":
+		if in["AccountId"] != testAccountID || in["PermissionSetArn"] != testPermissionSetARN || in["InstanceArn"] != testInstanceARN {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"__type": "ValidationException", "message": "invalid input"})
+			return
+		}
+		assignments := []map[string]any{{"AccountId": testAccountID, "PermissionSetArn": testPermissionSetARN, "PrincipalId": "g1", "PrincipalType": "GROUP"}}
+		for _, id := range s.assignments {
+			assignments = append(assignments, map[string]any{"AccountId": testAccountID, "PermissionSetArn": testPermissionSetARN, "PrincipalId": id, "PrincipalType": "USER"})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"AccountAssignments": assignments})
+
+	case "CreateAccountAssignment", "DeleteAccountAssignment":
+		id := fmt.Sprintf("op-%d", len(s.operations)+1)
+		s.operations[id] = map[string]any{"principal": in["PrincipalId"], "create": operation == "CreateAccountAssignment", "pending": s.pending}
+		key := "AccountAssignmentCreationStatus"
+		if operation == "DeleteAccountAssignment" {
+			key = "AccountAssignmentDeletionStatus"
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{key: s.status(id)})
+
+	case "DescribeAccountAssignmentCreationStatus":
+		_ = json.NewEncoder(w).Encode(map[string]any{"AccountAssignmentCreationStatus": s.status(in["AccountAssignmentCreationRequestId"].(string))})
+
+	case "DescribeAccountAssignmentDeletionStatus":
+		_ = json.NewEncoder(w).Encode(map[string]any{"AccountAssignmentDeletionStatus": s.status(in["AccountAssignmentDeletionRequestId"].(string))})
+
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"__type": "UnknownOperationException"})
+	}
+}
+
+// status completes operation once pending describe calls are used up
+func (s *ssoAPI) status(id string) map[string]any {
+	op := s.operations[id]
+	if pending := op["pending"].(int); pending > 0 {
+		op["pending"] = pending - 1
+		return map[string]any{"RequestId": id, "Status": "IN_PROGRESS"}
+	}
+	if s.failure != "" {
+		return map[string]any{"RequestId": id, "Status": "FAILED", "FailureReason": s.failure}
+	}
+
+	principal := op["principal"].(string)
+	if op["create"].(bool) {
+		if !slices.Contains(s.assignments, principal) {
+			s.assignments = append(s.assignments, principal)
+		}
+	} else {
+		s.assignments = slices.DeleteFunc(s.assignments, func(id string) bool { return id == principal })
+	}
+	return map[string]any{"RequestId": id, "Status": "SUCCEEDED"}
+}
+
+func testAssignmentProvider(t *testing.T, api *ssoAPI, parameters map[string]string) *AWSProvider {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	provisioningPollInterval = time.Millisecond
+	Config.Creds = map[string]models.Credential{
+		"aws": {Name: "aws", Data: map[string]string{"identitystoreid": "d-1", "instancearn": testInstanceARN, "region": "eu-west-1"}},
+	}
+
+	params, err := extractParameters(models.ProviderConfig{CredentialRef: models.CredentialRef{Name: "aws"}, Parameters: parameters})
+	require.NoError(t, err)
+
+	creds := credentials.NewStaticCredentialsProvider("key", "secret", "")
+	return &AWSProvider{
+		SSOAdminClient: ssoadmin.New(ssoadmin.Options{Region: "eu-west-1", BaseEndpoint: aws.String(server.URL), Credentials: creds}),
+		IdentityClient: identitystore.New(identitystore.Options{Region: "eu-west-1", BaseEndpoint: aws.String(server.URL), Credentials: creds}),
+		Parameters:     params,
+		Name:           "AwsProdAdmin",
+	}
+}
+
+func testAssignmentRequest(username string) *models.AccessRequest {
+	request := &models.AccessRequest{Id: "req-1"}
+	request.Details.TTL = "1h"
+	request.Status.ProviderUsernames = map[string]string{providerType: username}
+	return request
+}
+
+func TestAccountAssignment(t *testing.T) {
+	ctx := context.Background()
+	api := newSSOAPI("alice", "bob")
+	api.pending = 2
+	p := testAssignmentProvider(t, api, map[string]string{"accountId": testAccountID, "permissionSetArn": testPermissionSetARN})
+
+	request := testAssignmentRequest("bob")
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatus{Action: models.ProviderStatusGranted, Details: "123456789012/ps-abcdef1234567890"}, request.Status.ProviderStatuses["AwsProdAdmin"])
+	assert.Equal(t, []string{"u2"}, api.assignments)
+	assert.Equal(t, 2, strings.Count(strings.Join(api.calls, ","), "DescribeAccountAssignmentCreationStatus"))
+
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, "already granted", request.Status.ProviderStatuses["AwsProdAdmin"].Error)
+
+	users, err := p.ListUsersWithAccess(ctx, models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob"}, users)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusRevoked, request.Status.ProviderStatuses["AwsProdAdmin"].Action)
+	assert.Empty(t, api.assignments)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, "already revoked", request.Status.ProviderStatuses["AwsProdAdmin"].Error)
+
+	require.NoError(t, p.RevokeAccess(ctx, testAssignmentRequest("mallory")))
+}
+
+func TestAccountAssignmentFailure(t *testing.T) {
+	ctx := context.Background()
+	api := newSSOAPI("alice")
+	api.failure = "Received a 404 status error: Not supported account"
+	p := testAssignmentProvider(t, api, map[string]string{"accountId": testAccountID, "permissionSetArn": testPermissionSetARN})
+
+	request := testAssignmentRequest("alice")
+	assert.Error(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusError, request.Status.ProviderStatuses["AwsProdAdmin"].Action)
+	assert.Equal(t, "provisioning failed: "+api.failure, request.Status.ProviderStatuses["AwsProdAdmin"].Error)
+
+	api.failure = ""
+	api.pending = 1000
+	p.Parameters.ProvisioningTimeout = 10 * time.Millisecond
+	assert.Error(t, p.GrantAccess(ctx, request))
+	assert.Contains(t, request.Status.ProviderStatuses["AwsProdAdmin"].Error, "provisioning still in progress")
+}
+
+func TestAccountAssignmentConfigErrors(t *testing.T) {
+	Config.Creds = map[string]models.Credential{
+		"aws":        {Name: "aws", Data: map[string]string{"instancearn": testInstanceARN}},
+		"noinstance": {Name: "noinstance", Data: map[string]string{}},
+	}
+
+	for _, config := range []models.ProviderConfig{
+		{CredentialRef: models.CredentialRef{Name: "aws"}, Parameters: map[string]string{"accountId": testAccountID}},
+		{CredentialRef: models.CredentialRef{Name: "aws"}, Parameters: map[string]string{"permissionSetArn": testPermissionSetARN}},
+		{CredentialRef: models.CredentialRef{Name: "aws"}, Parameters: map[string]string{"accountId": "1234", "permissionSetArn": testPermissionSetARN}},
+		{CredentialRef: models.CredentialRef{Name: "aws"}, Parameters: map[string]string{"accountId": testAccountID, "permissionSetArn": testPermissionSetARN, "provisioningTimeout": "soon"}},
+		{CredentialRef: models.CredentialRef{Name: "noinstance"}, Parameters: map[string]string{"accountId": testAccountID, "permissionSetArn": testPermissionSetARN}},
+	} {
+		_, err := extractParameters(config)
+		assert.Error(t, err, fmt.Sprint(config.CredentialRef.Name, config.Parameters))
+	}
+}
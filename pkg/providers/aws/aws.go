@@ -2,13 +2,13 @@ package aws
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/CTO2BPublic/passage-server/pkg/config"
 	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
 	"github.com/CTO2BPublic/passage-server/pkg/tracing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -22,10 +22,12 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 )
 
+const providerType = string(kinds.ProviderKindAWS)
+
 var Config = config.GetConfig()
 var Tracer = otel.Tracer("pkg/providers/aws")
 
-// AWSProvider handles AWS Identity Center (SSO) group management
+// AWSProvider handles AWS Identity Center (SSO) group management and account assignments
 type AWSProvider struct {
 	SSOAdminClient *ssoadmin.Client
 	IdentityClient *identitystore.Client
@@ -40,6 +42,10 @@ type AWSProviderParameters struct {
 	Region          string `json:"region"`
 	Group           string `json:"group"`
 	Username        string `json:"username"`
+	// AccountID and PermissionSetARN switch the provider from group membership to account assignment
+	AccountID           string        `json:"accountid"`
+	PermissionSetARN    string        `json:"permissionsetarn"`
+	ProvisioningTimeout time.Duration `json:"provisioningtimeout"`
 }
 
 // NewAWSProvider initializes an AWSProvider with the given configuration
@@ -73,12 +79,15 @@ func NewAWSProvider(ctx context.Context, config models.ProviderConfig) (*AWSProv
 	}, nil
 }
 
-// GrantAccess adds a user to an Identity Center group
+// GrantAccess adds a user to an Identity Center group, or assigns the permission set in the account
 func (a *AWSProvider) GrantAccess(ctx context.Context, request *models.AccessRequest) error {
 	ctx, span := tracing.NewSpanWrapper(ctx, "providers.aws.GrantAccess")
 	defer span.End()
 
 	parameters := a.Parameters
+	if parameters.isAccountAssignment() {
+		return a.createAccountAssignment(ctx, request)
+	}
 
 	// Get group ID
 	group, err := a.getGroup(ctx, parameters.Group)
@@ -122,12 +131,15 @@ func (a *AWSProvider) GrantAccess(ctx context.Context, request *models.AccessReq
 	return nil
 }
 
-// RevokeAccess removes a user from an Identity Center group
+// RevokeAccess removes a user from an Identity Center group, or deletes the account assignment
 func (a *AWSProvider) RevokeAccess(ctx context.Context, request *models.AccessRequest) error {
 	ctx, span := tracing.NewSpanWrapper(ctx, "providers.aws.RevokeAccess")
 	defer span.End()
 
 	parameters := a.Parameters
+	if parameters.isAccountAssignment() {
+		return a.deleteAccountAssignment(ctx, request)
+	}
 
 	// Get group ID
 	group, err := a.getGroup(ctx, parameters.Group)
@@ -185,26 +197,18 @@ func (a *AWSProvider) RevokeAccess(ctx context.Context, request *models.AccessRe
 
 // IsAccessExpired checks whether the access for the given request has expired
 func (a *AWSProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
-	ttl := request.Details.TTL
-	if ttl == "" {
-		return false, errors.New("TTL not specified in access request")
-	}
-
-	// Validate TTL expiration (this assumes TTL is a duration like "24h")
-	expiry, err := time.ParseDuration(ttl)
-	if err != nil {
-		return false, fmt.Errorf("invalid TTL format: %w", err)
-	}
-
-	expirationTime := request.CreatedAt.Add(expiry)
-	return time.Now().After(expirationTime), nil
+	return request.IsExpired(time.Now())
 }
 
 func (a *AWSProvider) ListUsersWithAccess(ctx context.Context, roleRef models.AccessRoleRef) ([]string, error) {
 	// Create a tracing span
-	_, span := tracing.NewSpanWrapper(ctx, "providers.aws.ListUsersWithAccess")
+	ctx, span := tracing.NewSpanWrapper(ctx, "providers.aws.ListUsersWithAccess")
 	defer span.End()
 
+	if a.Parameters.isAccountAssignment() {
+		return a.listAccountAssignmentUsers(ctx)
+	}
+
 	// Input for AWS Identity Store ListGroupMemberships API call
 	input := &identitystore.ListGroupMembershipsInput{
 		IdentityStoreId: aws.String(a.Parameters.IdentityStoreID),
@@ -268,16 +272,19 @@ func (a *AWSProvider) getUser(ctx context.Context, username string) (*types.User
 	)
 	defer span.End()
 
-	output, err := a.IdentityClient.ListUsers(ctx, &identitystore.ListUsersInput{
+	paginator := identitystore.NewListUsersPaginator(a.IdentityClient, &identitystore.ListUsersInput{
 		IdentityStoreId: aws.String(a.Parameters.IdentityStoreID),
 	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list users: %w", err)
-	}
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list users: %w", err)
+		}
 
-	for _, user := range output.Users {
-		if user.UserName != nil && *user.UserName == username {
-			return &user, nil
+		for _, user := range output.Users {
+			if user.UserName != nil && *user.UserName == username {
+				return &user, nil
+			}
 		}
 	}
 	return nil, fmt.Errorf("user not found: %s", username)
@@ -327,6 +334,18 @@ func extractParameters(config models.ProviderConfig) (AWSProviderParameters, err
 	instanceARN := creds.GetString("instancearn")
 	region := creds.GetString("region")
 
+	accountID, permissionSetARN := data["accountId"], data["permissionSetArn"]
+	if accountID != "" || permissionSetARN != "" {
+		return extractAssignmentParameters(data, AWSProviderParameters{
+			IdentityStoreID:  identityStoreID,
+			InstanceARN:      instanceARN,
+			Region:           region,
+			Username:         data["username"],
+			AccountID:        accountID,
+			PermissionSetARN: permissionSetARN,
+		})
+	}
+
 	group, ok := data["group"]
 	if !ok {
 		return AWSProviderParameters{}, fmt.Errorf("groupName not found in provider config")
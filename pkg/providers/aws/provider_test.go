@@ -3,9 +3,13 @@ package aws_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/CTO2BPublic/passage-server/pkg/models"
 	"github.com/CTO2BPublic/passage-server/pkg/providers/aws"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGoogleProvider(t *testing.T) {
@@ -20,3 +24,31 @@ func TestGoogleProvider(t *testing.T) {
 		t.Errorf("Failed to add user: %v", err)
 	}
 }
+
+func TestIsAccessExpired(t *testing.T) {
+	p := &aws.AWSProvider{}
+	ctx := context.Background()
+	approvedAt := time.Now().Add(-2 * time.Hour)
+
+	// TTL is counted from approval
+	request := &models.AccessRequest{Details: models.AccessRequestDetails{TTL: "1h"}}
+	request.Status.ApprovedAt = &approvedAt
+	expired, err := p.IsAccessExpired(ctx, request)
+	require.NoError(t, err)
+	assert.True(t, expired)
+
+	request.Details.TTL = "3h"
+	expired, err = p.IsAccessExpired(ctx, request)
+	require.NoError(t, err)
+	assert.False(t, expired)
+
+	// Standing requests never expire
+	request.Details.TTL = ""
+	expired, err = p.IsAccessExpired(ctx, request)
+	require.NoError(t, err)
+	assert.False(t, expired)
+
+	request.Details.TTL = "forever"
+	_, err = p.IsAccessExpired(ctx, request)
+	assert.Error(t, err)
+}
@@ -0,0 +1,36 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:             string(kinds.ProviderKindAWS),
+		RequiresUsername: true,
+		Description:      "Manages AWS IAM Identity Center group membership, or permission set assignments in an account when accountId is set",
+		Parameters: []registry.Parameter{
+			{Name: "group", Description: "Identity store group display name. Required without accountId", Example: "Billing"},
+			{Name: "accountId", Description: "AWS account id the permission set is assigned in", Example: "123456789012"},
+			{Name: "permissionSetArn", Description: "Permission set ARN. Required with accountId", Example: "arn:aws:sso:::permissionSet/ssoins-1234567890abcdef/ps-1234567890abcdef"},
+			{Name: "provisioningTimeout", Description: "How long to wait for assignment provisioning. Defaults to 2m", Example: "5m"},
+		},
+		Credentials: []registry.Parameter{
+			{Name: "accesskeyid", Description: "AWS access key id", Required: true},
+			{Name: "secretaccesskey", Description: "AWS secret access key", Required: true},
+			{Name: "identitystoreid", Description: "Identity store id", Required: true, Example: "d-1234567890"},
+			{Name: "instancearn", Description: "IAM Identity Center instance ARN. Required for account assignments", Example: "arn:aws:sso:::instance/ssoins-1234567890"},
+			{Name: "region", Description: "AWS region", Required: true, Example: "eu-west-1"},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewAWSProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
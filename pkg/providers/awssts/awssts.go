@@ -0,0 +1,286 @@
+package awssts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/config"
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/tracing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	providerType = string(kinds.ProviderKindAWSSTS)
+
+	// Session tag carrying access request id
+	requestTagKey = "PassageRequestId"
+
+	// STS limits for AssumeRole sessions
+	minSessionDuration = 15 * time.Minute
+	maxSessionName     = 64
+	maxPolicySize      = 2048
+)
+
+var Config = config.GetConfig()
+
+var (
+	roleARNPattern     = regexp.MustCompile(`^arn:aws[a-z-]*:iam::\d{12}:role/[\w+=,.@/-]+$`)
+	sessionNameInvalid = regexp.MustCompile(`[^\w+=,.@-]`)
+)
+
+// AWSSTSProvider vends short-lived credentials of an IAM role. Credentials are delivered to the requester once and not kept
+type AWSSTSProvider struct {
+	client *sts.Client
+
+	// provider name as defined in the provider configuration
+	name    string
+	region  string
+	roleARN string
+	// external id required by the role trust policy
+	externalID string
+	// inline session policy further restricting role permissions
+	sessionPolicy string
+	// managed policies used as session policies
+	sessionPolicyARNs []string
+	// upper bound of session duration. Must not exceed role MaxSessionDuration
+	maxSessionDuration time.Duration
+}
+
+// NewAWSSTSProvider initializes a new AWSSTSProvider with access keys from CredentialRef, or the default credential chain
+func NewAWSSTSProvider(ctx context.Context, config models.ProviderConfig) (*AWSSTSProvider, error) {
+	creds := Config.GetCredentials(config.CredentialRef.Name)
+	data := config.Parameters
+
+	p := &AWSSTSProvider{
+		name:               config.Name,
+		region:             creds.GetString("region"),
+		roleARN:            data["roleArn"],
+		externalID:         data["externalId"],
+		sessionPolicy:      strings.TrimSpace(data["sessionPolicy"]),
+		maxSessionDuration: time.Hour,
+	}
+
+	if p.region == "" {
+		return nil, errors.New("region not found in credentials")
+	}
+	if !roleARNPattern.MatchString(p.roleARN) {
+		return nil, fmt.Errorf("invalid roleArn %q", p.roleARN)
+	}
+
+	for _, arn := range strings.Split(data["sessionPolicyArns"], ",") {
+		if arn = strings.TrimSpace(arn); arn != "" {
+			p.sessionPolicyARNs = append(p.sessionPolicyARNs, arn)
+		}
+	}
+	if p.sessionPolicy == "" && len(p.sessionPolicyARNs) == 0 {
+		return nil, errors.New("sessionPolicy or sessionPolicyArns is required")
+	}
+	if p.sessionPolicy != "" {
+		if !json.Valid([]byte(p.sessionPolicy)) {
+			return nil, errors.New("sessionPolicy is not valid JSON")
+		}
+		if len(p.sessionPolicy) > maxPolicySize {
+			return nil, fmt.Errorf("sessionPolicy exceeds %d characters", maxPolicySize)
+		}
+	}
+
+	if value := data["maxSessionDuration"]; value != "" {
+		var err error
+		p.maxSessionDuration, err = time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maxSessionDuration: %w", err)
+		}
+		if p.maxSessionDuration < minSessionDuration || p.maxSessionDuration > 12*time.Hour {
+			return nil, errors.New("maxSessionDuration must be between 15m and 12h")
+		}
+	}
+
+	options := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(p.region)}
+	if accessKeyID := creds.GetString("accesskeyid"); accessKeyID != "" {
+		options = append(options, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(accessKeyID, creds.GetString("secretaccesskey"), ""),
+		))
+	}
+
+	awsConfig, err := awsconfig.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS SDK configuration: %w", err)
+	}
+	p.client = sts.NewFromConfig(awsConfig)
+
+	return p, nil
+}
+
+// GrantAccess assumes the role with the session policy and attaches credentials to the request for one-time retrieval
+func (p *AWSSTSProvider) GrantAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "GrantAccess")
+	defer span.End()
+
+	username := p.username(request)
+
+	duration, err := p.sessionDuration(request, time.Now())
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.roleARN, err.Error())
+		return err
+	}
+
+	input := &sts.AssumeRoleInput{
+		RoleArn:         aws.String(p.roleARN),
+		RoleSessionName: aws.String(sessionName(username, request.Id)),
+		DurationSeconds: aws.Int32(int32(duration.Seconds())),
+		Tags: []types.Tag{
+			{Key: aws.String(requestTagKey), Value: aws.String(request.Id)},
+		},
+	}
+	if p.externalID != "" {
+		input.ExternalId = aws.String(p.externalID)
+	}
+	if p.sessionPolicy != "" {
+		input.Policy = aws.String(p.sessionPolicy)
+	}
+	for _, arn := range p.sessionPolicyARNs {
+		input.PolicyArns = append(input.PolicyArns, types.PolicyDescriptorType{Arn: aws.String(arn)})
+	}
+
+	output, err := p.assumeRole(ctx, input)
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.roleARN, err.Error())
+		return fmt.Errorf("failed to assume role: %w", err)
+	}
+
+	creds := output.Credentials
+	if creds == nil || creds.AccessKeyId == nil || creds.Expiration == nil {
+		err := errors.New("assume role returned no credentials")
+		request.SetProviderStatusError(p.name, p.roleARN, err.Error())
+		return err
+	}
+
+	assumedRole := ""
+	if output.AssumedRoleUser != nil {
+		assumedRole = aws.ToString(output.AssumedRoleUser.Arn)
+	}
+
+	request.AddCredential(p.name, models.AccessCredentialAWS, map[string]string{
+		"accesskeyid":     aws.ToString(creds.AccessKeyId),
+		"secretaccesskey": aws.ToString(creds.SecretAccessKey),
+		"sessiontoken":    aws.ToString(creds.SessionToken),
+		"expiration":      creds.Expiration.UTC().Format(time.RFC3339),
+		"region":          p.region,
+	}, creds.Expiration)
+
+	request.SetProviderStatusGranted(p.name, p.roleARN, "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", username).
+		Str("Role", p.roleARN).
+		Str("AssumedRole", assumedRole).
+		Time("Expiration", *creds.Expiration).
+		Msg("AWS role credentials issued")
+
+	return nil
+}
+
+// RevokeAccess records revocation. Issued credentials cannot be revoked individually and expire with the session
+func (p *AWSSTSProvider) RevokeAccess(ctx context.Context, request *models.AccessRequest) error {
+	_, span := startSpan(ctx, "RevokeAccess")
+	defer span.End()
+
+	request.SetProviderStatusRevoked(p.name, p.roleARN, "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", p.username(request)).
+		Str("Role", p.roleARN).
+		Msg("AWS role credentials expire with the session")
+
+	return nil
+}
+
+// ListUsersWithAccess returns no users. The provider keeps no record of issued sessions
+func (p *AWSSTSProvider) ListUsersWithAccess(ctx context.Context, roleRef models.AccessRoleRef) ([]string, error) {
+	_, span := startSpan(ctx, "ListUsersWithAccess")
+	defer span.End()
+
+	return []string{}, nil
+}
+
+// IsAccessExpired checks whether the access for the given request has expired
+func (p *AWSSTSProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
+	_, span := startSpan(ctx, "IsAccessExpired")
+	defer span.End()
+
+	return request.IsExpired(time.Now())
+}
+
+func (p *AWSSTSProvider) assumeRole(ctx context.Context, input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+	ctx, span := startSpan(ctx, "assumeRole")
+	span.SetAttributes(
+		attribute.String("peer.service", "aws"),
+		attribute.String("span.kind", "client"),
+	)
+	defer span.End()
+
+	return p.client.AssumeRole(ctx, input)
+}
+
+// sessionDuration returns time left until request expiry capped by maxSessionDuration. STS does not issue sessions shorter than 15m
+func (p *AWSSTSProvider) sessionDuration(request *models.AccessRequest, now time.Time) (time.Duration, error) {
+	expires, err := request.Expiration(now)
+	if err != nil {
+		return 0, err
+	}
+	if expires == nil {
+		return 0, errors.New("TTL not specified in access request")
+	}
+
+	remaining := expires.Sub(now).Truncate(time.Second)
+	if remaining < minSessionDuration {
+		return 0, fmt.Errorf("remaining TTL %s is shorter than minimum session duration %s", remaining, minSessionDuration)
+	}
+	return min(remaining, p.maxSessionDuration), nil
+}
+
+// username returns provider username of the requester, falling back to the requester
+func (p *AWSSTSProvider) username(request *models.AccessRequest) string {
+	if username := request.GetProviderUsername(providerType); username != "" {
+		return username
+	}
+	return request.Status.RequestedBy
+}
+
+// sessionName builds RoleSessionName shown in CloudTrail from username, or request id when username is empty
+func sessionName(username string, requestID string) string {
+	name := sessionNameInvalid.ReplaceAllString(username, "-")
+	if name == "" {
+		name = "passage-" + requestID
+	}
+	if len(name) < 2 {
+		name = "passage-" + name
+	}
+	if len(name) > maxSessionName {
+		name = name[:maxSessionName]
+	}
+	return name
+}
+
+func startSpan(ctx context.Context, name string) (context.Context, *tracing.SpanWrapper) {
+	ctx, span := tracing.NewSpanWrapper(ctx, fmt.Sprintf("providers.awssts.%s", name))
+	return ctx, span
+}
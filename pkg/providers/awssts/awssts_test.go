@@ -0,0 +1,197 @@
+package awssts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRoleARN = "arn:aws:iam::123456789012:role/BreakGlass"
+	testPolicy  = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"ec2:Describe*","Resource":"*"}]}`
+)
+
+// stsAPI records AssumeRole calls and returns credentials valid for the requested duration
+type stsAPI struct {
+	mu    sync.Mutex
+	calls []url.Values
+}
+
+func (s *stsAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("Action") != "AssumeRole" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.calls = append(s.calls, r.PostForm)
+
+	if r.PostForm.Get("RoleArn") != testRoleARN {
+		w.Header().Set("Content-Type", "text/xml")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `<ErrorResponse><Error><Type>Sender</Type><Code>AccessDenied</Code><Message>not authorized to perform sts:AssumeRole</Message></Error><RequestId>r1</RequestId></ErrorResponse>`)
+		return
+	}
+
+	seconds, _ := strconv.Atoi(r.PostForm.Get("DurationSeconds"))
+	expiration := time.Now().Add(time.Duration(seconds) * time.Second).UTC().Format(time.RFC3339)
+
+	w.Header().Set("Content-Type", "text/xml")
+	fmt.Fprintf(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>ASIATESTKEY</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>session-token</SessionToken>
+      <Expiration>%s</Expiration>
+    </Credentials>
+    <AssumedRoleUser>
+      <Arn>arn:aws:sts::123456789012:assumed-role/BreakGlass/%s</Arn>
+      <AssumedRoleId>AROATEST:%s</AssumedRoleId>
+    </AssumedRoleUser>
+  </AssumeRoleResult>
+  <ResponseMetadata><RequestId>r1</RequestId></ResponseMetadata>
+</AssumeRoleResponse>`, expiration, r.PostForm.Get("RoleSessionName"), r.PostForm.Get("RoleSessionName"))
+}
+
+func testProvider(t *testing.T, api *stsAPI, parameters map[string]string) *AWSSTSProvider {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	Config.Creds = map[string]models.Credential{
+		"aws": {Name: "aws", Data: map[string]string{"region": "eu-west-1", "accesskeyid": "key", "secretaccesskey": "secret"}},
+	}
+
+	p, err := NewAWSSTSProvider(context.Background(), models.ProviderConfig{
+		Name:          "BreakGlass",
+		Provider:      "awssts",
+		CredentialRef: models.CredentialRef{Name: "aws"},
+		Parameters:    parameters,
+	})
+	require.NoError(t, err)
+
+	p.client = sts.New(sts.Options{
+		Region:       "eu-west-1",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  credentials.NewStaticCredentialsProvider("key", "secret", ""),
+	})
+	return p
+}
+
+func testRequest(ttl time.Duration) *models.AccessRequest {
+	expires := time.Now().Add(ttl)
+	request := &models.AccessRequest{Id: "0b6f7c9e-5d1a-4e0b-9a55-3c1f2d7e8a90"}
+	request.Details.TTL = ttl.String()
+	request.Status.ExpiresAt = &expires
+	request.Status.RequestedBy = "alice@example.com"
+	return request
+}
+
+func TestGrantAccess(t *testing.T) {
+	api := &stsAPI{}
+	p := testProvider(t, api, map[string]string{
+		"roleArn":           testRoleARN,
+		"sessionPolicy":     testPolicy,
+		"sessionPolicyArns": "arn:aws:iam::aws:policy/ReadOnlyAccess",
+		"externalId":        "passage",
+	})
+
+	request := testRequest(30 * time.Minute)
+	require.NoError(t, p.GrantAccess(context.Background(), request))
+	assert.Equal(t, models.ProviderStatus{Action: models.ProviderStatusGranted, Details: testRoleARN}, request.Status.ProviderStatuses["BreakGlass"])
+
+	require.Len(t, api.calls, 1)
+	call := api.calls[0]
+	assert.Equal(t, "alice@example.com", call.Get("RoleSessionName"))
+	assert.Contains(t, []string{"1799", "1800"}, call.Get("DurationSeconds"))
+	assert.Equal(t, testPolicy, call.Get("Policy"))
+	assert.Equal(t, "arn:aws:iam::aws:policy/ReadOnlyAccess", call.Get("PolicyArns.member.1.arn"))
+	assert.Equal(t, "passage", call.Get("ExternalId"))
+	assert.Equal(t, requestTagKey, call.Get("Tags.member.1.Key"))
+	assert.Equal(t, request.Id, call.Get("Tags.member.1.Value"))
+
+	require.Len(t, request.Credentials, 1)
+	credential := request.Credentials[0]
+	assert.Equal(t, models.AccessCredentialAWS, credential.Kind)
+	assert.Equal(t, "ASIATESTKEY", credential.Data["accesskeyid"])
+	assert.Equal(t, "session-token", credential.Data["sessiontoken"])
+	assert.Equal(t, "eu-west-1", credential.Data["region"])
+	require.NotNil(t, credential.ExpiresAt)
+	assert.WithinDuration(t, *request.Status.ExpiresAt, *credential.ExpiresAt, 2*time.Second)
+
+	require.NoError(t, p.RevokeAccess(context.Background(), request))
+	assert.Equal(t, models.ProviderStatusRevoked, request.Status.ProviderStatuses["BreakGlass"].Action)
+
+	users, err := p.ListUsersWithAccess(context.Background(), models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
+
+func TestSessionDurationCap(t *testing.T) {
+	api := &stsAPI{}
+	p := testProvider(t, api, map[string]string{"roleArn": testRoleARN, "sessionPolicy": testPolicy, "maxSessionDuration": "2h"})
+
+	require.NoError(t, p.GrantAccess(context.Background(), testRequest(8*time.Hour)))
+	assert.Equal(t, "7200", api.calls[0].Get("DurationSeconds"))
+
+	request := testRequest(10 * time.Minute)
+	assert.Error(t, p.GrantAccess(context.Background(), request))
+	assert.Contains(t, request.Status.ProviderStatuses["BreakGlass"].Error, "shorter than minimum session duration")
+	assert.Empty(t, request.Credentials)
+	assert.Len(t, api.calls, 1)
+}
+
+func TestAccessDenied(t *testing.T) {
+	api := &stsAPI{}
+	p := testProvider(t, api, map[string]string{"roleArn": "arn:aws:iam::123456789012:role/Other", "sessionPolicy": testPolicy})
+
+	request := testRequest(time.Hour)
+	assert.Error(t, p.GrantAccess(context.Background(), request))
+	assert.Equal(t, models.ProviderStatusError, request.Status.ProviderStatuses["BreakGlass"].Action)
+	assert.Contains(t, request.Status.ProviderStatuses["BreakGlass"].Error, "AccessDenied")
+	assert.Empty(t, request.Credentials)
+}
+
+func TestSessionName(t *testing.T) {
+	assert.Equal(t, "alice@example.com", sessionName("alice@example.com", "req-1"))
+	assert.Equal(t, "Alice-Smith", sessionName("Alice Smith", "req-1"))
+	assert.Equal(t, "passage-req-1", sessionName("", "req-1"))
+	assert.Equal(t, "passage-a", sessionName("a", "req-1"))
+	assert.Len(t, sessionName(fmt.Sprintf("%070d", 0), "req-1"), maxSessionName)
+}
+
+func TestConfigErrors(t *testing.T) {
+	ctx := context.Background()
+	Config.Creds = map[string]models.Credential{
+		"aws":      {Name: "aws", Data: map[string]string{"region": "eu-west-1"}},
+		"noregion": {Name: "noregion", Data: map[string]string{}},
+	}
+
+	for _, config := range []models.ProviderConfig{
+		{CredentialRef: models.CredentialRef{Name: "noregion"}, Parameters: map[string]string{"roleArn": testRoleARN, "sessionPolicy": testPolicy}},
+		{CredentialRef: models.CredentialRef{Name: "aws"}, Parameters: map[string]string{"sessionPolicy": testPolicy}},
+		{CredentialRef: models.CredentialRef{Name: "aws"}, Parameters: map[string]string{"roleArn": "BreakGlass", "sessionPolicy": testPolicy}},
+		{CredentialRef: models.CredentialRef{Name: "aws"}, Parameters: map[string]string{"roleArn": testRoleARN}},
+		{CredentialRef: models.CredentialRef{Name: "aws"}, Parameters: map[string]string{"roleArn": testRoleARN, "sessionPolicy": "{"}},
+		{CredentialRef: models.CredentialRef{Name: "aws"}, Parameters: map[string]string{"roleArn": testRoleARN, "sessionPolicy": testPolicy, "maxSessionDuration": "5m"}},
+		{CredentialRef: models.CredentialRef{Name: "aws"}, Parameters: map[string]string{"roleArn": testRoleARN, "sessionPolicy": testPolicy, "maxSessionDuration": "soon"}},
+	} {
+		_, err := NewAWSSTSProvider(ctx, config)
+		assert.Error(t, err, fmt.Sprint(config.CredentialRef.Name, config.Parameters))
+	}
+}
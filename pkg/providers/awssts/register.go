@@ -0,0 +1,34 @@
+package awssts
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:        string(kinds.ProviderKindAWSSTS),
+		Description: "Assumes an IAM role and delivers short-lived credentials to the requester once. Session duration is capped by request TTL",
+		Parameters: []registry.Parameter{
+			{Name: "roleArn", Description: "IAM role assumed on grant", Required: true, Example: "arn:aws:iam::123456789012:role/BreakGlass"},
+			{Name: "sessionPolicy", Description: "Inline JSON session policy. Either sessionPolicy or sessionPolicyArns is required", Example: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"ec2:Describe*","Resource":"*"}]}`},
+			{Name: "sessionPolicyArns", Description: "Comma-separated managed policy ARNs used as session policies", Example: "arn:aws:iam::aws:policy/ReadOnlyAccess"},
+			{Name: "externalId", Description: "External id required by the role trust policy"},
+			{Name: "maxSessionDuration", Description: "Upper bound of session duration, must not exceed role MaxSessionDuration. Defaults to 1h", Example: "4h"},
+		},
+		Credentials: []registry.Parameter{
+			{Name: "region", Description: "AWS region of the STS endpoint", Required: true, Example: "eu-west-1"},
+			{Name: "accesskeyid", Description: "AWS access key id. Default credential chain is used when empty"},
+			{Name: "secretaccesskey", Description: "AWS secret access key"},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewAWSSTSProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
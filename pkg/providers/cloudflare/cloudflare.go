@@ -210,19 +210,7 @@ func (p *CloudflareProvider) IsAccessExpired(ctx context.Context, request *model
 	_, span := startSpan(ctx, "IsAccessExpired")
 	defer span.End()
 
-	ttl := request.Details.TTL
-	if ttl == "" {
-		return false, errors.New("TTL not specified in access request")
-	}
-
-	// Validate TTL expiration (this assumes TTL is a duration like "24h")
-	expiry, err := time.ParseDuration(ttl)
-	if err != nil {
-		return false, fmt.Errorf("invalid TTL format: %w", err)
-	}
-
-	expirationTime := request.CreatedAt.Add(expiry)
-	return time.Now().After(expirationTime), nil
+	return request.IsExpired(time.Now())
 }
 
 func startSpan(ctx context.Context, name string) (context.Context, *tracing.SpanWrapper) {
@@ -3,6 +3,7 @@ package cloudflare
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/CTO2BPublic/passage-server/pkg/config"
 	"github.com/CTO2BPublic/passage-server/pkg/models"
@@ -102,3 +103,31 @@ func TestListUsersWithAccess(t *testing.T) {
 	err = c.removeGroupMember(ctx, c.groupID, email)
 	require.NoError(t, err)
 }
+
+func TestIsAccessExpired(t *testing.T) {
+	p := &CloudflareProvider{}
+	ctx := context.Background()
+	approvedAt := time.Now().Add(-2 * time.Hour)
+
+	// TTL is counted from approval
+	request := &models.AccessRequest{Details: models.AccessRequestDetails{TTL: "1h"}}
+	request.Status.ApprovedAt = &approvedAt
+	expired, err := p.IsAccessExpired(ctx, request)
+	require.NoError(t, err)
+	assert.True(t, expired)
+
+	request.Details.TTL = "3h"
+	expired, err = p.IsAccessExpired(ctx, request)
+	require.NoError(t, err)
+	assert.False(t, expired)
+
+	// Standing requests never expire
+	request.Details.TTL = ""
+	expired, err = p.IsAccessExpired(ctx, request)
+	require.NoError(t, err)
+	assert.False(t, expired)
+
+	request.Details.TTL = "forever"
+	_, err = p.IsAccessExpired(ctx, request)
+	assert.Error(t, err)
+}
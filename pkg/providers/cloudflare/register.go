@@ -0,0 +1,30 @@
+package cloudflare
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:             string(kinds.ProviderKindCloudflare),
+		RequiresUsername: true,
+		Description:      "Manages Cloudflare account member roles",
+		Parameters: []registry.Parameter{
+			{Name: "accountID", Description: "Cloudflare account id", Required: true},
+			{Name: "group", Description: "Permission group name", Required: true, Example: "Administrator Read Only"},
+		},
+		Credentials: []registry.Parameter{
+			{Name: "credentialsfile", Description: "Path to file containing API token", Required: true, Example: "/secrets/cloudflare-token"},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewCloudflareProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
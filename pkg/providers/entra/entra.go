@@ -0,0 +1,181 @@
+package entra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/config"
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/tracing"
+
+	"github.com/rs/zerolog/log"
+)
+
+const providerType = string(kinds.ProviderKindEntra)
+
+var Config = config.GetConfig()
+
+// EntraProvider handles Microsoft Entra ID group membership through Microsoft Graph
+type EntraProvider struct {
+	Client     *http.Client
+	Parameters EntraProviderParameters
+	Name       string `json:"name"`
+}
+
+// EntraProviderParameters encapsulates extracted provider details
+type EntraProviderParameters struct {
+	Group string `json:"group"`
+	// MembershipExpiry assigns membership through PIM for Groups with an end date instead of adding a permanent member
+	MembershipExpiry bool   `json:"membershipExpiry"`
+	TenantID         string `json:"tenantId"`
+	ClientID         string `json:"clientId"`
+	ClientSecret     string `json:"-"`
+	GraphURL         string `json:"graphUrl"`
+	AuthorityURL     string `json:"authorityUrl"`
+}
+
+// NewEntraProvider initializes a new EntraProvider with client credentials from CredentialRef
+func NewEntraProvider(ctx context.Context, config models.ProviderConfig) (*EntraProvider, error) {
+	parameters, err := extractParameters(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse provider config: %w", err)
+	}
+
+	return &EntraProvider{Client: graphClient(parameters), Parameters: parameters, Name: config.Name}, nil
+}
+
+// GrantAccess adds a user to the group, or assigns time-bound membership when MembershipExpiry is enabled
+func (e *EntraProvider) GrantAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := tracing.NewSpanWrapper(ctx, "providers.entra.GrantAccess")
+	defer span.End()
+
+	parameters := e.Parameters
+	username := request.GetProviderUsername(providerType)
+
+	userID, err := e.getUserID(ctx, username)
+	if err != nil {
+		request.SetProviderStatusError(e.Name, parameters.Group, err.Error())
+		return err
+	}
+
+	var added bool
+	if parameters.MembershipExpiry {
+		end, err := request.Expiration(time.Now())
+		if err != nil {
+			request.SetProviderStatusError(e.Name, parameters.Group, err.Error())
+			return err
+		}
+		added, err = e.assignGroupMember(ctx, userID, end, request)
+	} else {
+		added, err = e.addGroupMember(ctx, userID)
+	}
+	if err != nil {
+		request.SetProviderStatusError(e.Name, parameters.Group, err.Error())
+		return fmt.Errorf("failed to add user to group: %w", err)
+	}
+
+	if !added {
+		request.SetProviderStatusGranted(e.Name, parameters.Group, "already in group")
+		log.Info().
+			Str("TraceID", span.GetTraceID()).
+			Str("Provider", e.Name).
+			Str("AccessRequest", request.Id).
+			Str("Username", username).
+			Str("Group", parameters.Group).
+			Msg("User already in group")
+		return nil
+	}
+
+	request.SetProviderStatusGranted(e.Name, parameters.Group, "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", e.Name).
+		Str("AccessRequest", request.Id).
+		Str("Username", username).
+		Str("Group", parameters.Group).
+		Msg("User added to group")
+
+	return nil
+}
+
+// RevokeAccess removes a user from the group, or removes the time-bound assignment when MembershipExpiry is enabled
+func (e *EntraProvider) RevokeAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := tracing.NewSpanWrapper(ctx, "providers.entra.RevokeAccess")
+	defer span.End()
+
+	parameters := e.Parameters
+	username := request.GetProviderUsername(providerType)
+
+	userID, err := e.getUserID(ctx, username)
+	if errors.Is(err, errUserNotFound) {
+		request.SetProviderStatusRevoked(e.Name, parameters.Group, "user not found")
+		return nil
+	}
+	if err != nil {
+		request.SetProviderStatusError(e.Name, parameters.Group, err.Error())
+		return err
+	}
+
+	var removed bool
+	if parameters.MembershipExpiry {
+		removed, err = e.unassignGroupMember(ctx, userID, request)
+	} else {
+		removed, err = e.removeGroupMember(ctx, userID)
+	}
+	if err != nil {
+		request.SetProviderStatusError(e.Name, parameters.Group, err.Error())
+		return fmt.Errorf("failed to remove user from group: %w", err)
+	}
+
+	if !removed {
+		request.SetProviderStatusRevoked(e.Name, parameters.Group, "already removed from group")
+		log.Info().
+			Str("TraceID", span.GetTraceID()).
+			Str("Provider", e.Name).
+			Str("AccessRequest", request.Id).
+			Str("Username", username).
+			Str("Group", parameters.Group).
+			Msg("User already not in group")
+		return nil
+	}
+
+	request.SetProviderStatusRevoked(e.Name, parameters.Group, "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", e.Name).
+		Str("AccessRequest", request.Id).
+		Str("Username", username).
+		Str("Group", parameters.Group).
+		Msg("User removed from group")
+
+	return nil
+}
+
+// ListUsersWithAccess lists UPNs of direct group members, or of active assignments when MembershipExpiry is enabled
+func (e *EntraProvider) ListUsersWithAccess(ctx context.Context, roleRef models.AccessRoleRef) ([]string, error) {
+	ctx, span := tracing.NewSpanWrapper(ctx, "providers.entra.ListUsersWithAccess")
+	defer span.End()
+
+	if e.Parameters.MembershipExpiry {
+		usernames, err := e.listAssignedMembers(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list group assignments: %w", err)
+		}
+		return usernames, nil
+	}
+
+	usernames, err := e.listGroupMembers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list group members: %w", err)
+	}
+	return usernames, nil
+}
+
+// IsAccessExpired checks whether the access for the given request has expired
+func (e *EntraProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
+	return request.IsExpired(time.Now())
+}
@@ -0,0 +1,406 @@
+package entra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/tracing"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const (
+	defaultGraphURL     = "https://graph.microsoft.com"
+	defaultAuthorityURL = "https://login.microsoftonline.com"
+	pimRequestsPath     = "/v1.0/identityGovernance/privilegedAccess/group/assignmentScheduleRequests"
+	pimInstancesPath    = "/v1.0/identityGovernance/privilegedAccess/group/assignmentScheduleInstances"
+)
+
+var errUserNotFound = errors.New("user not found")
+
+// Graph clients shared between provider instances so access tokens are reused, keyed by endpoints and client credentials
+var (
+	clientsMu sync.Mutex
+	clients   = map[string]*http.Client{}
+)
+
+// graphError is returned for unexpected Microsoft Graph responses
+type graphError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *graphError) Error() string {
+	if e.code == "" {
+		return fmt.Sprintf("unexpected status %d", e.status)
+	}
+	return fmt.Sprintf("%s: %s", e.code, e.message)
+}
+
+// assignmentScheduleRequest is a PIM for Groups assignment request
+type assignmentScheduleRequest struct {
+	AccessID      string        `json:"accessId"`
+	PrincipalID   string        `json:"principalId"`
+	GroupID       string        `json:"groupId"`
+	Action        string        `json:"action"`
+	Justification string        `json:"justification,omitempty"`
+	ScheduleInfo  *scheduleInfo `json:"scheduleInfo,omitempty"`
+}
+
+type scheduleInfo struct {
+	StartDateTime time.Time  `json:"startDateTime"`
+	Expiration    expiration `json:"expiration"`
+}
+
+type expiration struct {
+	Type        string     `json:"type"`
+	EndDateTime *time.Time `json:"endDateTime,omitempty"`
+}
+
+func graphClient(parameters EntraProviderParameters) *http.Client {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	key := strings.Join([]string{parameters.GraphURL, parameters.AuthorityURL, parameters.TenantID, parameters.ClientID, parameters.ClientSecret}, "|")
+	if client, ok := clients[key]; ok {
+		return client
+	}
+
+	credentials := clientcredentials.Config{
+		ClientID:     parameters.ClientID,
+		ClientSecret: parameters.ClientSecret,
+		TokenURL:     parameters.AuthorityURL + "/" + url.PathEscape(parameters.TenantID) + "/oauth2/v2.0/token",
+		Scopes:       []string{parameters.GraphURL + "/.default"},
+	}
+
+	transport := &http.Client{Timeout: 30 * time.Second, Transport: otelhttp.NewTransport(http.DefaultTransport)}
+	client := credentials.Client(context.WithValue(context.Background(), oauth2.HTTPClient, transport))
+	client.Timeout = 30 * time.Second
+
+	clients[key] = client
+	return client
+}
+
+// call sends request to Graph path or absolute nextLink URL and decodes 2xx response body into out
+func (e *EntraProvider) call(ctx context.Context, name string, method string, path string, in any, out any) error {
+	ctx, span := tracing.NewSpanWrapper(ctx, "entra."+name)
+	span.SetAttributes(
+		attribute.String("peer.service", "microsoft-graph"),
+		attribute.String("span.kind", "client"),
+	)
+	defer span.End()
+
+	rawURL := path
+	if !strings.HasPrefix(path, "https://") && !strings.HasPrefix(path, "http://") {
+		rawURL = e.Parameters.GraphURL + path
+	}
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		graphErr := &graphError{status: resp.StatusCode}
+		var payload struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(respBody, &payload) == nil {
+			graphErr.code, graphErr.message = payload.Error.Code, payload.Error.Message
+		}
+		return graphErr
+	}
+
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+// list follows @odata.nextLink and returns all items of a collection
+func (e *EntraProvider) list(ctx context.Context, name string, path string) ([]json.RawMessage, error) {
+	items := []json.RawMessage{}
+	for path != "" {
+		var page struct {
+			Value    []json.RawMessage `json:"value"`
+			NextLink string            `json:"@odata.nextLink"`
+		}
+		if err := e.call(ctx, name, http.MethodGet, path, nil, &page); err != nil {
+			return nil, err
+		}
+		items = append(items, page.Value...)
+		path = page.NextLink
+	}
+	return items, nil
+}
+
+func isStatus(err error, status int) bool {
+	var graphErr *graphError
+	return errors.As(err, &graphErr) && graphErr.status == status
+}
+
+func isCode(err error, code string) bool {
+	var graphErr *graphError
+	return errors.As(err, &graphErr) && graphErr.code == code
+}
+
+// getUserID resolves UPN to user object id
+func (e *EntraProvider) getUserID(ctx context.Context, username string) (string, error) {
+	if username == "" {
+		return "", errors.New("entra username is not set")
+	}
+
+	var user struct {
+		ID string `json:"id"`
+	}
+	err := e.call(ctx, "getUserID", http.MethodGet, "/v1.0/users/"+url.PathEscape(username)+"?$select=id", nil, &user)
+	if isStatus(err, http.StatusNotFound) {
+		return "", fmt.Errorf("%w: %s", errUserNotFound, username)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+	return user.ID, nil
+}
+
+// getUsername reads UPN of a user object. Returns empty string for objects that are not users
+func (e *EntraProvider) getUsername(ctx context.Context, userID string) (string, error) {
+	var user struct {
+		UserPrincipalName string `json:"userPrincipalName"`
+	}
+	err := e.call(ctx, "getUsername", http.MethodGet, "/v1.0/users/"+url.PathEscape(userID)+"?$select=userPrincipalName", nil, &user)
+	if isStatus(err, http.StatusNotFound) {
+		return "", nil
+	}
+	return user.UserPrincipalName, err
+}
+
+// addGroupMember adds a direct member. Returns false when user is already a member
+func (e *EntraProvider) addGroupMember(ctx context.Context, userID string) (bool, error) {
+	ref := map[string]string{
+		"@odata.id": e.Parameters.GraphURL + "/v1.0/directoryObjects/" + userID,
+	}
+	err := e.call(ctx, "addGroupMember", http.MethodPost, "/v1.0/groups/"+url.PathEscape(e.Parameters.Group)+"/members/$ref", ref, nil)
+
+	var graphErr *graphError
+	if errors.As(err, &graphErr) && graphErr.status == http.StatusBadRequest && strings.Contains(graphErr.message, "already exist") {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// removeGroupMember removes a direct member. Returns false when user is not a member
+func (e *EntraProvider) removeGroupMember(ctx context.Context, userID string) (bool, error) {
+	err := e.call(ctx, "removeGroupMember", http.MethodDelete, "/v1.0/groups/"+url.PathEscape(e.Parameters.Group)+"/members/"+url.PathEscape(userID)+"/$ref", nil, nil)
+	if isStatus(err, http.StatusNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (e *EntraProvider) listGroupMembers(ctx context.Context) ([]string, error) {
+	items, err := e.list(ctx, "listGroupMembers", "/v1.0/groups/"+url.PathEscape(e.Parameters.Group)+"/members/microsoft.graph.user?$select=userPrincipalName&$top=999")
+	if err != nil {
+		return nil, err
+	}
+
+	usernames := []string{}
+	for _, item := range items {
+		var user struct {
+			UserPrincipalName string `json:"userPrincipalName"`
+		}
+		if err := json.Unmarshal(item, &user); err != nil {
+			return nil, err
+		}
+		usernames = append(usernames, user.UserPrincipalName)
+	}
+	return usernames, nil
+}
+
+// assignmentEnd returns end of the active PIM membership assignment. Found is false when there is none
+func (e *EntraProvider) assignmentEnd(ctx context.Context, userID string) (end *time.Time, found bool, err error) {
+	filter := fmt.Sprintf("groupId eq %s and principalId eq %s and accessId eq 'member'", odataString(e.Parameters.Group), odataString(userID))
+	items, err := e.list(ctx, "assignmentEnd", pimInstancesPath+"?$filter="+url.QueryEscape(filter))
+	if err != nil || len(items) == 0 {
+		return nil, false, err
+	}
+
+	var instance struct {
+		EndDateTime *time.Time `json:"endDateTime"`
+	}
+	if err := json.Unmarshal(items[0], &instance); err != nil {
+		return nil, false, err
+	}
+	return instance.EndDateTime, true, nil
+}
+
+// assignGroupMember assigns PIM membership ending at end. Existing assignment is extended but never shortened.
+// Returns false when the user already had an assignment
+func (e *EntraProvider) assignGroupMember(ctx context.Context, userID string, end *time.Time, request *models.AccessRequest) (bool, error) {
+	current, found, err := e.assignmentEnd(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	action := "adminAssign"
+	if found {
+		if current == nil || (end != nil && !current.Before(*end)) {
+			return false, nil
+		}
+		action = "adminUpdate"
+	}
+
+	schedule := &scheduleInfo{
+		StartDateTime: time.Now().UTC(),
+		Expiration:    expiration{Type: "noExpiration"},
+	}
+	if end != nil {
+		endUTC := end.UTC()
+		schedule.Expiration = expiration{Type: "afterDateTime", EndDateTime: &endUTC}
+	}
+
+	err = e.call(ctx, "assignGroupMember", http.MethodPost, pimRequestsPath, assignmentScheduleRequest{
+		AccessID:      "member",
+		PrincipalID:   userID,
+		GroupID:       e.Parameters.Group,
+		Action:        action,
+		Justification: "Passage access request " + request.Id,
+		ScheduleInfo:  schedule,
+	}, nil)
+	if isCode(err, "RoleAssignmentExists") {
+		return false, nil
+	}
+	return err == nil && !found, err
+}
+
+// unassignGroupMember removes PIM membership assignment. Returns false when there is none
+func (e *EntraProvider) unassignGroupMember(ctx context.Context, userID string, request *models.AccessRequest) (bool, error) {
+	err := e.call(ctx, "unassignGroupMember", http.MethodPost, pimRequestsPath, assignmentScheduleRequest{
+		AccessID:      "member",
+		PrincipalID:   userID,
+		GroupID:       e.Parameters.Group,
+		Action:        "adminRemove",
+		Justification: "Passage access request " + request.Id + " expired",
+	}, nil)
+	if isCode(err, "RoleAssignmentDoesNotExist") || isStatus(err, http.StatusNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// listAssignedMembers lists UPNs of users with active PIM membership assignments
+func (e *EntraProvider) listAssignedMembers(ctx context.Context) ([]string, error) {
+	filter := fmt.Sprintf("groupId eq %s and accessId eq 'member'", odataString(e.Parameters.Group))
+	items, err := e.list(ctx, "listAssignedMembers", pimInstancesPath+"?$filter="+url.QueryEscape(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	usernames := []string{}
+	for _, item := range items {
+		var instance struct {
+			PrincipalID string `json:"principalId"`
+		}
+		if err := json.Unmarshal(item, &instance); err != nil {
+			return nil, err
+		}
+
+		username, err := e.getUsername(ctx, instance.PrincipalID)
+		if err != nil {
+			return nil, err
+		}
+		if username != "" {
+			usernames = append(usernames, username)
+		}
+	}
+	return usernames, nil
+}
+
+// odataString quotes value as OData string literal
+func odataString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// extractParameters parses the provider config into EntraProviderParameters
+func extractParameters(config models.ProviderConfig) (EntraProviderParameters, error) {
+	data := config.Parameters
+	creds := Config.GetCredentials(config.CredentialRef.Name)
+
+	parameters := EntraProviderParameters{
+		Group:        data["group"],
+		TenantID:     creds.GetString("tenantid"),
+		ClientID:     creds.GetString("clientid"),
+		ClientSecret: creds.GetString("clientsecret"),
+		GraphURL:     strings.TrimSuffix(creds.GetString("graphurl"), "/"),
+		AuthorityURL: strings.TrimSuffix(creds.GetString("authorityurl"), "/"),
+	}
+
+	if parameters.Group == "" {
+		return EntraProviderParameters{}, errors.New("group not found in provider config")
+	}
+	if parameters.TenantID == "" || parameters.ClientID == "" || parameters.ClientSecret == "" {
+		return EntraProviderParameters{}, errors.New("tenantid, clientid and clientsecret are required in credentials")
+	}
+
+	if value := data["membershipExpiry"]; value != "" {
+		membershipExpiry, err := strconv.ParseBool(value)
+		if err != nil {
+			return EntraProviderParameters{}, fmt.Errorf("invalid membershipExpiry: %w", err)
+		}
+		parameters.MembershipExpiry = membershipExpiry
+	}
+
+	if parameters.GraphURL == "" {
+		parameters.GraphURL = defaultGraphURL
+	}
+	if parameters.AuthorityURL == "" {
+		parameters.AuthorityURL = defaultAuthorityURL
+	}
+
+	return parameters, nil
+}
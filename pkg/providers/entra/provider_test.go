@@ -0,0 +1,318 @@
+package entra
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTenant = "tenant-1"
+	testGroup  = "group-1"
+)
+
+// graphAPI is an HTTP stand-in for the Entra token endpoint and Microsoft Graph
+type graphAPI struct {
+	mu          sync.Mutex
+	url         string
+	users       map[string]string
+	members     []string
+	assignments map[string]time.Time
+	requests    []assignmentScheduleRequest
+	tokens      int
+}
+
+func newGraphAPI(upns ...string) *graphAPI {
+	api := &graphAPI{users: map[string]string{}, assignments: map[string]time.Time{}}
+	for i, upn := range upns {
+		api.users[fmt.Sprintf("user-%d", i+1)] = upn
+	}
+	return api
+}
+
+func graphErrorBody(w http.ResponseWriter, status int, code string, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]string{"code": code, "message": message}})
+}
+
+func (g *graphAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if r.URL.Path == "/"+testTenant+"/oauth2/v2.0/token" {
+		_ = r.ParseForm()
+		if r.PostForm.Get("grant_type") != "client_credentials" || r.PostForm.Get("scope") != g.url+"/.default" {
+			http.Error(w, "invalid_request", http.StatusBadRequest)
+			return
+		}
+		g.tokens++
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "graph-token", "token_type": "Bearer", "expires_in": 3600})
+		return
+	}
+
+	if r.Header.Get("Authorization") != "Bearer graph-token" {
+		graphErrorBody(w, http.StatusUnauthorized, "InvalidAuthenticationToken", "Access token is empty.")
+		return
+	}
+
+	path := r.URL.Path
+	members := "/v1.0/groups/" + testGroup + "/members/"
+
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/v1.0/users/"):
+		key := strings.TrimPrefix(path, "/v1.0/users/")
+		for id, upn := range g.users {
+			if key == id || key == upn {
+				_ = json.NewEncoder(w).Encode(map[string]string{"id": id, "userPrincipalName": upn})
+				return
+			}
+		}
+		graphErrorBody(w, http.StatusNotFound, "Request_ResourceNotFound", "Resource '"+key+"' does not exist.")
+
+	case r.Method == http.MethodPost && path == members+"$ref":
+		var ref map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&ref)
+		id := strings.TrimPrefix(ref["@odata.id"], g.url+"/v1.0/directoryObjects/")
+		if slices.Contains(g.members, id) {
+			graphErrorBody(w, http.StatusBadRequest, "Request_BadRequest", "One or more added object references already exist for the following modified properties: 'members'.")
+			return
+		}
+		g.members = append(g.members, id)
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, members) && strings.HasSuffix(path, "/$ref"):
+		id := strings.TrimSuffix(strings.TrimPrefix(path, members), "/$ref")
+		if !slices.Contains(g.members, id) {
+			graphErrorBody(w, http.StatusNotFound, "Request_ResourceNotFound", "Resource '"+id+"' does not exist.")
+			return
+		}
+		g.members = slices.DeleteFunc(g.members, func(m string) bool { return m == id })
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodGet && path == members+"microsoft.graph.user":
+		// One member per page to exercise nextLink
+		skip := 0
+		_, _ = fmt.Sscan(r.URL.Query().Get("$skiptoken"), &skip)
+		page := map[string]any{"value": []map[string]string{}}
+		if skip < len(g.members) {
+			page["value"] = []map[string]string{{"userPrincipalName": g.users[g.members[skip]]}}
+		}
+		if skip+1 < len(g.members) {
+			page["@odata.nextLink"] = fmt.Sprintf("%s%s?$skiptoken=%d", g.url, path, skip+1)
+		}
+		_ = json.NewEncoder(w).Encode(page)
+
+	case r.Method == http.MethodGet && path == pimInstancesPath:
+		filter := r.URL.Query().Get("$filter")
+		instances := []map[string]any{}
+		for id, end := range g.assignments {
+			if strings.Contains(filter, "principalId") && !strings.Contains(filter, "principalId eq '"+id+"'") {
+				continue
+			}
+			instances = append(instances, map[string]any{"principalId": id, "groupId": testGroup, "accessId": "member", "endDateTime": end})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"value": instances})
+
+	case r.Method == http.MethodPost && path == pimRequestsPath:
+		var request assignmentScheduleRequest
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		g.requests = append(g.requests, request)
+
+		_, exists := g.assignments[request.PrincipalID]
+		switch request.Action {
+		case "adminAssign":
+			if exists {
+				graphErrorBody(w, http.StatusBadRequest, "RoleAssignmentExists", "The Role assignment already exists.")
+				return
+			}
+			g.assignments[request.PrincipalID] = *request.ScheduleInfo.Expiration.EndDateTime
+		case "adminUpdate":
+			g.assignments[request.PrincipalID] = *request.ScheduleInfo.Expiration.EndDateTime
+		case "adminRemove":
+			if !exists {
+				graphErrorBody(w, http.StatusNotFound, "RoleAssignmentDoesNotExist", "The Role assignment does not exist.")
+				return
+			}
+			delete(g.assignments, request.PrincipalID)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(request)
+
+	default:
+		graphErrorBody(w, http.StatusBadRequest, "BadRequest", "Unsupported request "+r.Method+" "+path)
+	}
+}
+
+func testProvider(t *testing.T, api *graphAPI, parameters map[string]string) *EntraProvider {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	api.url = server.URL
+
+	Config.Creds = map[string]models.Credential{
+		"entra": {
+			Name: "entra",
+			Data: map[string]string{
+				"tenantid":     testTenant,
+				"clientid":     "client-id",
+				"clientsecret": "client-secret",
+				"graphurl":     server.URL,
+				"authorityurl": server.URL,
+			},
+		},
+	}
+
+	p, err := NewEntraProvider(context.Background(), models.ProviderConfig{
+		Name:          "EntraContributors",
+		Provider:      "entra",
+		CredentialRef: models.CredentialRef{Name: "entra"},
+		Parameters:    parameters,
+	})
+	require.NoError(t, err)
+	return p
+}
+
+func testRequest(upn string, expiresAt time.Time) *models.AccessRequest {
+	request := &models.AccessRequest{Id: "req-1"}
+	request.Status.ExpiresAt = &expiresAt
+	request.Status.ProviderUsernames = map[string]string{providerType: upn}
+	return request
+}
+
+func TestGrantRevoke(t *testing.T) {
+	ctx := context.Background()
+	api := newGraphAPI("alice@example.com", "bob@example.com")
+	p := testProvider(t, api, map[string]string{"group": testGroup})
+
+	request := testRequest("alice@example.com", time.Now().Add(time.Hour))
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusGranted, request.Status.ProviderStatuses["EntraContributors"].Action)
+	assert.Equal(t, []string{"user-1"}, api.members)
+
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, "already in group", request.Status.ProviderStatuses["EntraContributors"].Error)
+
+	require.NoError(t, p.GrantAccess(ctx, testRequest("bob@example.com", time.Now().Add(time.Hour))))
+
+	users, err := p.ListUsersWithAccess(ctx, models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice@example.com", "bob@example.com"}, users)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusRevoked, request.Status.ProviderStatuses["EntraContributors"].Action)
+	assert.Equal(t, []string{"user-2"}, api.members)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, "already removed from group", request.Status.ProviderStatuses["EntraContributors"].Error)
+
+	// Client and its access token are reused across provider instances
+	p, err = NewEntraProvider(ctx, models.ProviderConfig{
+		Name:          "EntraContributors",
+		CredentialRef: models.CredentialRef{Name: "entra"},
+		Parameters:    map[string]string{"group": testGroup},
+	})
+	require.NoError(t, err)
+	_, err = p.ListUsersWithAccess(ctx, models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, api.tokens)
+}
+
+func TestMembershipExpiry(t *testing.T) {
+	ctx := context.Background()
+	api := newGraphAPI("alice@example.com")
+	p := testProvider(t, api, map[string]string{"group": testGroup, "membershipExpiry": "true"})
+
+	end := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	request := testRequest("alice@example.com", end)
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusGranted, request.Status.ProviderStatuses["EntraContributors"].Action)
+	assert.Empty(t, api.members)
+
+	require.Len(t, api.requests, 1)
+	assert.Equal(t, "adminAssign", api.requests[0].Action)
+	assert.Equal(t, "member", api.requests[0].AccessID)
+	assert.Equal(t, "afterDateTime", api.requests[0].ScheduleInfo.Expiration.Type)
+	assert.Equal(t, end, *api.requests[0].ScheduleInfo.Expiration.EndDateTime)
+
+	// Earlier expiration does not shorten the assignment
+	require.NoError(t, p.GrantAccess(ctx, testRequest("alice@example.com", end.Add(-time.Minute))))
+	assert.Len(t, api.requests, 1)
+
+	// Later expiration extends it
+	require.NoError(t, p.GrantAccess(ctx, testRequest("alice@example.com", end.Add(time.Hour))))
+	require.Len(t, api.requests, 2)
+	assert.Equal(t, "adminUpdate", api.requests[1].Action)
+	assert.Equal(t, end.Add(time.Hour), api.assignments["user-1"])
+
+	users, err := p.ListUsersWithAccess(ctx, models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice@example.com"}, users)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusRevoked, request.Status.ProviderStatuses["EntraContributors"].Action)
+	assert.Empty(t, api.assignments)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, "already removed from group", request.Status.ProviderStatuses["EntraContributors"].Error)
+
+	// Invalid TTL is not granted as a permanent assignment
+	invalid := testRequest("alice@example.com", end)
+	invalid.Status.ExpiresAt = nil
+	invalid.Details.TTL = "forever"
+	requests := len(api.requests)
+	require.Error(t, p.GrantAccess(ctx, invalid))
+	assert.Equal(t, models.ProviderStatusError, invalid.Status.ProviderStatuses["EntraContributors"].Action)
+	assert.Len(t, api.requests, requests)
+}
+
+func TestUnknownUser(t *testing.T) {
+	ctx := context.Background()
+	p := testProvider(t, newGraphAPI(), map[string]string{"group": testGroup})
+
+	request := testRequest("mallory@example.com", time.Now().Add(time.Hour))
+	assert.ErrorIs(t, p.GrantAccess(ctx, request), errUserNotFound)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, "user not found", request.Status.ProviderStatuses["EntraContributors"].Error)
+}
+
+func TestExtractParameters(t *testing.T) {
+	Config.Creds = map[string]models.Credential{
+		"entra":    {Name: "entra", Data: map[string]string{"tenantid": testTenant, "clientid": "client-id", "clientsecret": "secret"}},
+		"nosecret": {Name: "nosecret", Data: map[string]string{"tenantid": testTenant, "clientid": "client-id"}},
+	}
+
+	parameters, err := extractParameters(models.ProviderConfig{
+		CredentialRef: models.CredentialRef{Name: "entra"},
+		Parameters:    map[string]string{"group": testGroup},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, defaultGraphURL, parameters.GraphURL)
+	assert.Equal(t, defaultAuthorityURL, parameters.AuthorityURL)
+	assert.False(t, parameters.MembershipExpiry)
+
+	for _, config := range []models.ProviderConfig{
+		{CredentialRef: models.CredentialRef{Name: "entra"}, Parameters: map[string]string{}},
+		{CredentialRef: models.CredentialRef{Name: "nosecret"}, Parameters: map[string]string{"group": testGroup}},
+		{CredentialRef: models.CredentialRef{Name: "entra"}, Parameters: map[string]string{"group": testGroup, "membershipExpiry": "maybe"}},
+	} {
+		_, err := extractParameters(config)
+		assert.Error(t, err, fmt.Sprint(config.CredentialRef.Name, config.Parameters))
+	}
+}
+
+func TestODataString(t *testing.T) {
+	assert.Equal(t, `'o''brien'`, odataString("o'brien"))
+}
@@ -0,0 +1,34 @@
+package entra
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:             string(kinds.ProviderKindEntra),
+		RequiresUsername: true,
+		Description:      "Manages Microsoft Entra ID group membership through Microsoft Graph. Users are resolved by UPN",
+		Parameters: []registry.Parameter{
+			{Name: "group", Description: "Group object id", Required: true, Example: "02bd9fd6-8f93-4758-87c3-1fb73740a315"},
+			{Name: "membershipExpiry", Description: "Assign membership through PIM for Groups ending at request expiration instead of adding a permanent member", Example: "true"},
+		},
+		Credentials: []registry.Parameter{
+			{Name: "tenantid", Description: "Directory (tenant) id", Required: true, Example: "72f988bf-86f1-41af-91ab-2d7cd011db47"},
+			{Name: "clientid", Description: "Application (client) id granted GroupMember.ReadWrite.All and User.Read.All. PrivilegedAssignmentSchedule.ReadWrite.AzureADGroup is required for membershipExpiry", Required: true},
+			{Name: "clientsecret", Description: "Client secret of the application", Required: true},
+			{Name: "graphurl", Description: "Microsoft Graph endpoint for national clouds. Defaults to https://graph.microsoft.com", Example: "https://graph.microsoft.us"},
+			{Name: "authorityurl", Description: "Entra authority for national clouds. Defaults to https://login.microsoftonline.com", Example: "https://login.microsoftonline.us"},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewEntraProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
@@ -0,0 +1,292 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/config"
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/tracing"
+
+	"github.com/rs/zerolog/log"
+)
+
+const providerType = string(kinds.ProviderKindExec)
+
+// Maximum length of stdout and stderr stored in provider status
+const maxOutput = 4096
+
+const (
+	actionGrant  = "grant"
+	actionRevoke = "revoke"
+	actionList   = "list"
+)
+
+// Variables inherited from the server environment. Everything else is passed explicitly
+var inheritedEnv = []string{"PATH", "HOME", "LANG", "TMPDIR"}
+
+var Config = config.GetConfig()
+
+// ExecProvider manages access by running a local command
+type ExecProvider struct {
+	// provider name as defined in the provider configuration
+	name    string
+	command string
+	args    []string
+	workdir string
+	group   string
+	timeout time.Duration
+
+	parameters  map[string]string
+	credentials map[string]string
+}
+
+// Input is written to command stdin as JSON
+type Input struct {
+	Action     string                `json:"action"`
+	Provider   string                `json:"provider"`
+	Username   string                `json:"username,omitempty"`
+	Role       string                `json:"role"`
+	Group      string                `json:"group,omitempty"`
+	Parameters map[string]string     `json:"parameters"`
+	Request    *models.AccessRequest `json:"request,omitempty"`
+}
+
+// NewExecProvider initializes a new ExecProvider with credentials from ProviderConfig
+func NewExecProvider(ctx context.Context, config models.ProviderConfig) (*ExecProvider, error) {
+	data := config.Parameters
+
+	command := data["command"]
+	if command == "" {
+		return nil, errors.New("command not found in provider config")
+	}
+
+	timeout := 60 * time.Second
+	if value := data["timeout"]; value != "" {
+		var err error
+		timeout, err = time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+
+	creds := Config.GetCredentials(config.CredentialRef.Name)
+
+	return &ExecProvider{
+		name:        config.Name,
+		command:     command,
+		args:        strings.Fields(data["args"]),
+		workdir:     data["workdir"],
+		group:       data["group"],
+		timeout:     timeout,
+		parameters:  data,
+		credentials: creds.Data,
+	}, nil
+}
+
+// GrantAccess runs command with grant action
+func (p *ExecProvider) GrantAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "GrantAccess")
+	defer span.End()
+
+	input := p.input(actionGrant, request)
+
+	stdout, stderr, err := p.run(ctx, input)
+	if err != nil {
+		request.SetProviderStatusError(p.name, stdout, errorOutput(stderr, err))
+		return fmt.Errorf("failed to grant access: %w", err)
+	}
+
+	request.SetProviderStatusGranted(p.name, stdout, stderr)
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", input.Username).
+		Str("Command", p.command).
+		Msg("Access granted")
+
+	return nil
+}
+
+// RevokeAccess runs command with revoke action
+func (p *ExecProvider) RevokeAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "RevokeAccess")
+	defer span.End()
+
+	input := p.input(actionRevoke, request)
+
+	stdout, stderr, err := p.run(ctx, input)
+	if err != nil {
+		request.SetProviderStatusError(p.name, stdout, errorOutput(stderr, err))
+		return fmt.Errorf("failed to revoke access: %w", err)
+	}
+
+	request.SetProviderStatusRevoked(p.name, stdout, stderr)
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", input.Username).
+		Str("Command", p.command).
+		Msg("Access revoked")
+
+	return nil
+}
+
+// ListUsersWithAccess runs command with list action. Command prints JSON array or one username per line
+func (p *ExecProvider) ListUsersWithAccess(ctx context.Context, roleRef models.AccessRoleRef) ([]string, error) {
+	ctx, span := startSpan(ctx, "ListUsersWithAccess")
+	defer span.End()
+
+	input := Input{
+		Action:     actionList,
+		Provider:   p.name,
+		Role:       roleRef.Name,
+		Group:      p.group,
+		Parameters: p.parameters,
+	}
+
+	stdout, stderr, err := p.run(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %s", errorOutput(stderr, err))
+	}
+
+	return parseUsernames(stdout)
+}
+
+// IsAccessExpired checks whether the access for the given request has expired
+func (p *ExecProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
+	_, span := startSpan(ctx, "IsAccessExpired")
+	defer span.End()
+
+	return request.IsExpired(time.Now())
+}
+
+func (p *ExecProvider) input(action string, request *models.AccessRequest) Input {
+	return Input{
+		Action:     action,
+		Provider:   p.name,
+		Username:   request.GetProviderUsername(providerType),
+		Role:       request.RoleRef.Name,
+		Group:      p.group,
+		Parameters: p.parameters,
+		Request:    request,
+	}
+}
+
+// run executes command with input on stdin and returns trimmed stdout and stderr
+func (p *ExecProvider) run(ctx context.Context, input Input) (string, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	stdin, err := json.Marshal(input)
+	if err != nil {
+		return "", "", err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := osexec.CommandContext(ctx, p.command, p.args...)
+	cmd.Dir = p.workdir
+	cmd.Env = p.env(input)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Do not wait for orphaned children holding output pipes
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("command timed out after %s", p.timeout)
+	}
+
+	return output(stdout.String()), output(stderr.String()), err
+}
+
+// env builds command environment from request and provider configuration
+func (p *ExecProvider) env(input Input) []string {
+	env := []string{}
+	for _, key := range inheritedEnv {
+		if value, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+value)
+		}
+	}
+
+	env = append(env,
+		"PASSAGE_ACTION="+input.Action,
+		"PASSAGE_PROVIDER="+input.Provider,
+		"PASSAGE_USERNAME="+input.Username,
+		"PASSAGE_ROLE="+input.Role,
+		"PASSAGE_GROUP="+input.Group,
+	)
+	if input.Request != nil {
+		env = append(env,
+			"PASSAGE_REQUEST_ID="+input.Request.Id,
+			"PASSAGE_REQUESTED_BY="+input.Request.Status.RequestedBy,
+			"PASSAGE_TTL="+input.Request.Details.TTL,
+		)
+	}
+
+	env = append(env, prefixed("PASSAGE_PARAM_", p.parameters)...)
+	env = append(env, prefixed("PASSAGE_CRED_", p.credentials)...)
+
+	return env
+}
+
+// prefixed converts map to sorted environment variables with upper case keys
+func prefixed(prefix string, values map[string]string) []string {
+	env := []string{}
+	for key, value := range values {
+		name := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+		env = append(env, prefix+name+"="+value)
+	}
+	sort.Strings(env)
+	return env
+}
+
+func parseUsernames(stdout string) ([]string, error) {
+	usernames := []string{}
+
+	if strings.HasPrefix(stdout, "[") {
+		if err := json.Unmarshal([]byte(stdout), &usernames); err != nil {
+			return nil, fmt.Errorf("invalid list output: %w", err)
+		}
+		return usernames, nil
+	}
+
+	for _, line := range strings.Split(stdout, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			usernames = append(usernames, line)
+		}
+	}
+	return usernames, nil
+}
+
+func errorOutput(stderr string, err error) string {
+	if stderr == "" {
+		return err.Error()
+	}
+	return fmt.Sprintf("%s: %s", err, stderr)
+}
+
+func output(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > maxOutput {
+		return s[:maxOutput] + "..."
+	}
+	return s
+}
+
+func startSpan(ctx context.Context, name string) (context.Context, *tracing.SpanWrapper) {
+	ctx, span := tracing.NewSpanWrapper(ctx, fmt.Sprintf("providers.exec.%s", name))
+	return ctx, span
+}
@@ -0,0 +1,114 @@
+package exec
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testScript(t *testing.T, script string) string {
+	path := filepath.Join(t.TempDir(), "script.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755))
+	return path
+}
+
+func testProvider(t *testing.T, script string, parameters map[string]string) *ExecProvider {
+	Config.Creds = map[string]models.Credential{
+		"exec": {
+			Name: "exec",
+			Data: map[string]string{"token": "secret"},
+		},
+	}
+
+	params := map[string]string{"command": testScript(t, script), "group": "vpn-sre"}
+	for k, v := range parameters {
+		params[k] = v
+	}
+
+	p, err := NewExecProvider(context.Background(), models.ProviderConfig{
+		Name:          "Exec",
+		Provider:      "exec",
+		CredentialRef: models.CredentialRef{Name: "exec"},
+		Parameters:    params,
+	})
+	require.NoError(t, err)
+	return p
+}
+
+func testRequest() *models.AccessRequest {
+	request := &models.AccessRequest{Id: "req-1"}
+	request.RoleRef.Name = "VPN"
+	request.Status.ProviderUsernames = map[string]string{providerType: "alice"}
+	return request
+}
+
+func TestGrantRevoke(t *testing.T) {
+	script := `
+echo "$PASSAGE_ACTION $PASSAGE_USERNAME $PASSAGE_ROLE $PASSAGE_GROUP $PASSAGE_REQUEST_ID $PASSAGE_PARAM_GROUP $PASSAGE_CRED_TOKEN"
+grep -q '"action":"'$PASSAGE_ACTION'"' || exit 3
+echo "warning" >&2
+`
+	p := testProvider(t, script, nil)
+	ctx := context.Background()
+
+	request := testRequest()
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatus{
+		Action:  models.ProviderStatusGranted,
+		Details: "grant alice VPN vpn-sre req-1 vpn-sre secret",
+		Error:   "warning",
+	}, request.Status.ProviderStatuses["Exec"])
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusRevoked, request.Status.ProviderStatuses["Exec"].Action)
+	assert.Equal(t, "revoke alice VPN vpn-sre req-1 vpn-sre secret", request.Status.ProviderStatuses["Exec"].Details)
+}
+
+func TestNonZeroExit(t *testing.T) {
+	p := testProvider(t, "echo partial; echo 'user not found' >&2; exit 2", nil)
+
+	request := testRequest()
+	err := p.GrantAccess(context.Background(), request)
+	require.Error(t, err)
+
+	status := request.Status.ProviderStatuses["Exec"]
+	assert.Equal(t, models.ProviderStatusError, status.Action)
+	assert.Equal(t, "partial", status.Details)
+	assert.Equal(t, "exit status 2: user not found", status.Error)
+}
+
+func TestTimeout(t *testing.T) {
+	p := testProvider(t, "sleep 5", map[string]string{"timeout": "100ms"})
+
+	request := testRequest()
+	err := p.RevokeAccess(context.Background(), request)
+	assert.ErrorContains(t, err, "timed out")
+	assert.Equal(t, "command timed out after 100ms", request.Status.ProviderStatuses["Exec"].Error)
+}
+
+func TestListUsersWithAccess(t *testing.T) {
+	p := testProvider(t, `printf 'alice\n\nbob\n'`, nil)
+	users, err := p.ListUsersWithAccess(context.Background(), models.AccessRoleRef{Name: "VPN"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, users)
+
+	p = testProvider(t, `echo '["carol"]'`, nil)
+	users, err = p.ListUsersWithAccess(context.Background(), models.AccessRoleRef{Name: "VPN"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"carol"}, users)
+}
+
+func TestEnvironmentIsolated(t *testing.T) {
+	t.Setenv("PASSAGE_CREDS_GITLAB_DATA_TOKEN", "leaked")
+
+	p := testProvider(t, `echo "${PASSAGE_CREDS_GITLAB_DATA_TOKEN:-none}"`, nil)
+	request := testRequest()
+	require.NoError(t, p.GrantAccess(context.Background(), request))
+	assert.Equal(t, "none", request.Status.ProviderStatuses["Exec"].Details)
+}
@@ -0,0 +1,30 @@
+package exec
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind: string(kinds.ProviderKindExec),
+		Description: "Runs a local command. Request is passed as JSON on stdin and as PASSAGE_* environment variables, " +
+			"parameters as PASSAGE_PARAM_* and credentials as PASSAGE_CRED_*. Non-zero exit is an error",
+		Parameters: []registry.Parameter{
+			{Name: "command", Description: "Command to run. PASSAGE_ACTION is grant, revoke or list", Required: true, Example: "/opt/scripts/vpn-profile.sh"},
+			{Name: "args", Description: "Space separated command arguments", Example: "--profile sre"},
+			{Name: "workdir", Description: "Working directory", Example: "/opt/scripts"},
+			{Name: "group", Description: "Group passed to the command", Example: "vpn-sre"},
+			{Name: "timeout", Description: "Command timeout. Defaults to 60s", Example: "60s"},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewExecProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
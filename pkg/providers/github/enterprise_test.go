@@ -0,0 +1,251 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// enterpriseAPI fakes Github Enterprise Server API for org exampleorg and repository infra
+type enterpriseAPI struct {
+	members       []string
+	orgInvited    bool // alice has not accepted org invitation
+	collaborators []string
+	invitations   map[int64]bool // invitation id of alice to expired
+	nextID        int64
+}
+
+func (e *enterpriseAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reply := func(status int, body any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if body != nil {
+			json.NewEncoder(w).Encode(body)
+		}
+	}
+
+	path, ok := strings.CutPrefix(r.URL.Path, "/api/v3")
+	if !ok {
+		reply(http.StatusNotFound, map[string]any{"message": "Not Found"})
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && path == "/app/installations":
+		reply(http.StatusOK, []map[string]any{{"id": 1, "account": map[string]any{"login": "exampleorg"}}})
+	case r.Method == http.MethodPost && path == "/app/installations/1/access_tokens":
+		reply(http.StatusCreated, map[string]any{"token": "ghs_test", "expires_at": time.Now().Add(time.Hour)})
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/orgs/exampleorg/memberships/"):
+		username := strings.TrimPrefix(path, "/orgs/exampleorg/memberships/")
+		for _, member := range e.members {
+			if member == username {
+				reply(http.StatusOK, map[string]any{"state": "active", "role": "member"})
+				return
+			}
+		}
+		if e.orgInvited && username == "alice" {
+			reply(http.StatusOK, map[string]any{"state": "pending", "role": "member"})
+			return
+		}
+		reply(http.StatusNotFound, map[string]any{"message": "Not Found"})
+	case r.Method == http.MethodPut && path == "/orgs/exampleorg/memberships/alice":
+		if slices.Contains(e.members, "alice") {
+			reply(http.StatusOK, map[string]any{"state": "active", "role": "member"})
+			return
+		}
+		e.orgInvited = true
+		reply(http.StatusOK, map[string]any{"state": "pending", "role": "member"})
+	case r.Method == http.MethodDelete && path == "/orgs/exampleorg/memberships/alice":
+		e.orgInvited = false
+		e.members = slices.DeleteFunc(e.members, func(m string) bool { return m == "alice" })
+		reply(http.StatusNoContent, nil)
+	case r.Method == http.MethodGet && path == "/repos/exampleorg/infra/invitations":
+		invitations := []map[string]any{}
+		for id, expired := range e.invitations {
+			invitations = append(invitations, map[string]any{"id": id, "invitee": map[string]any{"login": "Alice"}, "expired": expired})
+		}
+		reply(http.StatusOK, invitations)
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, "/repos/exampleorg/infra/invitations/"):
+		var id int64
+		fmt.Sscan(strings.TrimPrefix(path, "/repos/exampleorg/infra/invitations/"), &id)
+		delete(e.invitations, id)
+		reply(http.StatusNoContent, nil)
+	case r.Method == http.MethodPut && path == "/repos/exampleorg/infra/collaborators/alice":
+		for _, c := range e.collaborators {
+			if c == "alice" {
+				reply(http.StatusNoContent, nil)
+				return
+			}
+		}
+		for id := range e.invitations {
+			reply(http.StatusCreated, map[string]any{"id": id})
+			return
+		}
+		e.nextID++
+		e.invitations[e.nextID] = false
+		reply(http.StatusCreated, map[string]any{"id": e.nextID})
+	case r.Method == http.MethodDelete && path == "/repos/exampleorg/infra/collaborators/alice":
+		e.collaborators = nil
+		reply(http.StatusNoContent, nil)
+	case r.Method == http.MethodGet && path == "/repos/exampleorg/infra/collaborators":
+		collaborators := []map[string]any{}
+		if r.URL.Query().Get("affiliation") == "outside" {
+			for _, c := range e.collaborators {
+				collaborators = append(collaborators, map[string]any{"login": c})
+			}
+		}
+		reply(http.StatusOK, collaborators)
+	default:
+		reply(http.StatusNotFound, map[string]any{"message": "Not Found"})
+	}
+}
+
+func enterpriseProvider(t *testing.T, params map[string]string) (*GithubProvider, *enterpriseAPI) {
+	api := &enterpriseAPI{invitations: map[int64]bool{}}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPath := filepath.Join(t.TempDir(), "app.pem")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0o600))
+
+	Config.Creds = map[string]models.Credential{"ghes": {Name: "ghes", Data: map[string]string{
+		"appid":          "42",
+		"privatekeypath": keyPath,
+		"baseurl":        server.URL,
+	}}}
+
+	p, err := NewGithubProvider(context.Background(), models.ProviderConfig{
+		Name:          "Ghes",
+		Provider:      "github",
+		CredentialRef: models.CredentialRef{Name: "ghes"},
+		Parameters:    params,
+	})
+	require.NoError(t, err)
+	return p, api
+}
+
+func enterpriseRequest(username string) *models.AccessRequest {
+	request := &models.AccessRequest{Id: "req-1"}
+	request.Status.ProviderUsernames = map[string]string{string(kinds.ProviderKindGithub): username}
+	return request
+}
+
+func TestOutsideCollaborator(t *testing.T) {
+	p, api := enterpriseProvider(t, map[string]string{
+		"org":                 "exampleorg",
+		"repositories":        `{"infra":"push"}`,
+		"outsideCollaborator": "true",
+	})
+	assert.Equal(t, p.InstallationClient.BaseURL.String(), p.AppClient.BaseURL.String())
+	assert.True(t, strings.HasSuffix(p.InstallationClient.BaseURL.Path, "/api/v3/"))
+
+	ctx := context.Background()
+	request := enterpriseRequest("alice")
+
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatus{Action: models.ProviderStatusGranted, Details: "exampleorg (invitation pending: infra)"}, request.Status.ProviderStatuses["Ghes"])
+	require.Len(t, api.invitations, 1)
+
+	// Grant replaces invitation expired unaccepted
+	api.invitations[1] = true
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, map[int64]bool{2: false}, api.invitations)
+
+	// Revoke cancels unaccepted invitation, expired or not
+	api.invitations[2] = true
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatus{Action: models.ProviderStatusRevoked, Details: "exampleorg (invitation cancelled: infra)"}, request.Status.ProviderStatuses["Ghes"])
+	assert.Empty(t, api.invitations)
+
+	// Accepted invitation
+	api.collaborators = []string{"alice"}
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatus{Action: models.ProviderStatusGranted, Details: "exampleorg"}, request.Status.ProviderStatuses["Ghes"])
+
+	users, err := p.ListUsersWithAccess(ctx, models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, users)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatus{Action: models.ProviderStatusRevoked, Details: "exampleorg"}, request.Status.ProviderStatuses["Ghes"])
+	assert.Empty(t, api.collaborators)
+}
+
+func TestOrgInvitation(t *testing.T) {
+	p, api := enterpriseProvider(t, map[string]string{
+		"org":  "exampleorg",
+		"role": "member",
+	})
+
+	ctx := context.Background()
+	request := enterpriseRequest("alice")
+
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatus{Action: models.ProviderStatusGranted, Details: "exampleorg (invitation pending: exampleorg)"}, request.Status.ProviderStatuses["Ghes"])
+	assert.True(t, api.orgInvited)
+
+	// Invitation sent before the grant is left to its owner
+	other := enterpriseRequest("alice")
+	require.NoError(t, p.GrantAccess(ctx, other))
+	require.NoError(t, p.RevokeAccess(ctx, other))
+	assert.Equal(t, models.ProviderStatus{Action: models.ProviderStatusRevoked, Details: "exampleorg"}, other.Status.ProviderStatuses["Ghes"])
+	assert.True(t, api.orgInvited)
+
+	// Org membership is kept on revoke, but unaccepted invitation is cancelled
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatus{Action: models.ProviderStatusRevoked, Details: "exampleorg (invitation cancelled: exampleorg)"}, request.Status.ProviderStatuses["Ghes"])
+	assert.False(t, api.orgInvited)
+
+	api.members = []string{"alice"}
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatus{Action: models.ProviderStatusRevoked, Details: "exampleorg"}, request.Status.ProviderStatuses["Ghes"])
+	assert.Equal(t, []string{"alice"}, api.members)
+}
+
+func TestOutsideCollaboratorOrgMember(t *testing.T) {
+	p, api := enterpriseProvider(t, map[string]string{
+		"org":                 "exampleorg",
+		"repositories":        `{"infra":"push"}`,
+		"outsideCollaborator": "true",
+	})
+	api.members = []string{"alice"}
+
+	request := enterpriseRequest("alice")
+	err := p.GrantAccess(context.Background(), request)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not an outside collaborator")
+	assert.Equal(t, models.ProviderStatusError, request.Status.ProviderStatuses["Ghes"].Action)
+	assert.Empty(t, api.invitations)
+}
+
+func TestOutsideCollaboratorParameters(t *testing.T) {
+	for _, params := range []map[string]string{
+		{"org": "exampleorg", "outsideCollaborator": "true"},
+		{"org": "exampleorg", "outsideCollaborator": "true", "repositories": `{"infra":"push"}`, "role": "member"},
+		{"org": "exampleorg", "outsideCollaborator": "true", "repositories": `{"infra":"push"}`, "teams": `{"sre":"member"}`},
+		{"org": "exampleorg", "outsideCollaborator": "true", "repositories": `{"infra":"push"}`, "removeUser": "true"},
+	} {
+		_, err := extractParameters(models.ProviderConfig{Parameters: params})
+		assert.Error(t, err, params)
+	}
+}
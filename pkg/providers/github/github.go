@@ -2,9 +2,9 @@ package github
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/CTO2BPublic/passage-server/pkg/config"
@@ -24,6 +24,9 @@ import (
 var Config = config.GetConfig()
 var Tracer = otel.Tracer("pkg/providers/github")
 
+// orgInvitationReference is the provider reference set when the grant sent org invitation
+const orgInvitationReference = "orgInvitation"
+
 type GithubProvider struct {
 	AppClient          *github.Client
 	InstallationClient *github.Client
@@ -33,12 +36,13 @@ type GithubProvider struct {
 }
 
 type GithubProviderParameters struct {
-	Org          string
-	Role         string
-	OrgRoles     []string
-	Teams        map[string]string
-	Repositories map[string]string
-	RemoveUser   string
+	Org                 string
+	Role                string
+	OrgRoles            []string
+	Teams               map[string]string
+	Repositories        map[string]string
+	RemoveUser          string
+	OutsideCollaborator string
 }
 
 func NewGithubProvider(ctx context.Context, config models.ProviderConfig) (*GithubProvider, error) {
@@ -55,6 +59,7 @@ func NewGithubProvider(ctx context.Context, config models.ProviderConfig) (*Gith
 	}
 
 	keyPath := creds.GetString("privatekeypath")
+	baseURL := creds.GetString("baseurl")
 
 	var installationID int64
 
@@ -68,7 +73,11 @@ func NewGithubProvider(ctx context.Context, config models.ProviderConfig) (*Gith
 		return nil, fmt.Errorf("failed to create apps transport: %w", err)
 	}
 
-	appClient := github.NewClient(&http.Client{Transport: appTr})
+	appClient, err := newClient(&http.Client{Transport: appTr}, baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid baseurl: %w", err)
+	}
+	appTr.BaseURL = apiBaseURL(appClient)
 
 	installations, _, err := appClient.Apps.ListInstallations(ctx, nil)
 	if err != nil {
@@ -87,14 +96,17 @@ func NewGithubProvider(ctx context.Context, config models.ProviderConfig) (*Gith
 	}
 
 	// Installation client
-	insTr := http.DefaultTransport
-	insTr, err = ghinstallation.NewKeyFromFile(insTr, appID, installationID, keyPath)
+	insTr, err := ghinstallation.NewKeyFromFile(http.DefaultTransport, appID, installationID, keyPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GitHub App transport: %w", err)
 	}
+	insTr.BaseURL = appTr.BaseURL
 
 	tracedInsTr := otelhttp.NewTransport(insTr)
-	installationClient := github.NewClient(&http.Client{Transport: tracedInsTr})
+	installationClient, err := newClient(&http.Client{Transport: tracedInsTr}, baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid baseurl: %w", err)
+	}
 
 	// PAT Client
 	pat := creds.GetString("pat")
@@ -102,7 +114,10 @@ func NewGithubProvider(ctx context.Context, config models.ProviderConfig) (*Gith
 		&oauth2.Token{AccessToken: pat},
 	)
 	tc := oauth2.NewClient(ctx, patTr)
-	patClient := github.NewClient(tc)
+	patClient, err := newClient(tc, baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid baseurl: %w", err)
+	}
 
 	return &GithubProvider{
 		AppClient:          appClient,
@@ -119,14 +134,41 @@ func (p *GithubProvider) GrantAccess(ctx context.Context, request *models.Access
 
 	params := p.Parameters
 	username := request.GetProviderUsername(string(kinds.ProviderKindGithub))
+	pending := []string{}
+
+	// Outside collaborators must not be organization members
+	if params.OutsideCollaborator == "true" {
+		isMember, err := p.isOrgMember(ctx, params.Org, username)
+		if err != nil {
+			request.SetProviderStatusError(p.Name, params.Org, err.Error())
+			return fmt.Errorf("failed to check org membership: %w", err)
+		}
+		if isMember {
+			err := fmt.Errorf("user %s is a member of Github org %s, not an outside collaborator", username, params.Org)
+			request.SetProviderStatusError(p.Name, params.Org, err.Error())
+			return err
+		}
+	}
 
 	// Manage Org membership
 	if params.Role != "" {
-		err := p.addUserToOrg(ctx, params.Org, params.Role, username)
+		existing, err := p.isOrgMember(ctx, params.Org, username)
+		if err != nil {
+			request.SetProviderStatusError(p.Name, params.Org, err.Error())
+			return fmt.Errorf("failed to check org membership: %w", err)
+		}
+		invited, err := p.addUserToOrg(ctx, params.Org, params.Role, username)
 		if err != nil {
 			request.SetProviderStatusError(p.Name, params.Role, err.Error())
 			return fmt.Errorf("failed setting Github Org membership: %w", err)
 		}
+		if invited {
+			pending = append(pending, params.Org)
+		}
+		// Only invitation sent by this request is cancelled on revoke
+		if invited && !existing {
+			request.SetProviderReference(p.Name, orgInvitationReference, "true")
+		}
 	}
 
 	// Run roles, teams, repos in parallel
@@ -157,9 +199,11 @@ func (p *GithubProvider) GrantAccess(ctx context.Context, request *models.Access
 	}
 
 	// Manage direct Repository access
+	invitedRepos := []string{}
 	if len(params.Repositories) > 0 {
 		g.Go(func() error {
-			err := p.addUserToRepos(ctx, params.Org, params.Repositories, username)
+			var err error
+			invitedRepos, err = p.addUserToRepos(ctx, params.Org, params.Repositories, username)
 			if err != nil {
 				request.SetProviderStatusError(p.Name, params.Role, err.Error())
 				return fmt.Errorf("failed setting Direct Github Repository permissions %+v: %w", params.Teams, err)
@@ -173,6 +217,20 @@ func (p *GithubProvider) GrantAccess(ctx context.Context, request *models.Access
 		return err
 	}
 
+	// Access starts once the user accepts the invitations
+	pending = append(pending, invitedRepos...)
+	if len(pending) > 0 {
+		request.SetProviderStatusGranted(p.Name, params.Org+" (invitation pending: "+strings.Join(pending, ",")+")", "")
+		log.Info().
+			Str("Provider", p.Name).
+			Str("AccessRequest", request.Id).
+			Str("Username", username).
+			Str("Org", params.Org).
+			Strs("Pending", pending).
+			Msg("User invited")
+		return nil
+	}
+
 	request.SetProviderStatusGranted(p.Name, params.Org, "")
 	log.Info().
 		Str("Provider", p.Name).
@@ -190,13 +248,23 @@ func (p *GithubProvider) RevokeAccess(ctx context.Context, request *models.Acces
 	params := p.Parameters
 	username := request.GetProviderUsername(string(kinds.ProviderKindGithub))
 
-	// Manage Org membership
+	// Manage Org membership. Membership is kept unless removeUser is set, but unaccepted invitation sent on grant is cancelled
+	cancelled := []string{}
 	if params.RemoveUser == "true" {
 		err := p.removeUserFromOrg(ctx, params.Org, username)
 		if err != nil {
 			request.SetProviderStatusError(p.Name, params.Org, err.Error())
 			return fmt.Errorf("failed to remove user from org: %w", err)
 		}
+	} else if request.GetProviderReference(p.Name, orgInvitationReference) != "" {
+		invited, err := p.cancelOrgInvitation(ctx, params.Org, username)
+		if err != nil {
+			request.SetProviderStatusError(p.Name, params.Org, err.Error())
+			return fmt.Errorf("failed to cancel org invitation: %w", err)
+		}
+		if invited {
+			cancelled = append(cancelled, params.Org)
+		}
 	}
 
 	// Manage Org Roles
@@ -218,15 +286,20 @@ func (p *GithubProvider) RevokeAccess(ctx context.Context, request *models.Acces
 
 	// Manage direct Repository access
 	if len(params.Repositories) > 0 {
-		err := p.removeUserFromRepos(ctx, params.Org, params.Repositories, username)
+		cancelledRepos, err := p.removeUserFromRepos(ctx, params.Org, params.Repositories, username)
 		if err != nil {
 			request.SetProviderStatusError(p.Name, params.Role, err.Error())
 			return fmt.Errorf("failed setting Direct Github Repository permissions %+v: %w", params.Teams, err)
 		}
+		cancelled = append(cancelled, cancelledRepos...)
 
 	}
 
-	request.SetProviderStatusRevoked(p.Name, params.Org, "")
+	if len(cancelled) > 0 {
+		request.SetProviderStatusRevoked(p.Name, params.Org+" (invitation cancelled: "+strings.Join(cancelled, ",")+")", "")
+	} else {
+		request.SetProviderStatusRevoked(p.Name, params.Org, "")
+	}
 	log.Info().
 		Str("Provider", p.Name).
 		Str("AccessRequest", request.Id).
@@ -242,6 +315,10 @@ func (p *GithubProvider) ListUsersWithAccess(ctx context.Context, roleRef models
 	defer span.End()
 
 	params := p.Parameters
+	if params.OutsideCollaborator == "true" {
+		return p.listOutsideCollaborators(ctx, params.Org, params.Repositories)
+	}
+
 	members, _, err := p.InstallationClient.Organizations.ListMembers(ctx, params.Org, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list org members: %w", err)
@@ -256,15 +333,22 @@ func (p *GithubProvider) ListUsersWithAccess(ctx context.Context, roleRef models
 	return usernames, nil
 }
 
+// IsAccessExpired checks whether the access for the given request has expired. Unaccepted invitations are
+// replaced on grant and cancelled on revoke
 func (p *GithubProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
-	ttl := request.Details.TTL
-	if ttl == "" {
-		return false, errors.New("TTL not specified")
-	}
-	expiry, err := time.ParseDuration(ttl)
-	if err != nil {
-		return false, fmt.Errorf("invalid TTL format: %w", err)
+	return request.IsExpired(time.Now())
+}
+
+// newClient returns client for github.com, or for Github Enterprise Server when baseURL is set
+func newClient(httpClient *http.Client, baseURL string) (*github.Client, error) {
+	client := github.NewClient(httpClient)
+	if baseURL == "" {
+		return client, nil
 	}
-	expirationTime := request.CreatedAt.Add(expiry)
-	return time.Now().After(expirationTime), nil
+	return client.WithEnterpriseURLs(baseURL, baseURL)
+}
+
+// apiBaseURL returns API URL of the client in the form expected by ghinstallation
+func apiBaseURL(client *github.Client) string {
+	return strings.TrimSuffix(client.BaseURL.String(), "/")
 }
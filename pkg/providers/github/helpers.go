@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"slices"
 	"strconv"
 	"strings"
@@ -35,7 +36,8 @@ func (p *GithubProvider) isOrgMember(ctx context.Context, org, user string) (boo
 	return membership != nil, nil
 }
 
-func (p *GithubProvider) addUserToOrg(ctx context.Context, org string, role string, username string) error {
+// addUserToOrg sets org membership. Returns true when the user is invited and has not accepted yet
+func (p *GithubProvider) addUserToOrg(ctx context.Context, org string, role string, username string) (bool, error) {
 	ctx, span := tracing.NewSpanWrapper(ctx, "github.addUserToOrg")
 	span.SetAttributes(
 		attribute.String("peer.service", "github"),
@@ -47,10 +49,10 @@ func (p *GithubProvider) addUserToOrg(ctx context.Context, org string, role stri
 	if !slices.Contains(supportedRoles, role) {
 		err := fmt.Errorf("unsupported Github Org membership role: %s", role)
 		span.RecordError(err)
-		return err
+		return false, err
 	}
 
-	_, _, err := p.InstallationClient.Organizations.EditOrgMembership(
+	membership, _, err := p.InstallationClient.Organizations.EditOrgMembership(
 		ctx,
 		username,
 		org,
@@ -58,10 +60,39 @@ func (p *GithubProvider) addUserToOrg(ctx context.Context, org string, role stri
 	)
 	if err != nil {
 		span.RecordError(err)
-		return err
+		return false, err
 	}
 
-	return nil
+	return membership.GetState() == "pending", nil
+}
+
+// cancelOrgInvitation cancels org invitation the user has not accepted. Returns false when there is none
+func (p *GithubProvider) cancelOrgInvitation(ctx context.Context, org string, username string) (bool, error) {
+	ctx, span := tracing.NewSpanWrapper(ctx, "github.cancelOrgInvitation")
+	span.SetAttributes(
+		attribute.String("peer.service", "github"),
+		attribute.String("span.kind", "client"),
+	)
+	defer span.End()
+
+	membership, resp, err := p.InstallationClient.Organizations.GetOrgMembership(ctx, username, org)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		return false, err
+	}
+	if membership.GetState() != "pending" {
+		return false, nil
+	}
+
+	// Removing pending membership cancels the invitation
+	if _, err := p.InstallationClient.Organizations.RemoveOrgMembership(ctx, username, org); err != nil {
+		span.RecordError(err)
+		return false, err
+	}
+	return true, nil
 }
 
 func (p *GithubProvider) removeUserFromOrg(ctx context.Context, org string, username string) error {
@@ -244,7 +275,8 @@ func (p *GithubProvider) removeOrgRolesFromUser(ctx context.Context, org string,
 	return nil
 }
 
-func (p *GithubProvider) addUserToRepos(ctx context.Context, org string, repos map[string]string, username string) error {
+// addUserToRepos adds the user as collaborator. Returns repositories where the user is invited and has not accepted yet
+func (p *GithubProvider) addUserToRepos(ctx context.Context, org string, repos map[string]string, username string) ([]string, error) {
 	ctx, span := tracing.NewSpanWrapper(ctx, "github.addUserToRepos")
 	span.SetAttributes(
 		attribute.String("peer.service", "github"),
@@ -252,9 +284,24 @@ func (p *GithubProvider) addUserToRepos(ctx context.Context, org string, repos m
 	)
 	defer span.End()
 
+	invited := []string{}
 	for repo, role := range repos {
 
-		_, _, err := p.InstallationClient.Repositories.AddCollaborator(
+		// Expired invitation can not be accepted. Replace it with a new one
+		invitation, err := p.findInvitation(ctx, org, repo, username)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		if invitation.GetExpired() {
+			_, err := p.InstallationClient.Repositories.DeleteInvitation(ctx, org, repo, invitation.GetID())
+			if err != nil {
+				span.RecordError(err)
+				return nil, err
+			}
+		}
+
+		_, resp, err := p.InstallationClient.Repositories.AddCollaborator(
 			ctx,
 			org,
 			repo,
@@ -265,14 +312,20 @@ func (p *GithubProvider) addUserToRepos(ctx context.Context, org string, repos m
 		)
 		if err != nil {
 			span.RecordError(err)
-			return err
+			return nil, err
+		}
+		// Created means an invitation was sent. Existing collaborators get No Content
+		if resp.StatusCode == http.StatusCreated {
+			invited = append(invited, repo)
 		}
 	}
 
-	return nil
+	slices.Sort(invited)
+	return invited, nil
 }
 
-func (p *GithubProvider) removeUserFromRepos(ctx context.Context, org string, repos map[string]string, username string) error {
+// removeUserFromRepos removes the collaborator and cancels unaccepted invitations. Returns repositories with cancelled invitations
+func (p *GithubProvider) removeUserFromRepos(ctx context.Context, org string, repos map[string]string, username string) ([]string, error) {
 	ctx, span := tracing.NewSpanWrapper(ctx, "github.removeUserFromRepos")
 	span.SetAttributes(
 		attribute.String("peer.service", "github"),
@@ -280,9 +333,24 @@ func (p *GithubProvider) removeUserFromRepos(ctx context.Context, org string, re
 	)
 	defer span.End()
 
+	cancelled := []string{}
 	for repo := range repos {
 
-		_, err := p.InstallationClient.Repositories.RemoveCollaborator(
+		invitation, err := p.findInvitation(ctx, org, repo, username)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		if invitation != nil {
+			_, err := p.InstallationClient.Repositories.DeleteInvitation(ctx, org, repo, invitation.GetID())
+			if err != nil {
+				span.RecordError(err)
+				return nil, err
+			}
+			cancelled = append(cancelled, repo)
+		}
+
+		_, err = p.InstallationClient.Repositories.RemoveCollaborator(
 			ctx,
 			org,
 			repo,
@@ -290,11 +358,81 @@ func (p *GithubProvider) removeUserFromRepos(ctx context.Context, org string, re
 		)
 		if err != nil {
 			span.RecordError(err)
-			return err
+			return nil, err
 		}
 	}
 
-	return nil
+	slices.Sort(cancelled)
+	return cancelled, nil
+}
+
+// findInvitation returns pending repository invitation of the user, or nil when there is none
+func (p *GithubProvider) findInvitation(ctx context.Context, org string, repo string, username string) (*github.RepositoryInvitation, error) {
+	ctx, span := tracing.NewSpanWrapper(ctx, "github.findInvitation")
+	span.SetAttributes(
+		attribute.String("peer.service", "github"),
+		attribute.String("span.kind", "client"),
+	)
+	defer span.End()
+
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		invitations, resp, err := p.InstallationClient.Repositories.ListInvitations(ctx, org, repo, opts)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+
+		for _, invitation := range invitations {
+			if strings.EqualFold(invitation.GetInvitee().GetLogin(), username) {
+				return invitation, nil
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// listOutsideCollaborators lists outside collaborators of the repositories
+func (p *GithubProvider) listOutsideCollaborators(ctx context.Context, org string, repos map[string]string) ([]string, error) {
+	ctx, span := tracing.NewSpanWrapper(ctx, "github.listOutsideCollaborators")
+	span.SetAttributes(
+		attribute.String("peer.service", "github"),
+		attribute.String("span.kind", "client"),
+	)
+	defer span.End()
+
+	usernames := []string{}
+	for repo := range repos {
+		opts := &github.ListCollaboratorsOptions{
+			Affiliation: "outside",
+			ListOptions: github.ListOptions{PerPage: 100},
+		}
+		for {
+			collaborators, resp, err := p.InstallationClient.Repositories.ListCollaborators(ctx, org, repo, opts)
+			if err != nil {
+				span.RecordError(err)
+				return nil, fmt.Errorf("failed to list collaborators of %s: %w", repo, err)
+			}
+
+			for _, c := range collaborators {
+				if !slices.Contains(usernames, c.GetLogin()) {
+					usernames = append(usernames, c.GetLogin())
+				}
+			}
+
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+	}
+
+	slices.Sort(usernames)
+	return usernames, nil
 }
 
 func extractParameters(cfg models.ProviderConfig) (GithubProviderParameters, error) {
@@ -342,13 +480,29 @@ func extractParameters(cfg models.ProviderConfig) (GithubProviderParameters, err
 		removeUser = "false"
 	}
 
+	outsideCollaborator, ok := data["outsideCollaborator"]
+	if !ok {
+		outsideCollaborator = "false"
+	}
+
+	// Outside collaborators only get repository access
+	if outsideCollaborator == "true" {
+		if len(repoMap) == 0 {
+			return GithubProviderParameters{}, errors.New("repositories are required for outside collaborators")
+		}
+		if role != "" || len(orgRolesList) > 0 || len(teamsMap) > 0 || removeUser == "true" {
+			return GithubProviderParameters{}, errors.New("role, orgRoles, teams and removeUser can not be used with outside collaborators")
+		}
+	}
+
 	return GithubProviderParameters{
-		Org:          org,
-		Role:         role,
-		OrgRoles:     orgRolesList,
-		Teams:        teamsMap,
-		Repositories: repoMap,
-		RemoveUser:   removeUser,
+		Org:                 org,
+		Role:                role,
+		OrgRoles:            orgRolesList,
+		Teams:               teamsMap,
+		Repositories:        repoMap,
+		RemoveUser:          removeUser,
+		OutsideCollaborator: outsideCollaborator,
 	}, nil
 }
 
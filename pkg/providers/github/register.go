@@ -0,0 +1,38 @@
+package github
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:             string(kinds.ProviderKindGithub),
+		RequiresUsername: true,
+		Description:      "Manages Github organization membership, organization roles, teams and repository access",
+		Parameters: []registry.Parameter{
+			{Name: "org", Description: "Organization login", Required: true, Example: "CTO2BPublic"},
+			{Name: "role", Description: "Organization membership role", Example: "member"},
+			{Name: "orgRoles", Description: "YAML list of organization roles", Example: "[security-manager]"},
+			{Name: "teams", Description: "YAML map of team slug to team role", Example: "sre: member"},
+			{Name: "repositories", Description: "YAML map of repository to permission", Example: "infra: push"},
+			{Name: "removeUser", Description: "Remove user from organization on revoke", Example: "false"},
+			{Name: "outsideCollaborator", Description: "Grant repositories to outside collaborators only. Organization members are refused", Example: "true"},
+		},
+		Credentials: []registry.Parameter{
+			{Name: "appid", Description: "Github App id", Required: true, Example: "123456"},
+			{Name: "privatekeypath", Description: "Path to Github App private key", Required: true, Example: "/secrets/github.pem"},
+			{Name: "pat", Description: "Personal access token used for organization roles"},
+			{Name: "baseurl", Description: "API base URL of Github Enterprise Server", Example: "https://github.exampleorg.com/api/v3/"},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewGithubProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
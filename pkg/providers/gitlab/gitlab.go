@@ -8,6 +8,7 @@ import (
 
 	"github.com/CTO2BPublic/passage-server/pkg/config"
 	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
 	"github.com/CTO2BPublic/passage-server/pkg/tracing"
 
 	"github.com/rs/zerolog/log"
@@ -15,6 +16,11 @@ import (
 	"go.opentelemetry.io/otel"
 )
 
+const providerType = string(kinds.ProviderKindGitlab)
+
+// API endpoint used when baseurl is not set in credentials
+const defaultBaseURL = "https://gitlab.com/api/v4"
+
 var Config = config.GetConfig()
 var Tracer = otel.Tracer("pkg/providers/gitlab")
 
@@ -29,8 +35,10 @@ type GitlabProvider struct {
 // parameters encapsulates extracted provider details
 type GitlabProviderParameters struct {
 	Group    string                    `json:"group"`
+	Project  string                    `json:"project"`
 	Username string                    `json:"username"`
 	Token    string                    `json:"token"`
+	BaseURL  string                    `json:"baseUrl"`
 	Level    clientgo.AccessLevelValue `json:"string"`
 }
 
@@ -41,7 +49,7 @@ func NewGitlabProvider(ctx context.Context, config models.ProviderConfig) (*Gitl
 		return nil, fmt.Errorf("failed to parse provider config: %w", err)
 	}
 
-	client, err := clientgo.NewClient(parameters.Token, clientgo.WithBaseURL("https://gitlab.com/api/v4"))
+	client, err := clientgo.NewClient(parameters.Token, clientgo.WithBaseURL(parameters.BaseURL))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GitLab client: %w", err)
 	}
@@ -49,13 +57,19 @@ func NewGitlabProvider(ctx context.Context, config models.ProviderConfig) (*Gitl
 	return &GitlabProvider{Client: client, Parameters: parameters, Name: config.Name}, nil
 }
 
-// GrantAccess adds a user to a specified group based on the provider parameters
+// GrantAccess adds a user to a specified group or project based on the provider parameters.
+// Membership expires in GitLab together with the request
 func (a *GitlabProvider) GrantAccess(ctx context.Context, request *models.AccessRequest) error {
 
 	ctx, span := tracing.NewSpanWrapper(ctx, "providers.gitlab.GrantAccess")
 	defer span.End()
 
 	parameters := a.Parameters
+	username := a.username(request)
+
+	if parameters.Project != "" {
+		return a.grantProjectAccess(ctx, request, username)
+	}
 
 	group, err := a.getGroup(ctx)
 	if err != nil {
@@ -63,13 +77,13 @@ func (a *GitlabProvider) GrantAccess(ctx context.Context, request *models.Access
 		return fmt.Errorf("failed to retrieve gitlab group %s: %w", parameters.Group, err)
 	}
 
-	user, err := a.getUser(ctx)
+	user, err := a.getUser(ctx, username)
 	if err != nil {
 		request.SetProviderStatusError(a.Name, parameters.Group, err.Error())
-		return fmt.Errorf("failed to retrieve gitlab user: %s: %w", parameters.Username, err)
+		return fmt.Errorf("failed to retrieve gitlab user: %s: %w", username, err)
 	}
 
-	err = a.addGroupMember(ctx, group, user)
+	err = a.addGroupMember(ctx, group, user, memberExpiry(request))
 	if err != nil {
 		if apiErr, ok := err.(*clientgo.ErrorResponse); ok && apiErr.Response.StatusCode == 409 {
 
@@ -78,7 +92,7 @@ func (a *GitlabProvider) GrantAccess(ctx context.Context, request *models.Access
 			log.Info().
 				Str("Provider", a.Name).
 				Str("AccessRequest", request.Id).
-				Str("username", parameters.Username).
+				Str("username", username).
 				Str("group", parameters.Group).
 				Msg("User already in group")
 			return nil
@@ -87,7 +101,7 @@ func (a *GitlabProvider) GrantAccess(ctx context.Context, request *models.Access
 		// Failed to add user. Update request status
 		request.SetProviderStatusError(a.Name, parameters.Group, err.Error())
 
-		return fmt.Errorf("failed to add user: %s to group: %s: %w", parameters.Username, parameters.Group, err)
+		return fmt.Errorf("failed to add user: %s to group: %s: %w", username, parameters.Group, err)
 	}
 
 	// User added
@@ -95,7 +109,7 @@ func (a *GitlabProvider) GrantAccess(ctx context.Context, request *models.Access
 	log.Info().
 		Str("Provider", a.Name).
 		Str("AccessRequest", request.Id).
-		Str("username", parameters.Username).
+		Str("username", username).
 		Str("group", parameters.Group).
 		Msg("User added to group")
 
@@ -109,6 +123,11 @@ func (a *GitlabProvider) RevokeAccess(ctx context.Context, request *models.Acces
 	defer span.End()
 
 	parameters := a.Parameters
+	username := a.username(request)
+
+	if parameters.Project != "" {
+		return a.revokeProjectAccess(ctx, request, username)
+	}
 
 	group, err := a.getGroup(ctx)
 	if err != nil {
@@ -116,14 +135,14 @@ func (a *GitlabProvider) RevokeAccess(ctx context.Context, request *models.Acces
 		return fmt.Errorf("failed to retrieve gitlab group: %w", err)
 	}
 
-	user, err := a.getUser(ctx)
+	user, err := a.getUser(ctx, username)
 	if err != nil {
 		if errors.Is(err, ErrUserNotFound) {
 			request.SetProviderStatusRevoked(a.Name, parameters.Group, "user does not exist. probably deleted")
 			log.Info().
 				Str("Provider", a.Name).
 				Str("AccessRequest", request.Id).
-				Str("username", parameters.Username).
+				Str("username", username).
 				Str("group", parameters.Group).
 				Msg("User not found, treating as already revoked")
 
@@ -134,7 +153,7 @@ func (a *GitlabProvider) RevokeAccess(ctx context.Context, request *models.Acces
 		return fmt.Errorf("failed to retrieve gitlab user id: %w", err)
 	}
 
-	isMember, err := a.isGroupMember(ctx, group, user)
+	isMember, err := a.isGroupMember(ctx, group, username)
 	if err != nil {
 		request.SetProviderStatusError(a.Name, parameters.Group, err.Error())
 		return fmt.Errorf("failed to check user membership: %w", err)
@@ -145,7 +164,7 @@ func (a *GitlabProvider) RevokeAccess(ctx context.Context, request *models.Acces
 		log.Info().
 			Str("Provider", a.Name).
 			Str("AccessRequest", request.Id).
-			Str("username", parameters.Username).
+			Str("username", username).
 			Str("group", parameters.Group).
 			Msg("User already not in group")
 		return nil
@@ -161,7 +180,7 @@ func (a *GitlabProvider) RevokeAccess(ctx context.Context, request *models.Acces
 	log.Info().
 		Str("Provider", a.Name).
 		Str("AccessRequest", request.Id).
-		Str("username", parameters.Username).
+		Str("username", username).
 		Str("group", parameters.Group).
 		Msgf("User removed from group")
 	return nil
@@ -176,6 +195,10 @@ func (a *GitlabProvider) ListUsersWithAccess(ctx context.Context, roleRef models
 
 	parameters := a.Parameters
 
+	if parameters.Project != "" {
+		return a.listProjectMembers(ctx)
+	}
+
 	group, err := a.getGroup(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse group ID: %w", err)
@@ -196,17 +219,23 @@ func (a *GitlabProvider) ListUsersWithAccess(ctx context.Context, roleRef models
 
 // IsAccessExpired checks whether the access for the given request has expired
 func (a *GitlabProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
-	ttl := request.Details.TTL
-	if ttl == "" {
-		return false, errors.New("TTL not specified in access request")
-	}
+	return request.IsExpired(time.Now())
+}
 
-	// Validate TTL expiration (this assumes TTL is a duration like "24h")
-	expiry, err := time.ParseDuration(ttl)
-	if err != nil {
-		return false, fmt.Errorf("invalid TTL format: %w", err)
+// username returns GitLab username of the requester, falling back to the username parameter
+func (a *GitlabProvider) username(request *models.AccessRequest) string {
+	if username := request.GetProviderUsername(providerType); username != "" {
+		return username
 	}
+	return a.Parameters.Username
+}
 
-	expirationTime := request.CreatedAt.Add(expiry)
-	return time.Now().After(expirationTime), nil
+// memberExpiry returns expires_at for the membership. GitLab expires members by date,
+// so the day after request expiry is used and revoke removes the member on time. Standing requests never expire
+func memberExpiry(request *models.AccessRequest) *string {
+	if request.Status.ExpiresAt == nil {
+		return nil
+	}
+	date := request.Status.ExpiresAt.UTC().AddDate(0, 0, 1).Format(time.DateOnly)
+	return &date
 }
@@ -43,7 +43,7 @@ func (a *GitlabProvider) getGroup(ctx context.Context) (group *clientgo.Group, e
 	return nil, fmt.Errorf("group not found")
 }
 
-func (a *GitlabProvider) getUser(ctx context.Context) (user *clientgo.User, err error) {
+func (a *GitlabProvider) getUser(ctx context.Context, username string) (user *clientgo.User, err error) {
 
 	_, span := tracing.NewSpanWrapper(ctx, "gitlab.getUser")
 	span.SetAttributes(
@@ -52,11 +52,9 @@ func (a *GitlabProvider) getUser(ctx context.Context) (user *clientgo.User, err
 	)
 	defer span.End()
 
-	parameters := a.Parameters
-
 	users, _, err := a.Client.Users.ListUsers(&clientgo.ListUsersOptions{
 		ListOptions: clientgo.ListOptions{},
-		Search:      &a.Parameters.Username,
+		Search:      &username,
 	})
 	if err != nil {
 		return nil, err
@@ -64,14 +62,14 @@ func (a *GitlabProvider) getUser(ctx context.Context) (user *clientgo.User, err
 
 	for _, user := range users {
 		log.Debug().Msgf("found user: %+v", user)
-		if user.Username == parameters.Username {
+		if user.Username == username {
 			return user, nil
 		}
 	}
 	return nil, ErrUserNotFound
 }
 
-func (a *GitlabProvider) addGroupMember(ctx context.Context, group *clientgo.Group, user *clientgo.User) (err error) {
+func (a *GitlabProvider) addGroupMember(ctx context.Context, group *clientgo.Group, user *clientgo.User, expiresAt *string) (err error) {
 
 	_, span := tracing.NewSpanWrapper(ctx, "gitlab.addGroupMember")
 	span.SetAttributes(
@@ -86,7 +84,7 @@ func (a *GitlabProvider) addGroupMember(ctx context.Context, group *clientgo.Gro
 	_, _, err = a.Client.GroupMembers.AddGroupMember(group.ID, &clientgo.AddGroupMemberOptions{
 		Username:    &user.Username,
 		AccessLevel: level,
-		// ExpiresAt:    new(string),
+		ExpiresAt:   expiresAt,
 	})
 	return err
 }
@@ -108,7 +106,7 @@ func (a *GitlabProvider) removeGroupMember(ctx context.Context, group *clientgo.
 	return err
 }
 
-func (a *GitlabProvider) isGroupMember(ctx context.Context, group *clientgo.Group, user *clientgo.User) (isMember bool, err error) {
+func (a *GitlabProvider) isGroupMember(ctx context.Context, group *clientgo.Group, username string) (isMember bool, err error) {
 
 	_, span := tracing.NewSpanWrapper(ctx, "gitlab.isGroupMember")
 	span.SetAttributes(
@@ -121,15 +119,15 @@ func (a *GitlabProvider) isGroupMember(ctx context.Context, group *clientgo.Grou
 
 	isMember = false
 	users, _, err := a.Client.Groups.ListGroupMembers(group.ID, &clientgo.ListGroupMembersOptions{
-		Query: &parameters.Username,
+		Query: &username,
 	})
 	if err != nil {
 		return isMember, fmt.Errorf("failed to retrieve users for group: %s: %w", parameters.Group, err)
 	}
 
 	for _, u := range users {
-		log.Debug().Msgf("%+v", user)
-		if u.Username == parameters.Username {
+		log.Debug().Msgf("%+v", u)
+		if u.Username == username {
 			isMember = true
 			break
 		}
@@ -145,14 +143,18 @@ func extractParameters(config models.ProviderConfig) (GitlabProviderParameters,
 	creds := Config.GetCredentials(config.CredentialRef.Name)
 	token := creds.GetString("token")
 
-	group, ok := data["group"]
-	if !ok {
-		return GitlabProviderParameters{}, errors.New("group not found in provider config")
+	baseURL := creds.GetString("baseurl")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
 	}
 
-	username, ok := data["username"]
-	if !ok {
-		return GitlabProviderParameters{}, errors.New("username not found in provider config")
+	group := data["group"]
+	project := data["project"]
+	if group == "" && project == "" {
+		return GitlabProviderParameters{}, errors.New("group or project not found in provider config")
+	}
+	if group != "" && project != "" {
+		return GitlabProviderParameters{}, errors.New("only one of group or project can be set in provider config")
 	}
 
 	level, ok := data["level"]
@@ -177,8 +179,10 @@ func extractParameters(config models.ProviderConfig) (GitlabProviderParameters,
 
 	return GitlabProviderParameters{
 		Group:    group,
+		Project:  project,
 		Token:    token,
-		Username: username,
+		BaseURL:  baseURL,
+		Username: data["username"],
 		Level:    accessLevel,
 	}, nil
 }
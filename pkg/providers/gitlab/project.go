@@ -0,0 +1,230 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/tracing"
+
+	"github.com/rs/zerolog/log"
+	clientgo "gitlab.com/gitlab-org/api/client-go"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// grantProjectAccess adds a user to the project as a direct member
+func (a *GitlabProvider) grantProjectAccess(ctx context.Context, request *models.AccessRequest, username string) error {
+
+	parameters := a.Parameters
+
+	project, err := a.getProject(ctx)
+	if err != nil {
+		request.SetProviderStatusError(a.Name, parameters.Project, err.Error())
+		return fmt.Errorf("failed to retrieve gitlab project %s: %w", parameters.Project, err)
+	}
+
+	user, err := a.getUser(ctx, username)
+	if err != nil {
+		request.SetProviderStatusError(a.Name, parameters.Project, err.Error())
+		return fmt.Errorf("failed to retrieve gitlab user: %s: %w", username, err)
+	}
+
+	isMember, err := a.isProjectMember(ctx, project, user)
+	if err != nil {
+		request.SetProviderStatusError(a.Name, parameters.Project, err.Error())
+		return fmt.Errorf("failed to check user membership: %w", err)
+	}
+
+	if isMember {
+		request.SetProviderStatusGranted(a.Name, parameters.Project, "already in project")
+		log.Info().
+			Str("Provider", a.Name).
+			Str("AccessRequest", request.Id).
+			Str("username", username).
+			Str("project", parameters.Project).
+			Msg("User already in project")
+		return nil
+	}
+
+	err = a.addProjectMember(ctx, project, user, memberExpiry(request))
+	if err != nil {
+		request.SetProviderStatusError(a.Name, parameters.Project, err.Error())
+		return fmt.Errorf("failed to add user: %s to project: %s: %w", username, parameters.Project, err)
+	}
+
+	request.SetProviderStatusGranted(a.Name, parameters.Project, "")
+	log.Info().
+		Str("Provider", a.Name).
+		Str("AccessRequest", request.Id).
+		Str("username", username).
+		Str("project", parameters.Project).
+		Msg("User added to project")
+
+	return nil
+}
+
+// revokeProjectAccess removes a user from the project
+func (a *GitlabProvider) revokeProjectAccess(ctx context.Context, request *models.AccessRequest, username string) error {
+
+	parameters := a.Parameters
+
+	project, err := a.getProject(ctx)
+	if err != nil {
+		request.SetProviderStatusError(a.Name, parameters.Project, err.Error())
+		return fmt.Errorf("failed to retrieve gitlab project: %w", err)
+	}
+
+	user, err := a.getUser(ctx, username)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			request.SetProviderStatusRevoked(a.Name, parameters.Project, "user does not exist. probably deleted")
+			log.Info().
+				Str("Provider", a.Name).
+				Str("AccessRequest", request.Id).
+				Str("username", username).
+				Str("project", parameters.Project).
+				Msg("User not found, treating as already revoked")
+			return nil
+		}
+
+		request.SetProviderStatusError(a.Name, parameters.Project, err.Error())
+		return fmt.Errorf("failed to retrieve gitlab user id: %w", err)
+	}
+
+	isMember, err := a.isProjectMember(ctx, project, user)
+	if err != nil {
+		request.SetProviderStatusError(a.Name, parameters.Project, err.Error())
+		return fmt.Errorf("failed to check user membership: %w", err)
+	}
+
+	if !isMember {
+		request.SetProviderStatusRevoked(a.Name, parameters.Project, "already removed from project")
+		log.Info().
+			Str("Provider", a.Name).
+			Str("AccessRequest", request.Id).
+			Str("username", username).
+			Str("project", parameters.Project).
+			Msg("User already not in project")
+		return nil
+	}
+
+	err = a.removeProjectMember(ctx, project, user)
+	if err != nil {
+		request.SetProviderStatusError(a.Name, parameters.Project, err.Error())
+		return fmt.Errorf("failed to remove user from project: %w", err)
+	}
+
+	request.SetProviderStatusRevoked(a.Name, parameters.Project, "")
+	log.Info().
+		Str("Provider", a.Name).
+		Str("AccessRequest", request.Id).
+		Str("username", username).
+		Str("project", parameters.Project).
+		Msg("User removed from project")
+	return nil
+}
+
+func (a *GitlabProvider) getProject(ctx context.Context) (project *clientgo.Project, err error) {
+
+	_, span := tracing.NewSpanWrapper(ctx, "gitlab.getProject")
+	span.SetAttributes(
+		attribute.String("peer.service", "gitlab"),
+		attribute.String("span.kind", "client"),
+	)
+	defer span.End()
+
+	project, _, err = a.Client.Projects.GetProject(a.Parameters.Project, &clientgo.GetProjectOptions{})
+	return project, err
+}
+
+func (a *GitlabProvider) addProjectMember(ctx context.Context, project *clientgo.Project, user *clientgo.User, expiresAt *string) (err error) {
+
+	_, span := tracing.NewSpanWrapper(ctx, "gitlab.addProjectMember")
+	span.SetAttributes(
+		attribute.String("peer.service", "gitlab"),
+		attribute.String("span.kind", "client"),
+	)
+	defer span.End()
+
+	_, _, err = a.Client.ProjectMembers.AddProjectMember(project.ID, &clientgo.AddProjectMemberOptions{
+		UserID:      user.ID,
+		AccessLevel: clientgo.Ptr(a.Parameters.Level),
+		ExpiresAt:   expiresAt,
+	})
+	return err
+}
+
+func (a *GitlabProvider) removeProjectMember(ctx context.Context, project *clientgo.Project, user *clientgo.User) (err error) {
+
+	_, span := tracing.NewSpanWrapper(ctx, "gitlab.removeProjectMember")
+	span.SetAttributes(
+		attribute.String("peer.service", "gitlab"),
+		attribute.String("span.kind", "client"),
+	)
+	defer span.End()
+
+	_, err = a.Client.ProjectMembers.DeleteProjectMember(project.ID, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to remove user from project: %w", err)
+	}
+
+	return nil
+}
+
+// isProjectMember checks direct project membership. Members inherited from groups are not managed here
+func (a *GitlabProvider) isProjectMember(ctx context.Context, project *clientgo.Project, user *clientgo.User) (isMember bool, err error) {
+
+	_, span := tracing.NewSpanWrapper(ctx, "gitlab.isProjectMember")
+	span.SetAttributes(
+		attribute.String("peer.service", "gitlab"),
+		attribute.String("span.kind", "client"),
+	)
+	defer span.End()
+
+	_, resp, err := a.Client.ProjectMembers.GetProjectMember(project.ID, user.ID)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to retrieve member for project: %s: %w", a.Parameters.Project, err)
+	}
+	return true, nil
+}
+
+// listProjectMembers lists usernames of direct project members
+func (a *GitlabProvider) listProjectMembers(ctx context.Context) ([]string, error) {
+
+	_, span := tracing.NewSpanWrapper(ctx, "gitlab.listProjectMembers")
+	span.SetAttributes(
+		attribute.String("peer.service", "gitlab"),
+		attribute.String("span.kind", "client"),
+	)
+	defer span.End()
+
+	project, err := a.getProject(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve gitlab project: %w", err)
+	}
+
+	usernames := []string{}
+	options := &clientgo.ListProjectMembersOptions{ListOptions: clientgo.ListOptions{PerPage: 100}}
+	for {
+		members, resp, err := a.Client.ProjectMembers.ListProjectMembers(project.ID, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list project members: %w", err)
+		}
+
+		for _, member := range members {
+			usernames = append(usernames, member.Username)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		options.Page = resp.NextPage
+	}
+
+	return usernames, nil
+}
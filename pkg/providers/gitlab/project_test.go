@@ -0,0 +1,151 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gitlabAPI fakes GitLab REST API for a single user, group and project
+type gitlabAPI struct {
+	members map[string]map[string]any // members by "groups/5" or "projects/7"
+}
+
+func (g *gitlabAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reply := func(status int, body any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}
+
+	path := r.URL.EscapedPath()
+	switch {
+	case r.Method == http.MethodGet && path == "/api/v4/users":
+		reply(http.StatusOK, []map[string]any{{"id": 3, "username": "alice"}})
+	case r.Method == http.MethodGet && path == "/api/v4/groups":
+		reply(http.StatusOK, []map[string]any{{"id": 5, "full_path": "exampleorg/sre"}})
+	case r.Method == http.MethodGet && path == "/api/v4/projects/exampleorg%2Fapi":
+		reply(http.StatusOK, map[string]any{"id": 7, "path_with_namespace": "exampleorg/api"})
+	case r.Method == http.MethodGet && path == "/api/v4/projects/7/members/3":
+		if member, ok := g.members["projects/7"]; ok {
+			reply(http.StatusOK, member)
+			return
+		}
+		reply(http.StatusNotFound, map[string]any{"message": "404 Not found"})
+	case r.Method == http.MethodGet && path == "/api/v4/projects/7/members":
+		members := []map[string]any{}
+		if member, ok := g.members["projects/7"]; ok {
+			members = append(members, member)
+		}
+		reply(http.StatusOK, members)
+	case r.Method == http.MethodPost && (path == "/api/v4/projects/7/members" || path == "/api/v4/groups/5/members"):
+		var member map[string]any
+		json.NewDecoder(r.Body).Decode(&member)
+		member["username"] = "alice"
+		g.members[path[len("/api/v4/"):len(path)-len("/members")]] = member
+		reply(http.StatusCreated, member)
+	case r.Method == http.MethodDelete && path == "/api/v4/projects/7/members/3":
+		delete(g.members, "projects/7")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		reply(http.StatusNotFound, map[string]any{"message": "404 Not found"})
+	}
+}
+
+func testProvider(t *testing.T, parameters map[string]string) (*GitlabProvider, *gitlabAPI) {
+	api := &gitlabAPI{members: map[string]map[string]any{}}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	Config.Creds = map[string]models.Credential{"gitlab": {Name: "gitlab", Data: map[string]string{
+		"token":   "glpat-test",
+		"baseurl": server.URL,
+	}}}
+
+	p, err := NewGitlabProvider(context.Background(), models.ProviderConfig{
+		Name:          "Gitlab",
+		Provider:      providerType,
+		CredentialRef: models.CredentialRef{Name: "gitlab"},
+		Parameters:    parameters,
+	})
+	require.NoError(t, err)
+	return p, api
+}
+
+func testRequest() *models.AccessRequest {
+	expires := time.Date(2026, 3, 14, 18, 30, 0, 0, time.UTC)
+	request := &models.AccessRequest{Id: "req-1"}
+	request.Status.ExpiresAt = &expires
+	request.Status.ProviderUsernames = map[string]string{providerType: "alice"}
+	return request
+}
+
+func TestProjectAccess(t *testing.T) {
+	p, api := testProvider(t, map[string]string{"project": "exampleorg/api", "level": "Developer"})
+
+	request := testRequest()
+	require.NoError(t, p.GrantAccess(context.Background(), request))
+	assert.Equal(t, models.ProviderStatus{Action: models.ProviderStatusGranted, Details: "exampleorg/api"}, request.Status.ProviderStatuses["Gitlab"])
+
+	member := api.members["projects/7"]
+	require.NotNil(t, member)
+	assert.Equal(t, float64(3), member["user_id"])
+	assert.Equal(t, float64(30), member["access_level"])
+	assert.Equal(t, "2026-03-15", member["expires_at"])
+
+	require.NoError(t, p.GrantAccess(context.Background(), request))
+	assert.Equal(t, "already in project", request.Status.ProviderStatuses["Gitlab"].Error)
+
+	users, err := p.ListUsersWithAccess(context.Background(), models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, users)
+
+	require.NoError(t, p.RevokeAccess(context.Background(), request))
+	assert.Equal(t, models.ProviderStatus{Action: models.ProviderStatusRevoked, Details: "exampleorg/api"}, request.Status.ProviderStatuses["Gitlab"])
+	assert.Empty(t, api.members)
+
+	require.NoError(t, p.RevokeAccess(context.Background(), request))
+	assert.Equal(t, "already removed from project", request.Status.ProviderStatuses["Gitlab"].Error)
+}
+
+func TestGroupAccessExpiry(t *testing.T) {
+	p, api := testProvider(t, map[string]string{"group": "exampleorg/sre", "level": "Reporter"})
+
+	request := testRequest()
+	require.NoError(t, p.GrantAccess(context.Background(), request))
+	assert.Equal(t, "2026-03-15", api.members["groups/5"]["expires_at"])
+	assert.Equal(t, float64(20), api.members["groups/5"]["access_level"])
+
+	// Standing access has no expiry
+	request.Status.ExpiresAt = nil
+	require.NoError(t, p.GrantAccess(context.Background(), request))
+	assert.Nil(t, api.members["groups/5"]["expires_at"])
+}
+
+func TestExtractParameters(t *testing.T) {
+	Config.Creds = map[string]models.Credential{"gitlab": {Name: "gitlab", Data: map[string]string{"token": "glpat-test"}}}
+
+	parameters, err := extractParameters(models.ProviderConfig{
+		CredentialRef: models.CredentialRef{Name: "gitlab"},
+		Parameters:    map[string]string{"group": "exampleorg/sre", "level": "Owner"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, defaultBaseURL, parameters.BaseURL)
+
+	for _, data := range []map[string]string{
+		{"level": "Owner"},
+		{"group": "exampleorg/sre", "project": "exampleorg/api", "level": "Owner"},
+		{"project": "exampleorg/api"},
+	} {
+		_, err := extractParameters(models.ProviderConfig{CredentialRef: models.CredentialRef{Name: "gitlab"}, Parameters: data})
+		assert.Error(t, err, data)
+	}
+}
@@ -0,0 +1,33 @@
+package gitlab
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:             string(kinds.ProviderKindGitlab),
+		RequiresUsername: true,
+		Description:      "Manages Gitlab group or project membership. Membership expires together with the request",
+		Parameters: []registry.Parameter{
+			{Name: "group", Description: "Full path of the group. Either group or project is required", Example: "exampleorg/pu-group"},
+			{Name: "project", Description: "Full path of the project. Either group or project is required", Example: "exampleorg/payments-api"},
+			{Name: "level", Description: "Access level name", Example: "Developer"},
+			{Name: "username", Description: "Gitlab username used when the requester has none set"},
+		},
+		Credentials: []registry.Parameter{
+			{Name: "token", Description: "Gitlab access token", Required: true},
+			{Name: "baseurl", Description: "API base URL of self-managed Gitlab", Example: "https://gitlab.exampleorg.com/api/v4"},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewGitlabProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
@@ -2,7 +2,6 @@ package google
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"time"
 
@@ -173,17 +172,5 @@ func (g *GoogleProvider) ListUsersWithAccess(ctx context.Context, roleRef models
 
 // IsAccessExpired checks whether the access for the given request has expired
 func (g *GoogleProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
-	ttl := request.Details.TTL
-	if ttl == "" {
-		return false, errors.New("TTL not specified in access request")
-	}
-
-	// Validate TTL expiration (this assumes TTL is a duration like "24h")
-	expiry, err := time.ParseDuration(ttl)
-	if err != nil {
-		return false, fmt.Errorf("invalid TTL format: %w", err)
-	}
-
-	expirationTime := request.CreatedAt.Add(expiry)
-	return time.Now().After(expirationTime), nil
+	return request.IsExpired(time.Now())
 }
@@ -0,0 +1,29 @@
+package google
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:             string(kinds.ProviderKindGoogle),
+		RequiresUsername: true,
+		Description:      "Manages Google Workspace group membership",
+		Parameters: []registry.Parameter{
+			{Name: "group", Description: "Group email", Required: true, Example: "ro-access@exampleorg.com"},
+		},
+		Credentials: []registry.Parameter{
+			{Name: "credentialsfile", Description: "Path to service account credentials file", Required: true, Example: "/secrets/google.json"},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewGoogleProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
@@ -10,14 +10,18 @@ const (
 	ProviderKindTeleport   ProviderKind = "teleport"
 	ProviderKindAWS        ProviderKind = "aws"
 	ProviderKindCloudflare ProviderKind = "cloudflare"
+	ProviderKindAtlassian  ProviderKind = "atlassian"
+	ProviderKindPlugin     ProviderKind = "plugin"
+	ProviderKindWebhook    ProviderKind = "webhook"
+	ProviderKindExec       ProviderKind = "exec"
+	ProviderKindKubernetes ProviderKind = "kubernetes"
+	ProviderKindPostgres   ProviderKind = "postgres"
+	ProviderKindMySQL      ProviderKind = "mysql"
+	ProviderKindLDAP       ProviderKind = "ldap"
+	ProviderKindSCIM       ProviderKind = "scim"
+	ProviderKindOkta       ProviderKind = "okta"
+	ProviderKindEntra      ProviderKind = "entra"
+	ProviderKindVault      ProviderKind = "vault"
+	ProviderKindAWSSTS     ProviderKind = "awssts"
+	ProviderKindSSHCA      ProviderKind = "sshca"
 )
-
-var AllProviderKinds = []ProviderKind{
-	ProviderKindMock,
-	ProviderKindGitlab,
-	ProviderKindGithub,
-	ProviderKindGoogle,
-	ProviderKindTeleport,
-	ProviderKindAWS,
-	ProviderKindCloudflare,
-}
@@ -0,0 +1,363 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/config"
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/tracing"
+
+	"github.com/rs/zerolog/log"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const providerType = string(kinds.ProviderKindKubernetes)
+
+const (
+	labelManagedBy     = "app.kubernetes.io/managed-by"
+	labelAccessRequest = "passage.io/access-request"
+
+	annotationProvider = "passage.io/provider"
+	annotationRole     = "passage.io/role"
+	annotationUsername = "passage.io/username"
+
+	managedBy = "passage"
+)
+
+var (
+	invalidNameChars  = regexp.MustCompile(`[^a-z0-9-]+`)
+	invalidLabelChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+var Config = config.GetConfig()
+
+// KubernetesProvider manages RoleBindings and ClusterRoleBindings for access requests
+type KubernetesProvider struct {
+	client kubernetes.Interface
+
+	// provider name as defined in the provider configuration
+	name string
+	// ClusterRole or Role referenced by created bindings
+	role     string
+	roleKind string
+	// RoleBinding namespace. ClusterRoleBinding is created when empty
+	namespace string
+	// User or Group
+	subjectKind string
+}
+
+// NewKubernetesProvider initializes a new KubernetesProvider with kubeconfig or in-cluster credentials from ProviderConfig
+func NewKubernetesProvider(ctx context.Context, config models.ProviderConfig) (*KubernetesProvider, error) {
+	creds := Config.GetCredentials(config.CredentialRef.Name)
+
+	restConfig, err := restConfig(creds, config.Parameters["context"])
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	return newKubernetesProvider(config, client)
+}
+
+func newKubernetesProvider(config models.ProviderConfig, client kubernetes.Interface) (*KubernetesProvider, error) {
+	data := config.Parameters
+
+	p := &KubernetesProvider{
+		client:      client,
+		name:        config.Name,
+		role:        data["role"],
+		roleKind:    data["roleKind"],
+		namespace:   data["namespace"],
+		subjectKind: data["subjectKind"],
+	}
+
+	if p.role == "" {
+		return nil, errors.New("role not found in provider config")
+	}
+	if p.roleKind == "" {
+		p.roleKind = "ClusterRole"
+	}
+	if p.subjectKind == "" {
+		p.subjectKind = rbacv1.UserKind
+	}
+
+	if p.roleKind != "ClusterRole" && p.roleKind != "Role" {
+		return nil, fmt.Errorf("invalid roleKind %s. Expected ClusterRole or Role", p.roleKind)
+	}
+	if p.roleKind == "Role" && p.namespace == "" {
+		return nil, errors.New("namespace is required for roleKind Role")
+	}
+	if p.subjectKind != rbacv1.UserKind && p.subjectKind != rbacv1.GroupKind {
+		return nil, fmt.Errorf("invalid subjectKind %s. Expected User or Group", p.subjectKind)
+	}
+
+	return p, nil
+}
+
+// restConfig loads kubeconfig from credentials. In-cluster config is used when no kubeconfig is set
+func restConfig(creds models.Credential, kubeContext string) (*rest.Config, error) {
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+
+	if data := creds.GetString("kubeconfigdata"); data != "" {
+		clientConfig, err := clientcmd.Load([]byte(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse kubeconfigdata: %w", err)
+		}
+		return clientcmd.NewDefaultClientConfig(*clientConfig, overrides).ClientConfig()
+	}
+
+	if path := creds.GetString("kubeconfig"); path != "" {
+		return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: path},
+			overrides,
+		).ClientConfig()
+	}
+
+	restConfig, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig not found in credentials and in-cluster config is unavailable: %w", err)
+	}
+	return restConfig, nil
+}
+
+// GrantAccess creates binding labelled with access request ID
+func (p *KubernetesProvider) GrantAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "GrantAccess")
+	defer span.End()
+
+	username := request.GetProviderUsername(providerType)
+	if username == "" {
+		err := errors.New("kubernetes username is not set")
+		request.SetProviderStatusError(p.name, p.role, err.Error())
+		return err
+	}
+
+	meta := p.objectMeta(request, username)
+	roleRef := rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: p.roleKind, Name: p.role}
+	subjects := []rbacv1.Subject{{APIGroup: rbacv1.GroupName, Kind: p.subjectKind, Name: username}}
+
+	var err error
+	if p.namespace != "" {
+		_, err = p.client.RbacV1().RoleBindings(p.namespace).Create(ctx, &rbacv1.RoleBinding{
+			ObjectMeta: meta,
+			RoleRef:    roleRef,
+			Subjects:   subjects,
+		}, metav1.CreateOptions{})
+	} else {
+		_, err = p.client.RbacV1().ClusterRoleBindings().Create(ctx, &rbacv1.ClusterRoleBinding{
+			ObjectMeta: meta,
+			RoleRef:    roleRef,
+			Subjects:   subjects,
+		}, metav1.CreateOptions{})
+	}
+
+	if apierrors.IsAlreadyExists(err) {
+		request.SetProviderStatusGranted(p.name, p.bindingDetails(meta.Name), "binding already exists")
+		return nil
+	}
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.bindingDetails(meta.Name), err.Error())
+		return fmt.Errorf("failed to create binding: %w", err)
+	}
+
+	request.SetProviderStatusGranted(p.name, p.bindingDetails(meta.Name), "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", username).
+		Str("Binding", p.bindingDetails(meta.Name)).
+		Msg("Binding created")
+
+	return nil
+}
+
+// RevokeAccess deletes bindings labelled with access request ID
+func (p *KubernetesProvider) RevokeAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "RevokeAccess")
+	defer span.End()
+
+	selector := labels.Set{labelAccessRequest: labelValue(request.Id)}.String()
+
+	bindings, err := p.listBindings(ctx, selector)
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.role, err.Error())
+		return fmt.Errorf("failed to list bindings: %w", err)
+	}
+
+	names := []string{}
+	for _, binding := range bindings {
+		names = append(names, binding.name)
+	}
+
+	if len(names) == 0 {
+		request.SetProviderStatusRevoked(p.name, p.role, "binding already removed")
+		return nil
+	}
+
+	for _, name := range names {
+		if err := p.deleteBinding(ctx, name); err != nil && !apierrors.IsNotFound(err) {
+			request.SetProviderStatusError(p.name, p.bindingDetails(name), err.Error())
+			return fmt.Errorf("failed to delete binding: %w", err)
+		}
+	}
+
+	request.SetProviderStatusRevoked(p.name, p.bindingDetails(strings.Join(names, ",")), "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Strs("Bindings", names).
+		Msg("Bindings deleted")
+
+	return nil
+}
+
+// ListUsersWithAccess lists subjects of passage managed bindings referencing provider role
+func (p *KubernetesProvider) ListUsersWithAccess(ctx context.Context, roleRef models.AccessRoleRef) ([]string, error) {
+	ctx, span := startSpan(ctx, "ListUsersWithAccess")
+	defer span.End()
+
+	bindings, err := p.listBindings(ctx, labels.Set{labelManagedBy: managedBy}.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bindings: %w", err)
+	}
+
+	usernames := []string{}
+	seen := map[string]bool{}
+	for _, binding := range bindings {
+		for _, subject := range binding.subjects {
+			if subject.Kind != p.subjectKind || seen[subject.Name] {
+				continue
+			}
+			seen[subject.Name] = true
+			usernames = append(usernames, subject.Name)
+		}
+	}
+
+	return usernames, nil
+}
+
+// IsRequestScoped reports that bindings are found by access request label
+func (p *KubernetesProvider) IsRequestScoped() bool {
+	return true
+}
+
+// IsAccessExpired checks whether the access for the given request has expired
+func (p *KubernetesProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
+	_, span := startSpan(ctx, "IsAccessExpired")
+	defer span.End()
+
+	return request.IsExpired(time.Now())
+}
+
+func (p *KubernetesProvider) objectMeta(request *models.AccessRequest, username string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      bindingName(request.Id, p.role),
+		Namespace: p.namespace,
+		Labels: map[string]string{
+			labelManagedBy:     managedBy,
+			labelAccessRequest: labelValue(request.Id),
+		},
+		Annotations: map[string]string{
+			annotationProvider: p.name,
+			annotationRole:     request.RoleRef.Name,
+			annotationUsername: username,
+		},
+	}
+}
+
+// binding is a RoleBinding or ClusterRoleBinding
+type binding struct {
+	name     string
+	subjects []rbacv1.Subject
+}
+
+// listBindings lists bindings matching selector that reference provider role
+func (p *KubernetesProvider) listBindings(ctx context.Context, selector string) ([]binding, error) {
+	options := metav1.ListOptions{LabelSelector: selector}
+	bindings := []binding{}
+
+	if p.namespace != "" {
+		list, err := p.client.RbacV1().RoleBindings(p.namespace).List(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			if p.referencesRole(item.RoleRef) {
+				bindings = append(bindings, binding{name: item.Name, subjects: item.Subjects})
+			}
+		}
+		return bindings, nil
+	}
+
+	list, err := p.client.RbacV1().ClusterRoleBindings().List(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range list.Items {
+		if p.referencesRole(item.RoleRef) {
+			bindings = append(bindings, binding{name: item.Name, subjects: item.Subjects})
+		}
+	}
+	return bindings, nil
+}
+
+func (p *KubernetesProvider) referencesRole(roleRef rbacv1.RoleRef) bool {
+	return roleRef.Kind == p.roleKind && roleRef.Name == p.role
+}
+
+func (p *KubernetesProvider) deleteBinding(ctx context.Context, name string) error {
+	if p.namespace != "" {
+		return p.client.RbacV1().RoleBindings(p.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	}
+	return p.client.RbacV1().ClusterRoleBindings().Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+func (p *KubernetesProvider) bindingDetails(name string) string {
+	if p.namespace != "" {
+		return fmt.Sprintf("%s/%s", p.namespace, name)
+	}
+	return name
+}
+
+// bindingName derives DNS compatible binding name from request ID and role. Long role names are truncated
+// so the request ID, which revoke relies on, is always kept whole
+func bindingName(requestId string, role string) string {
+	suffix := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(requestId), "-"), "-")
+	prefix := invalidNameChars.ReplaceAllString(strings.ToLower("passage-"+role), "-")
+	if limit := max(253-len(suffix)-1, 0); len(prefix) > limit {
+		prefix = prefix[:limit]
+	}
+	return strings.Trim(prefix, "-") + "-" + suffix
+}
+
+// labelValue sanitizes value to be used as label value
+func labelValue(value string) string {
+	value = invalidLabelChars.ReplaceAllString(value, "-")
+	if len(value) > 63 {
+		value = value[:63]
+	}
+	return strings.Trim(value, "-_.")
+}
+
+func startSpan(ctx context.Context, name string) (context.Context, *tracing.SpanWrapper) {
+	ctx, span := tracing.NewSpanWrapper(ctx, fmt.Sprintf("providers.kubernetes.%s", name))
+	return ctx, span
+}
@@ -0,0 +1,137 @@
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testProvider(t *testing.T, parameters map[string]string) (*KubernetesProvider, *fake.Clientset) {
+	client := fake.NewClientset()
+	p, err := newKubernetesProvider(models.ProviderConfig{
+		Name:       "K8sEdit",
+		Provider:   "kubernetes",
+		Parameters: parameters,
+	}, client)
+	require.NoError(t, err)
+	return p, client
+}
+
+func testRequest(id string, username string) *models.AccessRequest {
+	request := &models.AccessRequest{Id: id}
+	request.RoleRef.Name = "Payments Edit"
+	request.Status.ProviderUsernames = map[string]string{providerType: username}
+	return request
+}
+
+func TestRoleBinding(t *testing.T) {
+	ctx := context.Background()
+	p, client := testProvider(t, map[string]string{"role": "edit", "namespace": "payments"})
+
+	request := testRequest("2f1c4f9e-0d5e-4b8e-9a57-000000000001", "alice@example.com")
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusGranted, request.Status.ProviderStatuses["K8sEdit"].Action)
+
+	bindings, err := client.RbacV1().RoleBindings("payments").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, bindings.Items, 1)
+
+	binding := bindings.Items[0]
+	assert.Equal(t, "passage-edit-2f1c4f9e-0d5e-4b8e-9a57-000000000001", binding.Name)
+	assert.Equal(t, request.Id, binding.Labels[labelAccessRequest])
+	assert.Equal(t, "Payments Edit", binding.Annotations[annotationRole])
+	assert.Equal(t, rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "edit"}, binding.RoleRef)
+	assert.Equal(t, []rbacv1.Subject{{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "alice@example.com"}}, binding.Subjects)
+
+	// Granting twice is idempotent
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, "binding already exists", request.Status.ProviderStatuses["K8sEdit"].Error)
+
+	other := testRequest("2f1c4f9e-0d5e-4b8e-9a57-000000000002", "bob@example.com")
+	require.NoError(t, p.GrantAccess(ctx, other))
+
+	users, err := p.ListUsersWithAccess(ctx, models.AccessRoleRef{Name: "Payments Edit"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"alice@example.com", "bob@example.com"}, users)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusRevoked, request.Status.ProviderStatuses["K8sEdit"].Action)
+
+	users, err = p.ListUsersWithAccess(ctx, models.AccessRoleRef{Name: "Payments Edit"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob@example.com"}, users)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, "binding already removed", request.Status.ProviderStatuses["K8sEdit"].Error)
+}
+
+func TestClusterRoleBinding(t *testing.T) {
+	ctx := context.Background()
+	p, client := testProvider(t, map[string]string{"role": "view", "subjectKind": "Group"})
+
+	request := testRequest("req-1", "sre-oncall")
+	require.NoError(t, p.GrantAccess(ctx, request))
+
+	bindings, err := client.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, bindings.Items, 1)
+	assert.Equal(t, rbacv1.GroupKind, bindings.Items[0].Subjects[0].Kind)
+
+	// Bindings not managed by passage are ignored
+	_, err = client.RbacV1().ClusterRoleBindings().Create(ctx, &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "manual"},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "view"},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "everyone"}},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	users, err := p.ListUsersWithAccess(ctx, models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sre-oncall"}, users)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	bindings, err = client.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, bindings.Items, 1)
+	assert.Equal(t, "manual", bindings.Items[0].Name)
+}
+
+func TestMissingUsername(t *testing.T) {
+	p, _ := testProvider(t, map[string]string{"role": "view"})
+
+	request := testRequest("req-1", "")
+	assert.Error(t, p.GrantAccess(context.Background(), request))
+	assert.Equal(t, models.ProviderStatusError, request.Status.ProviderStatuses["K8sEdit"].Action)
+}
+
+func TestConfigErrors(t *testing.T) {
+	client := fake.NewClientset()
+	for _, parameters := range []map[string]string{
+		{},
+		{"role": "edit", "roleKind": "Other"},
+		{"role": "edit", "roleKind": "Role"},
+		{"role": "edit", "subjectKind": "ServiceAccount"},
+	} {
+		_, err := newKubernetesProvider(models.ProviderConfig{Parameters: parameters}, client)
+		assert.Error(t, err, parameters)
+	}
+}
+
+func TestBindingName(t *testing.T) {
+	assert.Equal(t, "passage-cluster-admin-abc", bindingName("ABC", "cluster:admin"))
+	assert.Equal(t, "a-b", labelValue("a b"))
+
+	// Long role names are truncated, request id is kept whole
+	requestId := "3b7af992-5a30-4ce1-821b-cac8194a230b"
+	name := bindingName(requestId, strings.Repeat("role", 100))
+	assert.Len(t, name, 253)
+	assert.True(t, strings.HasSuffix(name, "-"+requestId))
+}
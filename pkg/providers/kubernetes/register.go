@@ -0,0 +1,34 @@
+package kubernetes
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:             string(kinds.ProviderKindKubernetes),
+		RequiresUsername: true,
+		Description:      "Creates RoleBinding or ClusterRoleBinding labelled with access request ID",
+		Parameters: []registry.Parameter{
+			{Name: "role", Description: "ClusterRole or Role to bind", Required: true, Example: "edit"},
+			{Name: "roleKind", Description: "ClusterRole or Role. Defaults to ClusterRole", Example: "ClusterRole"},
+			{Name: "namespace", Description: "RoleBinding namespace. ClusterRoleBinding is created when empty", Example: "payments"},
+			{Name: "subjectKind", Description: "User or Group. Defaults to User", Example: "User"},
+			{Name: "context", Description: "Kubeconfig context", Example: "prod"},
+		},
+		Credentials: []registry.Parameter{
+			{Name: "kubeconfig", Description: "Path to kubeconfig file. In-cluster credentials are used when neither kubeconfig nor kubeconfigdata is set", Example: "creds/kubeconfig"},
+			{Name: "kubeconfigdata", Description: "Inline kubeconfig"},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewKubernetesProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
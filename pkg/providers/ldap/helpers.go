@@ -0,0 +1,130 @@
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+var errUserNotFound = errors.New("user not found")
+
+// userFilter replaces username placeholder with escaped username
+func userFilter(filter string, username string) string {
+	return strings.ReplaceAll(filter, usernamePlaceholder, ldap.EscapeFilter(username))
+}
+
+// findUser resolves username to a DN with the user search filter
+func (p *LDAPProvider) findUser(conn *ldap.Conn, username string) (string, error) {
+	if username == "" {
+		return "", errors.New("ldap username is not set")
+	}
+
+	search := ldap.NewSearchRequest(
+		p.baseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, int(p.timeout.Seconds()), false,
+		userFilter(p.userFilter, username), []string{"1.1"}, nil,
+	)
+	result, err := conn.Search(search)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return "", fmt.Errorf("failed to search user: %w", err)
+	}
+
+	switch {
+	case result == nil || len(result.Entries) == 0:
+		return "", fmt.Errorf("%w: %s", errUserNotFound, username)
+	case len(result.Entries) > 1:
+		return "", fmt.Errorf("user filter matched multiple entries for %s", username)
+	}
+
+	return result.Entries[0].DN, nil
+}
+
+// isMember checks membership with an equality filter, so the server applies DN matching rules
+func (p *LDAPProvider) isMember(conn *ldap.Conn, userDN string) (bool, error) {
+	search := ldap.NewSearchRequest(
+		p.group, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 1, int(p.timeout.Seconds()), false,
+		fmt.Sprintf("(%s=%s)", p.memberAttribute, ldap.EscapeFilter(userDN)), []string{"1.1"}, nil,
+	)
+	result, err := conn.Search(search)
+	if err != nil {
+		return false, err
+	}
+	return len(result.Entries) > 0, nil
+}
+
+// groupMembers reads member DNs of the group.
+// Active Directory returns large groups in ranges, e.g. member;range=0-1499, which are followed until the last one
+func (p *LDAPProvider) groupMembers(conn *ldap.Conn) ([]string, error) {
+	members := []string{}
+	attribute := p.memberAttribute
+
+	for {
+		search := ldap.NewSearchRequest(
+			p.group, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 1, int(p.timeout.Seconds()), false,
+			"(objectClass=*)", []string{attribute}, nil,
+		)
+		result, err := conn.Search(search)
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Entries) == 0 {
+			return nil, fmt.Errorf("group %s not found", p.group)
+		}
+
+		next := ""
+		for _, attr := range result.Entries[0].Attributes {
+			if strings.EqualFold(attr.Name, p.memberAttribute) {
+				members = append(members, attr.Values...)
+				continue
+			}
+			high, ok := rangeEnd(attr.Name, p.memberAttribute)
+			if !ok {
+				continue
+			}
+			members = append(members, attr.Values...)
+			if high != "*" {
+				end, err := strconv.Atoi(high)
+				if err != nil {
+					return nil, fmt.Errorf("invalid range attribute %s", attr.Name)
+				}
+				next = fmt.Sprintf("%s;range=%d-*", p.memberAttribute, end+1)
+			}
+		}
+
+		if next == "" {
+			return members, nil
+		}
+		attribute = next
+	}
+}
+
+// rangeEnd returns the upper bound of a ranged attribute, e.g. "1499" for member;range=0-1499 and "*" for the last range
+func rangeEnd(name string, attribute string) (string, bool) {
+	prefix := strings.ToLower(attribute + ";range=")
+	if !strings.HasPrefix(strings.ToLower(name), prefix) {
+		return "", false
+	}
+	_, high, found := strings.Cut(name[len(prefix):], "-")
+	return high, found
+}
+
+// username reads username attribute of an entry
+func (p *LDAPProvider) username(conn *ldap.Conn, dn string) (string, error) {
+	search := ldap.NewSearchRequest(
+		dn, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 1, int(p.timeout.Seconds()), false,
+		"(objectClass=*)", []string{p.usernameAttribute}, nil,
+	)
+	result, err := conn.Search(search)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(result.Entries) == 0 {
+		return "", nil
+	}
+	return result.Entries[0].GetAttributeValue(p.usernameAttribute), nil
+}
@@ -0,0 +1,334 @@
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/config"
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/tracing"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/rs/zerolog/log"
+)
+
+const providerType = string(kinds.ProviderKindLDAP)
+
+// Placeholder replaced with escaped username in userFilter
+const usernamePlaceholder = "{username}"
+
+const (
+	defaultUserFilter        = "(uid={username})"
+	defaultMemberAttribute   = "member"
+	defaultUsernameAttribute = "uid"
+	defaultTimeout           = 30 * time.Second
+)
+
+var Config = config.GetConfig()
+
+// LDAPProvider manages LDAP / Active Directory group membership
+type LDAPProvider struct {
+	// provider name as defined in the provider configuration
+	name string
+	// DN of the managed group
+	group string
+	// base DN of user search
+	baseDN string
+	// user search filter with {username} placeholder
+	userFilter string
+	// group attribute holding member DNs
+	memberAttribute string
+	// user attribute holding the username
+	usernameAttribute string
+
+	url          string
+	bindDN       string
+	bindPassword string
+	startTLS     bool
+	tlsConfig    *tls.Config
+	timeout      time.Duration
+}
+
+// NewLDAPProvider initializes a new LDAPProvider with connection settings from CredentialRef
+func NewLDAPProvider(ctx context.Context, config models.ProviderConfig) (*LDAPProvider, error) {
+	creds := Config.GetCredentials(config.CredentialRef.Name)
+	data := config.Parameters
+
+	p := &LDAPProvider{
+		name:              config.Name,
+		group:             data["group"],
+		baseDN:            data["baseDN"],
+		userFilter:        data["userFilter"],
+		memberAttribute:   data["memberAttribute"],
+		usernameAttribute: data["usernameAttribute"],
+		url:               creds.GetString("url"),
+		bindDN:            creds.GetString("binddn"),
+		bindPassword:      creds.GetString("bindpassword"),
+		timeout:           defaultTimeout,
+	}
+
+	if p.url == "" {
+		return nil, errors.New("url not found in credentials")
+	}
+	if !strings.HasPrefix(p.url, "ldap://") && !strings.HasPrefix(p.url, "ldaps://") {
+		return nil, fmt.Errorf("invalid url %s. Expected ldap:// or ldaps:// scheme", p.url)
+	}
+	if p.group == "" {
+		return nil, errors.New("group not found in provider config")
+	}
+	if _, err := ldap.ParseDN(p.group); err != nil {
+		return nil, fmt.Errorf("invalid group DN: %w", err)
+	}
+	if p.baseDN == "" {
+		return nil, errors.New("baseDN not found in provider config")
+	}
+
+	if p.userFilter == "" {
+		p.userFilter = defaultUserFilter
+	}
+	if !strings.Contains(p.userFilter, usernamePlaceholder) {
+		return nil, fmt.Errorf("userFilter must contain %s placeholder", usernamePlaceholder)
+	}
+	if _, err := ldap.CompileFilter(userFilter(p.userFilter, "test")); err != nil {
+		return nil, fmt.Errorf("invalid userFilter: %w", err)
+	}
+	if p.memberAttribute == "" {
+		p.memberAttribute = defaultMemberAttribute
+	}
+	if p.usernameAttribute == "" {
+		p.usernameAttribute = defaultUsernameAttribute
+	}
+
+	if value := data["timeout"]; value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+		p.timeout = timeout
+	}
+
+	var err error
+	if value := creds.GetString("starttls"); value != "" {
+		p.startTLS, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid starttls: %w", err)
+		}
+	}
+
+	p.tlsConfig, err = newTLSConfig(creds.GetString("cacertpath"), creds.GetString("insecureskipverify"))
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func newTLSConfig(caCertPath string, insecureSkipVerify string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if insecureSkipVerify != "" {
+		skip, err := strconv.ParseBool(insecureSkipVerify)
+		if err != nil {
+			return nil, fmt.Errorf("invalid insecureskipverify: %w", err)
+		}
+		tlsConfig.InsecureSkipVerify = skip
+	}
+
+	if caCertPath != "" {
+		pem, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates found in CA certificate file")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+// connect dials the server, upgrades the connection with StartTLS when configured and binds
+func (p *LDAPProvider) connect() (*ldap.Conn, error) {
+	dialer := &net.Dialer{Timeout: p.timeout}
+	conn, err := ldap.DialURL(p.url, ldap.DialWithDialer(dialer), ldap.DialWithTLSConfig(p.tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	conn.SetTimeout(p.timeout)
+
+	if p.startTLS {
+		if err := conn.StartTLS(p.tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	if p.bindDN != "" {
+		if err := conn.Bind(p.bindDN, p.bindPassword); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to bind: %w", err)
+		}
+	}
+
+	return conn, nil
+}
+
+// GrantAccess adds user DN to the group
+func (p *LDAPProvider) GrantAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "GrantAccess")
+	defer span.End()
+
+	username := request.GetProviderUsername(providerType)
+
+	conn, err := p.connect()
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.group, err.Error())
+		return err
+	}
+	defer conn.Close()
+
+	userDN, err := p.findUser(conn, username)
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.group, err.Error())
+		return err
+	}
+
+	isMember, err := p.isMember(conn, userDN)
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.group, err.Error())
+		return fmt.Errorf("failed to check group membership: %w", err)
+	}
+
+	if isMember {
+		request.SetProviderStatusGranted(p.name, p.group, "already granted")
+		return nil
+	}
+
+	modify := ldap.NewModifyRequest(p.group, nil)
+	modify.Add(p.memberAttribute, []string{userDN})
+	if err := conn.Modify(modify); err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultAttributeOrValueExists) {
+		request.SetProviderStatusError(p.name, p.group, err.Error())
+		return fmt.Errorf("failed to add group member: %w", err)
+	}
+
+	request.SetProviderStatusGranted(p.name, p.group, "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", username).
+		Str("UserDN", userDN).
+		Str("Group", p.group).
+		Msg("User added to LDAP group")
+
+	return nil
+}
+
+// RevokeAccess removes user DN from the group
+func (p *LDAPProvider) RevokeAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "RevokeAccess")
+	defer span.End()
+
+	username := request.GetProviderUsername(providerType)
+
+	conn, err := p.connect()
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.group, err.Error())
+		return err
+	}
+	defer conn.Close()
+
+	userDN, err := p.findUser(conn, username)
+	if errors.Is(err, errUserNotFound) {
+		request.SetProviderStatusRevoked(p.name, p.group, "user not found")
+		return nil
+	}
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.group, err.Error())
+		return err
+	}
+
+	isMember, err := p.isMember(conn, userDN)
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.group, err.Error())
+		return fmt.Errorf("failed to check group membership: %w", err)
+	}
+
+	if !isMember {
+		request.SetProviderStatusRevoked(p.name, p.group, "already revoked")
+		return nil
+	}
+
+	modify := ldap.NewModifyRequest(p.group, nil)
+	modify.Delete(p.memberAttribute, []string{userDN})
+	if err := conn.Modify(modify); err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchAttribute) {
+		request.SetProviderStatusError(p.name, p.group, err.Error())
+		return fmt.Errorf("failed to remove group member: %w", err)
+	}
+
+	request.SetProviderStatusRevoked(p.name, p.group, "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", username).
+		Str("UserDN", userDN).
+		Str("Group", p.group).
+		Msg("User removed from LDAP group")
+
+	return nil
+}
+
+// ListUsersWithAccess lists usernames of group members. Members without username attribute are skipped
+func (p *LDAPProvider) ListUsersWithAccess(ctx context.Context, roleRef models.AccessRoleRef) ([]string, error) {
+	ctx, span := startSpan(ctx, "ListUsersWithAccess")
+	defer span.End()
+
+	conn, err := p.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	members, err := p.groupMembers(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read group members: %w", err)
+	}
+
+	usernames := []string{}
+	for _, memberDN := range members {
+		username, err := p.username(conn, memberDN)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read member %s: %w", memberDN, err)
+		}
+		if username != "" {
+			usernames = append(usernames, username)
+		}
+	}
+
+	return usernames, nil
+}
+
+// IsAccessExpired checks whether the access for the given request has expired
+func (p *LDAPProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
+	_, span := startSpan(ctx, "IsAccessExpired")
+	defer span.End()
+
+	return request.IsExpired(time.Now())
+}
+
+func startSpan(ctx context.Context, name string) (context.Context, *tracing.SpanWrapper) {
+	ctx, span := tracing.NewSpanWrapper(ctx, fmt.Sprintf("providers.ldap.%s", name))
+	return ctx, span
+}
@@ -0,0 +1,184 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Integration tests run against a local OpenLDAP container, e.g.
+// docker run -p 389:389 -e LDAP_ORGANISATION=Example -e LDAP_DOMAIN=example.org -e LDAP_ADMIN_PASSWORD=admin osixia/openldap
+// PASSAGE_TEST_LDAP_URL=ldap://localhost:389
+const (
+	envTestURL       = "PASSAGE_TEST_LDAP_URL"
+	testBaseDN       = "dc=example,dc=org"
+	testBindDN       = "cn=admin,dc=example,dc=org"
+	testBindPassword = "admin"
+	testOU           = "ou=passage-test,dc=example,dc=org"
+	testGroup        = "cn=vpn-users,ou=passage-test,dc=example,dc=org"
+)
+
+func testProvider(t *testing.T, parameters map[string]string) *LDAPProvider {
+	url := os.Getenv(envTestURL)
+	if url == "" {
+		t.Skipf("%s is not set", envTestURL)
+	}
+
+	Config.Creds = map[string]models.Credential{
+		"ldap": {
+			Name: "ldap",
+			Data: map[string]string{"url": url, "binddn": testBindDN, "bindpassword": testBindPassword},
+		},
+	}
+
+	p, err := NewLDAPProvider(context.Background(), models.ProviderConfig{
+		Name:          "VPNUsers",
+		Provider:      "ldap",
+		CredentialRef: models.CredentialRef{Name: "ldap"},
+		Parameters:    parameters,
+	})
+	require.NoError(t, err)
+	return p
+}
+
+func testRequest(username string) *models.AccessRequest {
+	request := &models.AccessRequest{Id: "req-1"}
+	request.Status.ProviderUsernames = map[string]string{providerType: username}
+	return request
+}
+
+// setup creates test users and a groupOfNames holding the bind account, which has no uid
+func setup(t *testing.T, p *LDAPProvider, usernames ...string) {
+	conn, err := p.connect()
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	entries := []string{testGroup}
+	for _, username := range usernames {
+		entries = append(entries, fmt.Sprintf("uid=%s,%s", username, testOU))
+	}
+	cleanup := func() {
+		for _, dn := range append(entries, testOU) {
+			_ = conn.Del(ldap.NewDelRequest(dn, nil))
+		}
+	}
+	cleanup()
+	t.Cleanup(cleanup)
+
+	ou := ldap.NewAddRequest(testOU, nil)
+	ou.Attribute("objectClass", []string{"organizationalUnit"})
+	ou.Attribute("ou", []string{"passage-test"})
+	require.NoError(t, conn.Add(ou))
+
+	for _, username := range usernames {
+		user := ldap.NewAddRequest(fmt.Sprintf("uid=%s,%s", username, testOU), nil)
+		user.Attribute("objectClass", []string{"inetOrgPerson"})
+		user.Attribute("uid", []string{username})
+		user.Attribute("cn", []string{username})
+		user.Attribute("sn", []string{username})
+		require.NoError(t, conn.Add(user))
+	}
+
+	group := ldap.NewAddRequest(testGroup, nil)
+	group.Attribute("objectClass", []string{"groupOfNames"})
+	group.Attribute("cn", []string{"vpn-users"})
+	group.Attribute("member", []string{testBindDN})
+	require.NoError(t, conn.Add(group))
+}
+
+func TestGrantRevoke(t *testing.T) {
+	ctx := context.Background()
+	p := testProvider(t, map[string]string{"group": testGroup, "baseDN": testBaseDN})
+	setup(t, p, "alice", "bob")
+
+	request := testRequest("alice")
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusGranted, request.Status.ProviderStatuses["VPNUsers"].Action)
+
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, "already granted", request.Status.ProviderStatuses["VPNUsers"].Error)
+
+	users, err := p.ListUsersWithAccess(ctx, models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, users)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusRevoked, request.Status.ProviderStatuses["VPNUsers"].Action)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, "already revoked", request.Status.ProviderStatuses["VPNUsers"].Error)
+
+	users, err = p.ListUsersWithAccess(ctx, models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
+
+func TestGrantUnknownUser(t *testing.T) {
+	ctx := context.Background()
+	p := testProvider(t, map[string]string{"group": testGroup, "baseDN": testBaseDN})
+	setup(t, p)
+
+	request := testRequest("mallory*")
+	assert.ErrorIs(t, p.GrantAccess(ctx, request), errUserNotFound)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, "user not found", request.Status.ProviderStatuses["VPNUsers"].Error)
+}
+
+func TestUserFilter(t *testing.T) {
+	assert.Equal(t, `(uid=alice)`, userFilter(defaultUserFilter, "alice"))
+	assert.Equal(t, `(&(objectClass=user)(sAMAccountName=\2a\29\28uid=\2a))`, userFilter("(&(objectClass=user)(sAMAccountName={username}))", "*)(uid=*"))
+}
+
+func TestRangeEnd(t *testing.T) {
+	high, ok := rangeEnd("member;range=0-1499", "member")
+	assert.True(t, ok)
+	assert.Equal(t, "1499", high)
+
+	high, ok = rangeEnd("Member;Range=1500-*", "member")
+	assert.True(t, ok)
+	assert.Equal(t, "*", high)
+
+	_, ok = rangeEnd("member", "member")
+	assert.False(t, ok)
+}
+
+func TestConfigErrors(t *testing.T) {
+	ctx := context.Background()
+	Config.Creds = map[string]models.Credential{
+		"ldap":  {Name: "ldap", Data: map[string]string{"url": "ldap://localhost:389"}},
+		"http":  {Name: "http", Data: map[string]string{"url": "http://localhost:389"}},
+		"tls":   {Name: "tls", Data: map[string]string{"url": "ldap://localhost:389", "starttls": "maybe"}},
+		"nocas": {Name: "nocas", Data: map[string]string{"url": "ldaps://localhost:636", "cacertpath": "/nonexistent"}},
+	}
+
+	valid := map[string]string{"group": testGroup, "baseDN": testBaseDN}
+	for _, config := range []models.ProviderConfig{
+		{Parameters: valid},
+		{CredentialRef: models.CredentialRef{Name: "http"}, Parameters: valid},
+		{CredentialRef: models.CredentialRef{Name: "tls"}, Parameters: valid},
+		{CredentialRef: models.CredentialRef{Name: "nocas"}, Parameters: valid},
+		{CredentialRef: models.CredentialRef{Name: "ldap"}, Parameters: map[string]string{"baseDN": testBaseDN}},
+		{CredentialRef: models.CredentialRef{Name: "ldap"}, Parameters: map[string]string{"group": "not a dn", "baseDN": testBaseDN}},
+		{CredentialRef: models.CredentialRef{Name: "ldap"}, Parameters: map[string]string{"group": testGroup}},
+		{CredentialRef: models.CredentialRef{Name: "ldap"}, Parameters: map[string]string{"group": testGroup, "baseDN": testBaseDN, "userFilter": "(uid=alice)"}},
+		{CredentialRef: models.CredentialRef{Name: "ldap"}, Parameters: map[string]string{"group": testGroup, "baseDN": testBaseDN, "userFilter": "(uid={username}"}},
+		{CredentialRef: models.CredentialRef{Name: "ldap"}, Parameters: map[string]string{"group": testGroup, "baseDN": testBaseDN, "timeout": "soon"}},
+	} {
+		_, err := NewLDAPProvider(ctx, config)
+		assert.Error(t, err, fmt.Sprint(config.CredentialRef.Name, config.Parameters))
+	}
+
+	p, err := NewLDAPProvider(ctx, models.ProviderConfig{CredentialRef: models.CredentialRef{Name: "ldap"}, Parameters: valid})
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, p.timeout)
+	assert.Equal(t, "member", p.memberAttribute)
+}
@@ -0,0 +1,39 @@
+package ldap
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:             string(kinds.ProviderKindLDAP),
+		RequiresUsername: true,
+		Description:      "Adds users to LDAP or Active Directory groups. Usernames are resolved to DNs with a search filter",
+		Parameters: []registry.Parameter{
+			{Name: "group", Description: "DN of the managed group", Required: true, Example: "cn=vpn-users,ou=groups,dc=example,dc=org"},
+			{Name: "baseDN", Description: "Base DN of user search", Required: true, Example: "ou=people,dc=example,dc=org"},
+			{Name: "userFilter", Description: "User search filter. {username} is replaced with escaped username. Defaults to (uid={username})", Example: "(&(objectClass=user)(sAMAccountName={username}))"},
+			{Name: "memberAttribute", Description: "Group attribute holding member DNs. Defaults to member", Example: "uniqueMember"},
+			{Name: "usernameAttribute", Description: "User attribute reported by ListUsersWithAccess. Defaults to uid", Example: "sAMAccountName"},
+			{Name: "timeout", Description: "Connection and operation timeout. Defaults to 30s", Example: "10s"},
+		},
+		Credentials: []registry.Parameter{
+			{Name: "url", Description: "Server URL. Use ldaps:// for LDAPS", Required: true, Example: "ldaps://ad.example.org:636"},
+			{Name: "binddn", Description: "DN of the account allowed to modify managed groups", Example: "cn=passage,ou=services,dc=example,dc=org"},
+			{Name: "bindpassword", Description: "Password of the bind account"},
+			{Name: "starttls", Description: "Upgrade ldap:// connection with StartTLS", Example: "true"},
+			{Name: "cacertpath", Description: "Path to PEM encoded CA certificate of the server", Example: "creds/ldap-ca.pem"},
+			{Name: "insecureskipverify", Description: "Skip server certificate verification", Example: "false"},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewLDAPProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
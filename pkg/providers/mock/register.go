@@ -0,0 +1,25 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:        string(kinds.ProviderKindMock),
+		Description: "Logs grant and revoke calls without changing any system. Useful for testing roles",
+		Parameters: []registry.Parameter{
+			{Name: "group", Description: "Group name reported in provider status", Required: true, Example: "ro-role"},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewMockProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
@@ -0,0 +1,393 @@
+package mysql
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const passwordAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// Comment marking accounts created by passage. Only those are dropped on revoke
+const managedComment = "managed by passage"
+
+// Host part of the granted role
+const roleHost = "%"
+
+// MySQL error returned when revoking a privilege that is not held
+const errNoSuchGrant = 1141
+
+var (
+	privilegePattern = regexp.MustCompile(`^[A-Z]+( [A-Z]+)*$`)
+	identPattern     = regexp.MustCompile(`^(\*|[A-Za-z0-9_$]+)$`)
+)
+
+// grant is a set of privileges on a single object, e.g. "SELECT, INSERT ON app.orders"
+type grant struct {
+	privileges []string
+	// schema or "*" for all schemas
+	schema string
+	// table or "*" for all tables in schema
+	table string
+}
+
+// parseGrants parses semicolon separated grants
+func parseGrants(value string) ([]grant, error) {
+	grants := []grant{}
+	for _, spec := range strings.Split(value, ";") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		privileges, object, found := strings.Cut(spec, " ON ")
+		if !found {
+			privileges, object, found = strings.Cut(spec, " on ")
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid grant %q. Expected <privileges> ON <schema>.<table>", spec)
+		}
+
+		g := grant{}
+		for _, privilege := range strings.Split(privileges, ",") {
+			privilege = strings.ToUpper(strings.Join(strings.Fields(privilege), " "))
+			if !privilegePattern.MatchString(privilege) {
+				return nil, fmt.Errorf("invalid privilege %q in grant %q", privilege, spec)
+			}
+			g.privileges = append(g.privileges, privilege)
+		}
+
+		schema, table, found := strings.Cut(strings.TrimSpace(object), ".")
+		if !found || !identPattern.MatchString(schema) || !identPattern.MatchString(table) || (schema == "*" && table != "*") {
+			return nil, fmt.Errorf("invalid object %q in grant %q", object, spec)
+		}
+		g.schema, g.table = schema, table
+
+		grants = append(grants, g)
+	}
+	return grants, nil
+}
+
+// object returns quoted grant target, e.g. `app`.`orders`
+func (g grant) object() string {
+	return quoteObject(g.schema) + "." + quoteObject(g.table)
+}
+
+func (g grant) String() string {
+	return strings.Join(g.privileges, ", ") + " ON " + g.schema + "." + g.table
+}
+
+// all reports whether grant holds ALL PRIVILEGES
+func (g grant) all() bool {
+	for _, privilege := range g.privileges {
+		if privilege == "ALL" || privilege == "ALL PRIVILEGES" {
+			return true
+		}
+	}
+	return false
+}
+
+// details returns granted role or grants for provider status
+func (p *MySQLProvider) details() string {
+	if p.role != "" {
+		return p.role
+	}
+	specs := make([]string, 0, len(p.grants))
+	for _, g := range p.grants {
+		specs = append(specs, g.String())
+	}
+	return strings.Join(specs, "; ")
+}
+
+// account returns quoted account name, e.g. 'user'@'%'
+func (p *MySQLProvider) account(username string) string {
+	return quoteLiteral(username) + "@" + quoteLiteral(p.userHost)
+}
+
+// roleAccount returns quoted role name. MariaDB roles have no host part
+func (p *MySQLProvider) roleAccount() string {
+	if p.flavor == flavorMariadb {
+		return quoteLiteral(p.role)
+	}
+	return quoteLiteral(p.role) + "@" + quoteLiteral(roleHost)
+}
+
+// grantee returns account name in the format used by information_schema
+func (p *MySQLProvider) grantee(username string) string {
+	return "'" + username + "'@'" + p.userHost + "'"
+}
+
+func (p *MySQLProvider) userExists(ctx context.Context, username string) (bool, error) {
+	var exists bool
+	err := p.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM mysql.user WHERE User = ? AND Host = ?)", username, p.userHost).Scan(&exists)
+	return exists, err
+}
+
+func (p *MySQLProvider) hasRole(ctx context.Context, username string) (bool, error) {
+	query := "SELECT EXISTS (SELECT 1 FROM mysql.role_edges WHERE FROM_USER = ? AND FROM_HOST = ? AND TO_USER = ? AND TO_HOST = ?)"
+	args := []any{p.role, roleHost, username, p.userHost}
+	if p.flavor == flavorMariadb {
+		query = "SELECT EXISTS (SELECT 1 FROM mysql.roles_mapping WHERE Role = ? AND User = ? AND Host = ?)"
+		args = []any{p.role, username, p.userHost}
+	}
+
+	var exists bool
+	err := p.db.QueryRowContext(ctx, query, args...).Scan(&exists)
+	return exists, err
+}
+
+func (p *MySQLProvider) isManaged(ctx context.Context, username string) (bool, error) {
+	var comment sql.NullString
+	err := p.db.QueryRowContext(ctx, `
+		SELECT JSON_UNQUOTE(JSON_EXTRACT(ATTRIBUTE, '$.comment'))
+		FROM information_schema.USER_ATTRIBUTES
+		WHERE USER = ? AND HOST = ?`, username, p.userHost).Scan(&comment)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return comment.String == managedComment, err
+}
+
+// createAccount creates account marked as managed by passage
+func (p *MySQLProvider) createAccount(ctx context.Context, username string, password string) error {
+	_, err := p.db.ExecContext(ctx, fmt.Sprintf("CREATE USER %s IDENTIFIED BY %s COMMENT %s",
+		p.account(username), quoteLiteral(password), quoteLiteral(managedComment)))
+	return err
+}
+
+// dropAccount drops managed account when it no longer holds any role or privilege
+func (p *MySQLProvider) dropAccount(ctx context.Context, username string) (bool, error) {
+	managed, err := p.isManaged(ctx, username)
+	if err != nil || !managed {
+		return false, err
+	}
+
+	var privileges int
+	err = p.db.QueryRowContext(ctx, `
+		SELECT
+			(SELECT count(*) FROM mysql.role_edges WHERE TO_USER = ? AND TO_HOST = ?) +
+			(SELECT count(*) FROM information_schema.USER_PRIVILEGES WHERE GRANTEE = ? AND PRIVILEGE_TYPE <> 'USAGE') +
+			(SELECT count(*) FROM information_schema.SCHEMA_PRIVILEGES WHERE GRANTEE = ?) +
+			(SELECT count(*) FROM information_schema.TABLE_PRIVILEGES WHERE GRANTEE = ?)`,
+		username, p.userHost, p.grantee(username), p.grantee(username), p.grantee(username)).Scan(&privileges)
+	if err != nil {
+		return false, err
+	}
+	if privileges > 0 {
+		return false, nil
+	}
+
+	if _, err := p.db.ExecContext(ctx, "DROP USER "+p.account(username)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// grant grants role or privileges to the account
+func (p *MySQLProvider) grant(ctx context.Context, username string) error {
+	if p.role == "" {
+		for _, g := range p.grants {
+			statement := fmt.Sprintf("GRANT %s ON %s TO %s", strings.Join(g.privileges, ", "), g.object(), p.account(username))
+			if _, err := p.db.ExecContext(ctx, statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	hasRole, err := p.hasRole(ctx, username)
+	if err != nil || hasRole {
+		return err
+	}
+
+	if _, err := p.db.ExecContext(ctx, fmt.Sprintf("GRANT %s TO %s", p.roleAccount(), p.account(username))); err != nil {
+		return err
+	}
+
+	// Role is activated on login only when it is a default role
+	statement := fmt.Sprintf("SET DEFAULT ROLE ALL TO %s", p.account(username))
+	if p.flavor == flavorMariadb {
+		statement = fmt.Sprintf("SET DEFAULT ROLE %s FOR %s", quoteIdent(p.role), p.account(username))
+	}
+	_, err = p.db.ExecContext(ctx, statement)
+	return err
+}
+
+// revoke revokes role or privileges from the account. Privileges that are not held are skipped
+func (p *MySQLProvider) revoke(ctx context.Context, username string) error {
+	if p.role == "" {
+		for _, g := range p.grants {
+			statement := fmt.Sprintf("REVOKE %s ON %s FROM %s", strings.Join(g.privileges, ", "), g.object(), p.account(username))
+			var mysqlErr *mysql.MySQLError
+			if _, err := p.db.ExecContext(ctx, statement); err != nil && !(errors.As(err, &mysqlErr) && mysqlErr.Number == errNoSuchGrant) {
+				return err
+			}
+		}
+		return nil
+	}
+
+	hasRole, err := p.hasRole(ctx, username)
+	if err != nil || !hasRole {
+		return err
+	}
+
+	_, err = p.db.ExecContext(ctx, fmt.Sprintf("REVOKE %s FROM %s", p.roleAccount(), p.account(username)))
+	return err
+}
+
+// listRoleMembers lists accounts the role is granted to
+func (p *MySQLProvider) listRoleMembers(ctx context.Context) ([]string, error) {
+	query := "SELECT TO_USER FROM mysql.role_edges WHERE FROM_USER = ? AND FROM_HOST = ? AND TO_HOST = ? ORDER BY TO_USER"
+	args := []any{p.role, roleHost, p.userHost}
+	if p.flavor == flavorMariadb {
+		query = "SELECT User FROM mysql.roles_mapping WHERE Role = ? AND Host = ? ORDER BY User"
+		args = []any{p.role, p.userHost}
+	}
+
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role members: %w", err)
+	}
+	defer rows.Close()
+
+	usernames := []string{}
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, err
+		}
+		usernames = append(usernames, username)
+	}
+
+	return usernames, rows.Err()
+}
+
+// listGrantees lists accounts holding all configured privileges
+func (p *MySQLProvider) listGrantees(ctx context.Context) ([]string, error) {
+	var holders map[string]bool
+	for _, g := range p.grants {
+		grantees, err := p.listGrantGrantees(ctx, g)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list grantees: %w", err)
+		}
+
+		if holders == nil {
+			holders = grantees
+			continue
+		}
+		for username := range holders {
+			if !grantees[username] {
+				delete(holders, username)
+			}
+		}
+	}
+
+	usernames := []string{}
+	for username := range holders {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+
+	return usernames, nil
+}
+
+// listGrantGrantees lists accounts holding every privilege of a grant on its object.
+// ALL PRIVILEGES is expanded by the server, so any privilege on the object is accepted for it
+func (p *MySQLProvider) listGrantGrantees(ctx context.Context, g grant) (map[string]bool, error) {
+	var query string
+	var args []any
+
+	switch {
+	case g.schema == "*":
+		query = "SELECT GRANTEE FROM information_schema.USER_PRIVILEGES WHERE 1 = 1"
+	case g.table == "*":
+		query = "SELECT GRANTEE FROM information_schema.SCHEMA_PRIVILEGES WHERE TABLE_SCHEMA = ?"
+		args = append(args, g.schema)
+	default:
+		query = "SELECT GRANTEE FROM information_schema.TABLE_PRIVILEGES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?"
+		args = append(args, g.schema, g.table)
+	}
+
+	required := 1
+	if !g.all() {
+		query += " AND PRIVILEGE_TYPE IN (?" + strings.Repeat(", ?", len(g.privileges)-1) + ")"
+		for _, privilege := range g.privileges {
+			args = append(args, privilege)
+		}
+		required = len(g.privileges)
+	} else {
+		query += " AND PRIVILEGE_TYPE <> 'USAGE'"
+	}
+	query += " GROUP BY GRANTEE HAVING COUNT(DISTINCT PRIVILEGE_TYPE) >= ?"
+	args = append(args, required)
+
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grantees := map[string]bool{}
+	for rows.Next() {
+		var grantee string
+		if err := rows.Scan(&grantee); err != nil {
+			return nil, err
+		}
+		if username, host, ok := parseGrantee(grantee); ok && host == p.userHost {
+			grantees[username] = true
+		}
+	}
+
+	return grantees, rows.Err()
+}
+
+// parseGrantee splits information_schema grantee 'user'@'host'
+func parseGrantee(grantee string) (string, string, bool) {
+	i := strings.LastIndex(grantee, "@")
+	if i < 0 {
+		return "", "", false
+	}
+	username, host := grantee[:i], grantee[i+1:]
+	if len(username) < 2 || len(host) < 2 {
+		return "", "", false
+	}
+	return strings.Trim(username, "'"), strings.Trim(host, "'"), true
+}
+
+// quoteObject quotes schema or table name. Wildcard is kept as is
+func quoteObject(name string) string {
+	if name == "*" {
+		return name
+	}
+	return quoteIdent(name)
+}
+
+func quoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// quoteLiteral quotes string literal. Backslashes are escaped so the result is safe with and without NO_BACKSLASH_ESCAPES
+func quoteLiteral(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func generatePassword() (string, error) {
+	password := make([]byte, 32)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(passwordAlphabet))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate password: %w", err)
+		}
+		password[i] = passwordAlphabet[n.Int64()]
+	}
+	return string(password), nil
+}
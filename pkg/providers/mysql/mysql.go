@@ -0,0 +1,273 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/config"
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/tracing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/rs/zerolog/log"
+)
+
+const providerType = string(kinds.ProviderKindMySQL)
+
+// Server flavors
+const (
+	flavorMysql   = "mysql"
+	flavorMariadb = "mariadb"
+)
+
+var Config = config.GetConfig()
+
+// Connection pools shared between provider instances
+var (
+	poolsMu sync.Mutex
+	pools   = map[string]*sql.DB{}
+)
+
+// MySQLProvider grants privileges or a role to a MySQL/MariaDB account
+type MySQLProvider struct {
+	db *sql.DB
+
+	// provider name as defined in the provider configuration
+	name string
+	// granted role. Mutually exclusive with grants
+	role string
+	// granted privileges
+	grants []grant
+	// account host part
+	userHost string
+	flavor   string
+	// create account with generated password when it does not exist
+	createUser bool
+
+	host     string
+	port     string
+	database string
+}
+
+// NewMySQLProvider initializes a new MySQLProvider with connection from CredentialRef
+func NewMySQLProvider(ctx context.Context, config models.ProviderConfig) (*MySQLProvider, error) {
+	creds := Config.GetCredentials(config.CredentialRef.Name)
+	data := config.Parameters
+
+	dsn := creds.GetString("dsn")
+	if dsn == "" {
+		return nil, errors.New("dsn not found in credentials")
+	}
+
+	dsnConfig, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dsn: %w", err)
+	}
+
+	p := &MySQLProvider{
+		name:     config.Name,
+		role:     data["role"],
+		userHost: data["userHost"],
+		flavor:   data["flavor"],
+		database: dsnConfig.DBName,
+	}
+	p.host, p.port, _ = net.SplitHostPort(dsnConfig.Addr)
+
+	if p.userHost == "" {
+		p.userHost = "%"
+	}
+	if p.flavor == "" {
+		p.flavor = flavorMysql
+	}
+	if p.flavor != flavorMysql && p.flavor != flavorMariadb {
+		return nil, fmt.Errorf("invalid flavor %s. Expected mysql or mariadb", p.flavor)
+	}
+
+	if data["grants"] != "" {
+		p.grants, err = parseGrants(data["grants"])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.role == "" && len(p.grants) == 0 {
+		return nil, errors.New("role or grants not found in provider config")
+	}
+	if p.role != "" && len(p.grants) > 0 {
+		return nil, errors.New("role and grants are mutually exclusive")
+	}
+
+	if value := data["createUser"]; value != "" {
+		p.createUser, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid createUser: %w", err)
+		}
+	}
+	if p.createUser && p.flavor == flavorMariadb {
+		return nil, errors.New("createUser is supported only with mysql flavor")
+	}
+
+	p.db, err = openDB(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func openDB(dsn string) (*sql.DB, error) {
+	poolsMu.Lock()
+	defer poolsMu.Unlock()
+
+	if db, ok := pools[dsn]; ok {
+		return db, nil
+	}
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db.SetMaxOpenConns(4)
+	db.SetConnMaxIdleTime(5 * time.Minute)
+
+	pools[dsn] = db
+	return db, nil
+}
+
+// GrantAccess grants configured privileges or role. Account is created first when createUser is enabled
+func (p *MySQLProvider) GrantAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "GrantAccess")
+	defer span.End()
+
+	username := request.GetProviderUsername(providerType)
+	if username == "" {
+		err := errors.New("mysql username is not set")
+		request.SetProviderStatusError(p.name, p.details(), err.Error())
+		return err
+	}
+
+	exists, err := p.userExists(ctx, username)
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.details(), err.Error())
+		return fmt.Errorf("failed to check user: %w", err)
+	}
+
+	if !exists {
+		if !p.createUser {
+			err := fmt.Errorf("user %s does not exist", p.account(username))
+			request.SetProviderStatusError(p.name, p.details(), err.Error())
+			return err
+		}
+
+		password, err := generatePassword()
+		if err != nil {
+			request.SetProviderStatusError(p.name, p.details(), err.Error())
+			return err
+		}
+		if err := p.createAccount(ctx, username, password); err != nil {
+			request.SetProviderStatusError(p.name, p.details(), err.Error())
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		request.AddCredential(p.name, models.AccessCredentialPassword, map[string]string{
+			"host":     p.host,
+			"port":     p.port,
+			"database": p.database,
+			"username": username,
+			"password": password,
+		}, request.Status.ExpiresAt)
+	}
+
+	if err := p.grant(ctx, username); err != nil {
+		request.SetProviderStatusError(p.name, p.details(), err.Error())
+		return fmt.Errorf("failed to grant access: %w", err)
+	}
+
+	request.SetProviderStatusGranted(p.name, p.details(), "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", username).
+		Str("Grants", p.details()).
+		Msg("Access granted")
+
+	return nil
+}
+
+// RevokeAccess revokes configured privileges or role. Accounts created by passage are dropped once they hold no privileges
+func (p *MySQLProvider) RevokeAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "RevokeAccess")
+	defer span.End()
+
+	username := request.GetProviderUsername(providerType)
+
+	exists, err := p.userExists(ctx, username)
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.details(), err.Error())
+		return fmt.Errorf("failed to check user: %w", err)
+	}
+
+	if !exists {
+		request.SetProviderStatusRevoked(p.name, p.details(), "user does not exist")
+		return nil
+	}
+
+	if err := p.revoke(ctx, username); err != nil {
+		request.SetProviderStatusError(p.name, p.details(), err.Error())
+		return fmt.Errorf("failed to revoke access: %w", err)
+	}
+
+	details := ""
+	if p.createUser {
+		dropped, err := p.dropAccount(ctx, username)
+		if err != nil {
+			request.SetProviderStatusError(p.name, p.details(), err.Error())
+			return fmt.Errorf("failed to drop user: %w", err)
+		}
+		if dropped {
+			details = "user dropped"
+		}
+	}
+
+	request.SetProviderStatusRevoked(p.name, p.details(), details)
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", username).
+		Str("Grants", p.details()).
+		Msg("Access revoked")
+
+	return nil
+}
+
+// ListUsersWithAccess lists accounts holding the role or all configured privileges
+func (p *MySQLProvider) ListUsersWithAccess(ctx context.Context, roleRef models.AccessRoleRef) ([]string, error) {
+	ctx, span := startSpan(ctx, "ListUsersWithAccess")
+	defer span.End()
+
+	if p.role != "" {
+		return p.listRoleMembers(ctx)
+	}
+	return p.listGrantees(ctx)
+}
+
+// IsAccessExpired checks whether the access for the given request has expired
+func (p *MySQLProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
+	_, span := startSpan(ctx, "IsAccessExpired")
+	defer span.End()
+
+	return request.IsExpired(time.Now())
+}
+
+func startSpan(ctx context.Context, name string) (context.Context, *tracing.SpanWrapper) {
+	ctx, span := tracing.NewSpanWrapper(ctx, fmt.Sprintf("providers.mysql.%s", name))
+	return ctx, span
+}
@@ -0,0 +1,200 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Integration tests run against a local MySQL 8 instance, e.g.
+// PASSAGE_TEST_MYSQL_DSN=root:root@tcp(localhost:3306)/
+const envTestDSN = "PASSAGE_TEST_MYSQL_DSN"
+
+func testProvider(t *testing.T, parameters map[string]string) *MySQLProvider {
+	dsn := os.Getenv(envTestDSN)
+	if dsn == "" {
+		t.Skipf("%s is not set", envTestDSN)
+	}
+
+	Config.Creds = map[string]models.Credential{
+		"mysql": {
+			Name: "mysql",
+			Data: map[string]string{"dsn": dsn},
+		},
+	}
+
+	p, err := NewMySQLProvider(context.Background(), models.ProviderConfig{
+		Name:          "MySQLRead",
+		Provider:      "mysql",
+		CredentialRef: models.CredentialRef{Name: "mysql"},
+		Parameters:    parameters,
+	})
+	require.NoError(t, err)
+	return p
+}
+
+func testRequest(username string) *models.AccessRequest {
+	expires := time.Now().Add(time.Hour)
+	request := &models.AccessRequest{Id: "req-1"}
+	request.Status.ExpiresAt = &expires
+	request.Status.ProviderUsernames = map[string]string{providerType: username}
+	return request
+}
+
+func setup(t *testing.T, p *MySQLProvider, statements ...string) {
+	ctx := context.Background()
+	cleanup := []string{
+		"DROP USER IF EXISTS 'passage_test_user'@'%'",
+		"DROP ROLE IF EXISTS 'passage_test_read'@'%'",
+		"DROP DATABASE IF EXISTS passage_test",
+	}
+	for _, statement := range cleanup {
+		_, _ = p.db.ExecContext(ctx, statement)
+	}
+	t.Cleanup(func() {
+		for _, statement := range cleanup {
+			_, _ = p.db.ExecContext(ctx, statement)
+		}
+	})
+
+	for _, statement := range append([]string{
+		"CREATE DATABASE passage_test",
+		"CREATE TABLE passage_test.orders (id int)",
+	}, statements...) {
+		_, err := p.db.ExecContext(ctx, statement)
+		require.NoError(t, err, statement)
+	}
+}
+
+func TestGrantRevokePrivileges(t *testing.T) {
+	ctx := context.Background()
+	p := testProvider(t, map[string]string{"grants": "SELECT ON passage_test.*; INSERT, UPDATE ON passage_test.orders"})
+	setup(t, p, "CREATE USER 'passage_test_user'@'%'")
+
+	request := testRequest("passage_test_user")
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusGranted, request.Status.ProviderStatuses["MySQLRead"].Action)
+	assert.Empty(t, request.Credentials)
+
+	users, err := p.ListUsersWithAccess(ctx, models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"passage_test_user"}, users)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusRevoked, request.Status.ProviderStatuses["MySQLRead"].Action)
+
+	users, err = p.ListUsersWithAccess(ctx, models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Empty(t, users)
+
+	// Revoking privileges that are not held succeeds
+	require.NoError(t, p.RevokeAccess(ctx, request))
+
+	// User not created by passage is kept
+	exists, err := p.userExists(ctx, "passage_test_user")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestGrantRevokeRoleCreatedUser(t *testing.T) {
+	ctx := context.Background()
+	p := testProvider(t, map[string]string{"role": "passage_test_read", "createUser": "true"})
+	setup(t, p,
+		"CREATE ROLE 'passage_test_read'@'%'",
+		"GRANT SELECT ON passage_test.* TO 'passage_test_read'@'%'",
+	)
+
+	request := testRequest("passage_test_user")
+	require.NoError(t, p.GrantAccess(ctx, request))
+	require.Len(t, request.Credentials, 1)
+
+	credential := request.Credentials[0]
+	assert.Equal(t, models.AccessCredentialPassword, credential.Kind)
+	assert.Equal(t, "passage_test_user", credential.Data["username"])
+	assert.Len(t, credential.Data["password"], 32)
+
+	users, err := p.ListUsersWithAccess(ctx, models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"passage_test_user"}, users)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, "user dropped", request.Status.ProviderStatuses["MySQLRead"].Error)
+
+	exists, err := p.userExists(ctx, "passage_test_user")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestGrantMissingUser(t *testing.T) {
+	p := testProvider(t, map[string]string{"grants": "SELECT ON passage_test.*"})
+	setup(t, p)
+
+	request := testRequest("passage_test_user")
+	assert.Error(t, p.GrantAccess(context.Background(), request))
+	assert.Equal(t, "user 'passage_test_user'@'%' does not exist", request.Status.ProviderStatuses["MySQLRead"].Error)
+}
+
+func TestParseGrants(t *testing.T) {
+	grants, err := parseGrants("select ON app.*; SELECT,  show view ON app.orders ;")
+	require.NoError(t, err)
+	require.Len(t, grants, 2)
+
+	assert.Equal(t, []string{"SELECT"}, grants[0].privileges)
+	assert.Equal(t, "`app`.*", grants[0].object())
+	assert.Equal(t, []string{"SELECT", "SHOW VIEW"}, grants[1].privileges)
+	assert.Equal(t, "`app`.`orders`", grants[1].object())
+
+	for _, value := range []string{
+		"SELECT app.*",
+		"SELECT ON app",
+		"SELECT ON *.orders",
+		"SELECT ON app.orders; DROP USER root",
+		"SELECT ON app.`orders`",
+		"SELECT; DROP ON app.*",
+	} {
+		_, err := parseGrants(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestParseGrantee(t *testing.T) {
+	username, host, ok := parseGrantee("'app.user@example.com'@'10.0.%'")
+	assert.True(t, ok)
+	assert.Equal(t, "app.user@example.com", username)
+	assert.Equal(t, "10.0.%", host)
+
+	_, _, ok = parseGrantee("invalid")
+	assert.False(t, ok)
+}
+
+func TestQuote(t *testing.T) {
+	assert.Equal(t, "`user``; DROP USER x; --`", quoteIdent("user`; DROP USER x; --"))
+	assert.Equal(t, `'it''s \\'`, quoteLiteral(`it's \`))
+}
+
+func TestConfigErrors(t *testing.T) {
+	ctx := context.Background()
+	Config.Creds = map[string]models.Credential{
+		"mysql": {Name: "mysql", Data: map[string]string{"dsn": "passage:secret@tcp(localhost:3306)/"}},
+	}
+
+	for _, config := range []models.ProviderConfig{
+		{Parameters: map[string]string{"role": "read"}},
+		{CredentialRef: models.CredentialRef{Name: "mysql"}, Parameters: map[string]string{}},
+		{CredentialRef: models.CredentialRef{Name: "mysql"}, Parameters: map[string]string{"role": "read", "grants": "SELECT ON app.*"}},
+		{CredentialRef: models.CredentialRef{Name: "mysql"}, Parameters: map[string]string{"grants": "SELECT app"}},
+		{CredentialRef: models.CredentialRef{Name: "mysql"}, Parameters: map[string]string{"role": "read", "createUser": "maybe"}},
+		{CredentialRef: models.CredentialRef{Name: "mysql"}, Parameters: map[string]string{"role": "read", "flavor": "oracle"}},
+		{CredentialRef: models.CredentialRef{Name: "mysql"}, Parameters: map[string]string{"role": "read", "flavor": "mariadb", "createUser": "true"}},
+	} {
+		_, err := NewMySQLProvider(ctx, config)
+		assert.Error(t, err, fmt.Sprint(config.Parameters))
+	}
+}
@@ -0,0 +1,33 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:             string(kinds.ProviderKindMySQL),
+		RequiresUsername: true,
+		Description:      "Grants MySQL/MariaDB role or privileges on schemas and tables. Optionally creates an account with generated password",
+		Parameters: []registry.Parameter{
+			{Name: "role", Description: "Database role granted to the user. Mutually exclusive with grants", Example: "app_readonly"},
+			{Name: "grants", Description: "Semicolon separated privileges granted to the user. Mutually exclusive with role", Example: "SELECT ON app.*; SELECT, UPDATE ON billing.invoices"},
+			{Name: "userHost", Description: "Host part of the user account. Defaults to %", Example: "10.0.%"},
+			{Name: "flavor", Description: "Server flavor, mysql or mariadb. Defaults to mysql", Example: "mariadb"},
+			{Name: "createUser", Description: "Create account with generated password when user does not exist. Created users are dropped on revoke. MySQL only", Example: "true"},
+		},
+		Credentials: []registry.Parameter{
+			{Name: "dsn", Description: "Data source name of an account with CREATE USER and GRANT OPTION privileges", Required: true, Example: "passage:secret@tcp(db.example.com:3306)/"},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewMySQLProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
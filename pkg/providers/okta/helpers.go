@@ -0,0 +1,392 @@
+package okta
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const maxErrorBody = 512
+
+// Scopes requested by OAuth service apps
+const oauthScopes = "okta.users.read okta.groups.manage"
+
+var errUserNotFound = errors.New("user not found")
+
+// OAuth access tokens shared between provider instances, keyed by org URL and client id
+var (
+	tokensMu sync.Mutex
+	tokens   = map[string]accessToken{}
+)
+
+type accessToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+type user struct {
+	ID      string `json:"id"`
+	Profile struct {
+		Login string `json:"login"`
+	} `json:"profile"`
+}
+
+type group struct {
+	ID      string `json:"id"`
+	Profile struct {
+		Name string `json:"name"`
+	} `json:"profile"`
+}
+
+// statusError is returned for unexpected HTTP response codes
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	if e.body == "" {
+		return fmt.Sprintf("unexpected status %d", e.code)
+	}
+	return fmt.Sprintf("unexpected status %d: %s", e.code, e.body)
+}
+
+func groupUserPath(groupID string, userID string) string {
+	return "/api/v1/groups/" + url.PathEscape(groupID) + "/users/" + url.PathEscape(userID)
+}
+
+// call sends request relative to org URL and decodes 2xx response body into out
+func (p *OktaProvider) call(ctx context.Context, method string, path string, in any, out any) error {
+	body, _, err := p.do(ctx, method, p.orgURL+path, in)
+	if err != nil {
+		return err
+	}
+	if out == nil || len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+// list follows Link rel="next" headers and appends all pages to out
+func (p *OktaProvider) list(ctx context.Context, path string, out any) error {
+	items := []json.RawMessage{}
+	next := p.orgURL + path
+
+	for next != "" {
+		body, header, err := p.do(ctx, http.MethodGet, next, nil)
+		if err != nil {
+			return err
+		}
+
+		page := []json.RawMessage{}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return fmt.Errorf("invalid response: %w", err)
+		}
+		items = append(items, page...)
+
+		next = nextLink(header.Values("Link"))
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// do sends request and returns body of a 2xx response.
+// Rate limited requests are retried after X-Rate-Limit-Reset as long as total wait stays within maxWait
+func (p *OktaProvider) do(ctx context.Context, method string, rawURL string, in any) ([]byte, http.Header, error) {
+	ctx, span := startSpan(ctx, "call")
+	span.SetAttributes(
+		attribute.String("span.kind", "client"),
+		attribute.String("http.method", method),
+	)
+	defer span.End()
+
+	var payload []byte
+	if in != nil {
+		var err error
+		if payload, err = json.Marshal(in); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	waited := time.Duration(0)
+	for {
+		req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(payload))
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		if in != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		authorization, err := p.authorization(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Header.Set("Authorization", authorization)
+
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return nil, nil, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read response: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			delay := rateLimitDelay(resp.Header, time.Now())
+			if waited+delay > p.maxWait {
+				return nil, nil, &statusError{code: resp.StatusCode, body: "rate limit exceeded"}
+			}
+
+			log.Warn().
+				Str("TraceID", span.GetTraceID()).
+				Str("Provider", p.name).
+				Dur("Delay", delay).
+				Msg("Okta rate limit exceeded, waiting for reset")
+
+			select {
+			case <-ctx.Done():
+				return nil, nil, ctx.Err()
+			case <-time.After(delay):
+			}
+			waited += delay
+			continue
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && p.privateKey != nil {
+			// Drop cached token so next call requests a new one
+			tokensMu.Lock()
+			delete(tokens, p.tokenKey())
+			tokensMu.Unlock()
+		}
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return nil, nil, &statusError{code: resp.StatusCode, body: errorSummary(body)}
+		}
+
+		return body, resp.Header, nil
+	}
+}
+
+// rateLimitDelay returns time until X-Rate-Limit-Reset, which holds UTC epoch seconds
+func rateLimitDelay(header http.Header, now time.Time) time.Duration {
+	reset, err := strconv.ParseInt(header.Get("X-Rate-Limit-Reset"), 10, 64)
+	if err != nil {
+		return time.Second
+	}
+
+	delay := time.Unix(reset, 0).Sub(now) + time.Second
+	if delay < time.Second {
+		return time.Second
+	}
+	return delay
+}
+
+// nextLink extracts URL of rel="next" from Link headers
+func nextLink(links []string) string {
+	for _, header := range links {
+		for _, link := range strings.Split(header, ",") {
+			target, params, found := strings.Cut(strings.TrimSpace(link), ";")
+			if found && strings.Contains(params, `rel="next"`) {
+				return strings.Trim(strings.TrimSpace(target), "<>")
+			}
+		}
+	}
+	return ""
+}
+
+// errorSummary extracts errorSummary from Okta error response
+func errorSummary(body []byte) string {
+	var oktaErr struct {
+		ErrorSummary string `json:"errorSummary"`
+	}
+	if err := json.Unmarshal(body, &oktaErr); err == nil && oktaErr.ErrorSummary != "" {
+		return oktaErr.ErrorSummary
+	}
+	if len(body) > maxErrorBody {
+		return string(body[:maxErrorBody]) + "..."
+	}
+	return string(body)
+}
+
+// authorization returns Authorization header value for API token or OAuth access token
+func (p *OktaProvider) authorization(ctx context.Context) (string, error) {
+	if p.apiToken != "" {
+		return "SSWS " + p.apiToken, nil
+	}
+
+	tokensMu.Lock()
+	token, ok := tokens[p.tokenKey()]
+	tokensMu.Unlock()
+	if ok && time.Now().Add(time.Minute).Before(token.expiresAt) {
+		return "Bearer " + token.value, nil
+	}
+
+	token, err := p.requestToken(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	tokensMu.Lock()
+	tokens[p.tokenKey()] = token
+	tokensMu.Unlock()
+
+	return "Bearer " + token.value, nil
+}
+
+func (p *OktaProvider) tokenKey() string {
+	return p.orgURL + "|" + p.clientID
+}
+
+// requestToken exchanges a signed client assertion for an access token with client credentials grant
+func (p *OktaProvider) requestToken(ctx context.Context) (accessToken, error) {
+	tokenURL := p.orgURL + "/oauth2/v1/token"
+	now := time.Now()
+
+	assertion := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+		Issuer:    p.clientID,
+		Subject:   p.clientID,
+		Audience:  jwt.ClaimStrings{tokenURL},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		ID:        uuid.NewString(),
+	})
+	if p.keyID != "" {
+		assertion.Header["kid"] = p.keyID
+	}
+	signed, err := assertion.SignedString(p.privateKey)
+	if err != nil {
+		return accessToken{}, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("scope", oauthScopes)
+	form.Set("client_assertion_type", "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+	form.Set("client_assertion", signed)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return accessToken{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return accessToken{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return accessToken{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return accessToken{}, &statusError{code: resp.StatusCode, body: errorSummary(body)}
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return accessToken{}, fmt.Errorf("invalid token response: %w", err)
+	}
+
+	return accessToken{
+		value:     token.AccessToken,
+		expiresAt: now.Add(time.Duration(token.ExpiresIn) * time.Second),
+	}, nil
+}
+
+// findUser resolves login to Okta user id
+func (p *OktaProvider) findUser(ctx context.Context, login string) (string, error) {
+	if login == "" {
+		return "", errors.New("okta username is not set")
+	}
+
+	var u user
+	err := p.call(ctx, http.MethodGet, "/api/v1/users/"+url.PathEscape(login), nil, &u)
+
+	var statusErr *statusError
+	if errors.As(err, &statusErr) && statusErr.code == http.StatusNotFound {
+		return "", fmt.Errorf("%w: %s", errUserNotFound, login)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+	return u.ID, nil
+}
+
+// groupID returns group id, resolving group name with an exact profile.name search
+func (p *OktaProvider) groupID(ctx context.Context) (string, error) {
+	if groupIDPattern.MatchString(p.group) {
+		return p.group, nil
+	}
+
+	search := fmt.Sprintf(`type eq "OKTA_GROUP" and profile.name eq "%s"`, strings.ReplaceAll(p.group, `"`, `\"`))
+
+	groups := []group{}
+	if err := p.list(ctx, "/api/v1/groups?search="+url.QueryEscape(search), &groups); err != nil {
+		return "", fmt.Errorf("failed to search group: %w", err)
+	}
+
+	switch {
+	case len(groups) == 0:
+		return "", fmt.Errorf("group %s not found", p.group)
+	case len(groups) > 1:
+		return "", fmt.Errorf("multiple groups named %s", p.group)
+	}
+	return groups[0].ID, nil
+}
+
+// membership resolves group and user ids and checks whether the user is a group member.
+// User groups are listed since a user is usually in fewer groups than a group has members
+func (p *OktaProvider) membership(ctx context.Context, login string) (string, string, bool, error) {
+	groupID, err := p.groupID(ctx)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	userID, err := p.findUser(ctx, login)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	groups := []group{}
+	if err := p.list(ctx, "/api/v1/users/"+url.PathEscape(userID)+"/groups", &groups); err != nil {
+		return "", "", false, fmt.Errorf("failed to list user groups: %w", err)
+	}
+
+	for _, g := range groups {
+		if g.ID == groupID {
+			return groupID, userID, true, nil
+		}
+	}
+	return groupID, userID, false, nil
+}
@@ -0,0 +1,231 @@
+package okta
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/config"
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/tracing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const providerType = string(kinds.ProviderKindOkta)
+
+// Okta group ids, e.g. 00g1emaKYZTWRYYRRTSK
+var groupIDPattern = regexp.MustCompile(`^00g[0-9A-Za-z]{17}$`)
+
+var Config = config.GetConfig()
+
+// OktaProvider manages Okta group membership
+type OktaProvider struct {
+	client *http.Client
+
+	// provider name as defined in the provider configuration
+	name string
+	// group id or name
+	group string
+	// maximum time spent waiting for rate limit reset per call
+	maxWait time.Duration
+
+	orgURL string
+	// API token authentication
+	apiToken string
+	// OAuth 2.0 service app authentication
+	clientID   string
+	keyID      string
+	privateKey *rsa.PrivateKey
+}
+
+// NewOktaProvider initializes a new OktaProvider with API token or OAuth private key from CredentialRef
+func NewOktaProvider(ctx context.Context, config models.ProviderConfig) (*OktaProvider, error) {
+	creds := Config.GetCredentials(config.CredentialRef.Name)
+	data := config.Parameters
+
+	timeout := 30 * time.Second
+	if value := data["timeout"]; value != "" {
+		var err error
+		timeout, err = time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+
+	maxWait := time.Minute
+	if value := data["maxRateLimitWait"]; value != "" {
+		var err error
+		maxWait, err = time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maxRateLimitWait: %w", err)
+		}
+	}
+
+	p := &OktaProvider{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+		name:     config.Name,
+		group:    data["group"],
+		maxWait:  maxWait,
+		orgURL:   strings.TrimSuffix(creds.GetString("orgurl"), "/"),
+		apiToken: creds.GetString("apitoken"),
+		clientID: creds.GetString("clientid"),
+		keyID:    creds.GetString("keyid"),
+	}
+
+	if p.orgURL == "" {
+		return nil, errors.New("orgurl not found in credentials")
+	}
+	if u, err := url.Parse(p.orgURL); err != nil || u.Scheme != "https" && u.Scheme != "http" || u.Host == "" {
+		return nil, fmt.Errorf("invalid orgurl %s", p.orgURL)
+	}
+	if p.group == "" {
+		return nil, errors.New("group not found in provider config")
+	}
+
+	privateKey := []byte(creds.GetString("privatekey"))
+	if path := creds.GetString("privatekeypath"); path != "" && len(privateKey) == 0 {
+		var err error
+		privateKey, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key: %w", err)
+		}
+	}
+
+	switch {
+	case p.apiToken != "":
+	case p.clientID != "" && len(privateKey) > 0:
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %w", err)
+		}
+		p.privateKey = key
+	default:
+		return nil, errors.New("apitoken or clientid with privatekey not found in credentials")
+	}
+
+	return p, nil
+}
+
+// GrantAccess adds the user to the group
+func (p *OktaProvider) GrantAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "GrantAccess")
+	defer span.End()
+
+	username := request.GetProviderUsername(providerType)
+
+	groupID, userID, isMember, err := p.membership(ctx, username)
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.group, err.Error())
+		return err
+	}
+
+	if isMember {
+		request.SetProviderStatusGranted(p.name, p.group, "already in group")
+		return nil
+	}
+
+	if err := p.call(ctx, http.MethodPut, groupUserPath(groupID, userID), nil, nil); err != nil {
+		request.SetProviderStatusError(p.name, p.group, err.Error())
+		return fmt.Errorf("failed to add user to group: %w", err)
+	}
+
+	request.SetProviderStatusGranted(p.name, p.group, "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", username).
+		Str("Group", p.group).
+		Msg("User added to group")
+
+	return nil
+}
+
+// RevokeAccess removes the user from the group
+func (p *OktaProvider) RevokeAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "RevokeAccess")
+	defer span.End()
+
+	username := request.GetProviderUsername(providerType)
+
+	groupID, userID, isMember, err := p.membership(ctx, username)
+	if errors.Is(err, errUserNotFound) {
+		request.SetProviderStatusRevoked(p.name, p.group, "user not found")
+		return nil
+	}
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.group, err.Error())
+		return err
+	}
+
+	if !isMember {
+		request.SetProviderStatusRevoked(p.name, p.group, "already removed from group")
+		return nil
+	}
+
+	if err := p.call(ctx, http.MethodDelete, groupUserPath(groupID, userID), nil, nil); err != nil {
+		request.SetProviderStatusError(p.name, p.group, err.Error())
+		return fmt.Errorf("failed to remove user from group: %w", err)
+	}
+
+	request.SetProviderStatusRevoked(p.name, p.group, "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", username).
+		Str("Group", p.group).
+		Msg("User removed from group")
+
+	return nil
+}
+
+// ListUsersWithAccess lists logins of group members
+func (p *OktaProvider) ListUsersWithAccess(ctx context.Context, roleRef models.AccessRoleRef) ([]string, error) {
+	ctx, span := startSpan(ctx, "ListUsersWithAccess")
+	defer span.End()
+
+	groupID, err := p.groupID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	users := []user{}
+	if err := p.list(ctx, "/api/v1/groups/"+url.PathEscape(groupID)+"/users?limit=200", &users); err != nil {
+		return nil, fmt.Errorf("failed to list group members: %w", err)
+	}
+
+	logins := []string{}
+	for _, u := range users {
+		logins = append(logins, u.Profile.Login)
+	}
+
+	return logins, nil
+}
+
+// IsAccessExpired checks whether the access for the given request has expired
+func (p *OktaProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
+	_, span := startSpan(ctx, "IsAccessExpired")
+	defer span.End()
+
+	return request.IsExpired(time.Now())
+}
+
+func startSpan(ctx context.Context, name string) (context.Context, *tracing.SpanWrapper) {
+	ctx, span := tracing.NewSpanWrapper(ctx, fmt.Sprintf("providers.okta.%s", name))
+	return ctx, span
+}
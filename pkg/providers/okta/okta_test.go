@@ -0,0 +1,326 @@
+package okta
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testToken   = "okta-token"
+	testGroupID = "00g1emaKYZTWRYYRRTSK"
+)
+
+// oktaAPI is a minimal Okta management API returning one item per page
+type oktaAPI struct {
+	mu         sync.Mutex
+	url        string
+	users      map[string]string
+	members    []string
+	rateLimits int
+	publicKey  *rsa.PublicKey
+	tokens     int
+}
+
+func newOktaAPI(logins ...string) *oktaAPI {
+	api := &oktaAPI{users: map[string]string{}}
+	for i, login := range logins {
+		api.users[fmt.Sprintf("00u%d", i+1)] = login
+	}
+	return api
+}
+
+func (o *oktaAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if r.URL.Path == "/oauth2/v1/token" {
+		o.token(w, r)
+		return
+	}
+
+	authorization := r.Header.Get("Authorization")
+	if authorization != "SSWS "+testToken && authorization != "Bearer access-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]string{"errorCode": "E0000011", "errorSummary": "Invalid token provided"})
+		return
+	}
+
+	if o.rateLimits > 0 {
+		o.rateLimits--
+		w.Header().Set("X-Rate-Limit-Reset", strconv.FormatInt(time.Now().Unix(), 10))
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+
+	path := r.URL.Path
+	groupUsers := "/api/v1/groups/" + testGroupID + "/users"
+
+	switch {
+	case r.Method == http.MethodGet && path == "/api/v1/groups":
+		groups := []group{}
+		if r.URL.Query().Get("search") == `type eq "OKTA_GROUP" and profile.name eq "Admins"` {
+			g := group{ID: testGroupID}
+			g.Profile.Name = "Admins"
+			groups = append(groups, g)
+		}
+		_ = json.NewEncoder(w).Encode(groups)
+
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/api/v1/users/") && strings.HasSuffix(path, "/groups"):
+		id := strings.TrimSuffix(strings.TrimPrefix(path, "/api/v1/users/"), "/groups")
+		groups := []group{{ID: "00g00000000000000000"}}
+		if slices.Contains(o.members, id) {
+			groups = append(groups, group{ID: testGroupID})
+		}
+		o.page(w, r, groups)
+
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/api/v1/users/"):
+		login := strings.TrimPrefix(path, "/api/v1/users/")
+		for id, l := range o.users {
+			if l == login {
+				u := user{ID: id}
+				u.Profile.Login = l
+				_ = json.NewEncoder(w).Encode(u)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"errorCode": "E0000007", "errorSummary": "Not found: Resource not found: " + login + " (User)"})
+
+	case r.Method == http.MethodGet && path == groupUsers:
+		users := []user{}
+		for _, id := range o.members {
+			u := user{ID: id}
+			u.Profile.Login = o.users[id]
+			users = append(users, u)
+		}
+		o.page(w, r, users)
+
+	case r.Method == http.MethodPut && strings.HasPrefix(path, groupUsers+"/"):
+		o.members = append(o.members, strings.TrimPrefix(path, groupUsers+"/"))
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, groupUsers+"/"):
+		id := strings.TrimPrefix(path, groupUsers+"/")
+		o.members = slices.DeleteFunc(o.members, func(m string) bool { return m == id })
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// writePage writes one item selected by "after" cursor and links the next page
+func writePage[T any](o *oktaAPI, w http.ResponseWriter, r *http.Request, items []T) {
+	after, _ := strconv.Atoi(r.URL.Query().Get("after"))
+	if after < len(items)-1 {
+		next := *r.URL
+		query := next.Query()
+		query.Set("after", strconv.Itoa(after+1))
+		next.RawQuery = query.Encode()
+		w.Header().Add("Link", fmt.Sprintf(`<%s%s>; rel="self"`, o.url, r.URL.RequestURI()))
+		w.Header().Add("Link", fmt.Sprintf(`<%s%s>; rel="next"`, o.url, next.RequestURI()))
+	}
+	if after >= len(items) {
+		_ = json.NewEncoder(w).Encode([]T{})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(items[after : after+1])
+}
+
+func (o *oktaAPI) page(w http.ResponseWriter, r *http.Request, items any) {
+	switch v := items.(type) {
+	case []group:
+		writePage(o, w, r, v)
+	case []user:
+		writePage(o, w, r, v)
+	}
+}
+
+// token validates client assertion and issues an access token
+func (o *oktaAPI) token(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(r.PostForm.Get("client_assertion"), claims, func(t *jwt.Token) (any, error) {
+		return o.publicKey, nil
+	})
+	if err != nil || claims.Subject != "client-id" || !claims.VerifyAudience(o.url+"/oauth2/v1/token", true) ||
+		r.PostForm.Get("grant_type") != "client_credentials" || r.PostForm.Get("scope") != oauthScopes {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]string{"errorSummary": "invalid client"})
+		return
+	}
+	o.tokens++
+	_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "access-token", "token_type": "Bearer", "expires_in": 3600})
+}
+
+func testProvider(t *testing.T, api *oktaAPI, creds map[string]string, parameters map[string]string) *OktaProvider {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	api.url = server.URL
+
+	data := map[string]string{"orgurl": server.URL}
+	for k, v := range creds {
+		data[k] = v
+	}
+	Config.Creds = map[string]models.Credential{
+		"okta": {Name: "okta", Data: data},
+	}
+
+	p, err := NewOktaProvider(context.Background(), models.ProviderConfig{
+		Name:          "OktaAdmins",
+		Provider:      "okta",
+		CredentialRef: models.CredentialRef{Name: "okta"},
+		Parameters:    parameters,
+	})
+	require.NoError(t, err)
+	return p
+}
+
+func testRequest(login string) *models.AccessRequest {
+	request := &models.AccessRequest{Id: "req-1"}
+	request.Status.ProviderUsernames = map[string]string{providerType: login}
+	return request
+}
+
+func TestGrantRevoke(t *testing.T) {
+	ctx := context.Background()
+	api := newOktaAPI("alice@example.com", "bob@example.com")
+	p := testProvider(t, api, map[string]string{"apitoken": testToken}, map[string]string{"group": "Admins"})
+
+	request := testRequest("alice@example.com")
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusGranted, request.Status.ProviderStatuses["OktaAdmins"].Action)
+
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, "already in group", request.Status.ProviderStatuses["OktaAdmins"].Error)
+
+	require.NoError(t, p.GrantAccess(ctx, testRequest("bob@example.com")))
+
+	users, err := p.ListUsersWithAccess(ctx, models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice@example.com", "bob@example.com"}, users)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusRevoked, request.Status.ProviderStatuses["OktaAdmins"].Action)
+	assert.Equal(t, []string{"00u2"}, api.members)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, "already removed from group", request.Status.ProviderStatuses["OktaAdmins"].Error)
+}
+
+func TestUnknownUserAndGroup(t *testing.T) {
+	ctx := context.Background()
+	api := newOktaAPI()
+	p := testProvider(t, api, map[string]string{"apitoken": testToken}, map[string]string{"group": testGroupID})
+
+	request := testRequest("mallory@example.com")
+	assert.ErrorIs(t, p.GrantAccess(ctx, request), errUserNotFound)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, "user not found", request.Status.ProviderStatuses["OktaAdmins"].Error)
+
+	p = testProvider(t, api, map[string]string{"apitoken": testToken}, map[string]string{"group": "Missing"})
+	assert.EqualError(t, p.GrantAccess(ctx, testRequest("alice@example.com")), "group Missing not found")
+}
+
+func TestRateLimit(t *testing.T) {
+	ctx := context.Background()
+	api := newOktaAPI("alice@example.com")
+	p := testProvider(t, api, map[string]string{"apitoken": testToken}, map[string]string{"group": testGroupID})
+
+	api.rateLimits = 1
+	require.NoError(t, p.GrantAccess(ctx, testRequest("alice@example.com")))
+	assert.Equal(t, []string{"00u1"}, api.members)
+
+	// Reset beyond maxWait fails without waiting
+	p.maxWait = 0
+	api.rateLimits = 1
+	request := testRequest("alice@example.com")
+	assert.Error(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, "failed to get user: unexpected status 429: rate limit exceeded", request.Status.ProviderStatuses["OktaAdmins"].Error)
+}
+
+func TestOAuthPrivateKey(t *testing.T) {
+	ctx := context.Background()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	api := newOktaAPI("alice@example.com")
+	api.publicKey = &key.PublicKey
+	p := testProvider(t, api, map[string]string{"clientid": "client-id", "privatekey": string(keyPEM)}, map[string]string{"group": testGroupID})
+
+	require.NoError(t, p.GrantAccess(ctx, testRequest("alice@example.com")))
+	users, err := p.ListUsersWithAccess(ctx, models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice@example.com"}, users)
+
+	// Access token is cached
+	assert.Equal(t, 1, api.tokens)
+}
+
+func TestRateLimitDelay(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	header := http.Header{}
+	header.Set("X-Rate-Limit-Reset", "1700000030")
+	assert.Equal(t, 31*time.Second, rateLimitDelay(header, now))
+
+	header.Set("X-Rate-Limit-Reset", "1699999990")
+	assert.Equal(t, time.Second, rateLimitDelay(header, now))
+
+	assert.Equal(t, time.Second, rateLimitDelay(http.Header{}, now))
+}
+
+func TestNextLink(t *testing.T) {
+	assert.Equal(t, "https://example.okta.com/api/v1/users?after=00u2", nextLink([]string{
+		`<https://example.okta.com/api/v1/users>; rel="self"`,
+		`<https://example.okta.com/api/v1/users?after=00u2>; rel="next"`,
+	}))
+	assert.Equal(t, "", nextLink([]string{`<https://example.okta.com/api/v1/users>; rel="self"`}))
+}
+
+func TestConfigErrors(t *testing.T) {
+	ctx := context.Background()
+	Config.Creds = map[string]models.Credential{
+		"okta":    {Name: "okta", Data: map[string]string{"orgurl": "https://example.okta.com", "apitoken": testToken}},
+		"noauth":  {Name: "noauth", Data: map[string]string{"orgurl": "https://example.okta.com"}},
+		"nourl":   {Name: "nourl", Data: map[string]string{"apitoken": testToken}},
+		"badkey":  {Name: "badkey", Data: map[string]string{"orgurl": "https://example.okta.com", "clientid": "client-id", "privatekey": "invalid"}},
+		"nofile":  {Name: "nofile", Data: map[string]string{"orgurl": "https://example.okta.com", "clientid": "client-id", "privatekeypath": "/nonexistent"}},
+		"keyonly": {Name: "keyonly", Data: map[string]string{"orgurl": "https://example.okta.com", "privatekeypath": "/nonexistent"}},
+	}
+
+	for _, config := range []models.ProviderConfig{
+		{CredentialRef: models.CredentialRef{Name: "okta"}, Parameters: map[string]string{}},
+		{CredentialRef: models.CredentialRef{Name: "okta"}, Parameters: map[string]string{"group": "Admins", "timeout": "soon"}},
+		{CredentialRef: models.CredentialRef{Name: "okta"}, Parameters: map[string]string{"group": "Admins", "maxRateLimitWait": "soon"}},
+		{CredentialRef: models.CredentialRef{Name: "noauth"}, Parameters: map[string]string{"group": "Admins"}},
+		{CredentialRef: models.CredentialRef{Name: "nourl"}, Parameters: map[string]string{"group": "Admins"}},
+		{CredentialRef: models.CredentialRef{Name: "badkey"}, Parameters: map[string]string{"group": "Admins"}},
+		{CredentialRef: models.CredentialRef{Name: "nofile"}, Parameters: map[string]string{"group": "Admins"}},
+		{CredentialRef: models.CredentialRef{Name: "keyonly"}, Parameters: map[string]string{"group": "Admins"}},
+	} {
+		_, err := NewOktaProvider(ctx, config)
+		assert.Error(t, err, fmt.Sprint(config.CredentialRef.Name, config.Parameters))
+	}
+}
@@ -0,0 +1,36 @@
+package okta
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:             string(kinds.ProviderKindOkta),
+		RequiresUsername: true,
+		Description:      "Manages Okta group membership. Users are resolved by login",
+		Parameters: []registry.Parameter{
+			{Name: "group", Description: "Group id or exact group name", Required: true, Example: "Engineering Admins"},
+			{Name: "maxRateLimitWait", Description: "Maximum time a call waits for rate limit reset before failing. Defaults to 1m", Example: "2m"},
+			{Name: "timeout", Description: "HTTP request timeout. Defaults to 30s", Example: "10s"},
+		},
+		Credentials: []registry.Parameter{
+			{Name: "orgurl", Description: "Okta organization URL", Required: true, Example: "https://exampleorg.okta.com"},
+			{Name: "apitoken", Description: "API token. Either apitoken or clientid with private key is required"},
+			{Name: "clientid", Description: "Client id of an API service app granted okta.users.read and okta.groups.manage scopes", Example: "0oa1b2c3d4e5f6g7h8i9"},
+			{Name: "privatekey", Description: "PEM encoded RSA private key of the service app"},
+			{Name: "privatekeypath", Description: "Path to PEM encoded RSA private key of the service app", Example: "creds/okta.pem"},
+			{Name: "keyid", Description: "Key id of the service app public key", Example: "kid-2024"},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewOktaProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
@@ -0,0 +1,346 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/config"
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/tracing"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+var Config = config.GetConfig()
+
+// Parameters consumed by the plugin provider itself. They are not passed to the plugin
+var pluginParameters = []string{"address", "command", "args", "timeout", "startTimeout"}
+
+// PluginProvider forwards provider calls to an out-of-process plugin over gRPC
+type PluginProvider struct {
+	conn    *grpc.ClientConn
+	config  PluginConfig
+	timeout time.Duration
+	name    string
+}
+
+// NewPluginProvider connects to plugin at address or launches plugin command as subprocess
+func NewPluginProvider(ctx context.Context, config models.ProviderConfig) (*PluginProvider, error) {
+
+	data := config.Parameters
+	address := data["address"]
+	command := data["command"]
+
+	if address == "" && command == "" {
+		return nil, errors.New("address or command not found in provider config")
+	}
+	if address != "" && command != "" {
+		return nil, errors.New("address and command are mutually exclusive")
+	}
+	// Config and credentials are sent in plain text, so only local sockets are accepted
+	if address != "" && !strings.HasPrefix(address, unixScheme) {
+		return nil, fmt.Errorf("address %s is not a unix socket. Use %s/path/to/socket", address, unixScheme)
+	}
+
+	timeout, err := parseDuration(data["timeout"], 60*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeout: %w", err)
+	}
+
+	startTimeout, err := parseDuration(data["startTimeout"], 10*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("invalid startTimeout: %w", err)
+	}
+
+	var conn *grpc.ClientConn
+	if address != "" {
+		conn, err = pool.dial(address)
+	} else {
+		conn, err = pool.launch(ctx, command, strings.Fields(data["args"]), startTimeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	parameters := maps.Clone(data)
+	for _, key := range pluginParameters {
+		delete(parameters, key)
+	}
+
+	creds := Config.GetCredentials(config.CredentialRef.Name)
+
+	return &PluginProvider{
+		conn: conn,
+		config: PluginConfig{
+			Name:        config.Name,
+			Parameters:  parameters,
+			Credentials: creds.Data,
+		},
+		timeout: timeout,
+		name:    config.Name,
+	}, nil
+}
+
+// GrantAccess forwards grant to the plugin and records plugin status on the request
+func (p *PluginProvider) GrantAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "GrantAccess")
+	defer span.End()
+
+	if err := p.access(ctx, "GrantAccess", request); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Msg("Plugin granted access")
+
+	return nil
+}
+
+// RevokeAccess forwards revoke to the plugin and records plugin status on the request
+func (p *PluginProvider) RevokeAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "RevokeAccess")
+	defer span.End()
+
+	if err := p.access(ctx, "RevokeAccess", request); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Msg("Plugin revoked access")
+
+	return nil
+}
+
+// ListUsersWithAccess lists users reported by the plugin
+func (p *PluginProvider) ListUsersWithAccess(ctx context.Context, roleRef models.AccessRoleRef) ([]string, error) {
+	ctx, span := startSpan(ctx, "ListUsersWithAccess")
+	defer span.End()
+
+	response := ListUsersResponse{}
+	err := p.invoke(ctx, "ListUsersWithAccess", &ListUsersMessage{Config: p.config, Role: roleRef}, &response)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", p.name, err)
+	}
+
+	return response.Usernames, nil
+}
+
+// IsAccessExpired asks the plugin whether access has expired
+func (p *PluginProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
+	ctx, span := startSpan(ctx, "IsAccessExpired")
+	defer span.End()
+
+	response := ExpiredResponse{}
+	err := p.invoke(ctx, "IsAccessExpired", &AccessRequestMessage{Config: p.config, Request: *request}, &response)
+	if err != nil {
+		return false, fmt.Errorf("plugin %s: %w", p.name, err)
+	}
+
+	return response.Expired, nil
+}
+
+func (p *PluginProvider) access(ctx context.Context, method string, request *models.AccessRequest) error {
+
+	response := AccessResponse{}
+	err := p.invoke(ctx, method, &AccessRequestMessage{Config: p.config, Request: *request}, &response)
+	if err != nil {
+		request.SetProviderStatusError(p.name, "", err.Error())
+		return fmt.Errorf("plugin %s: %w", p.name, err)
+	}
+
+	if response.Status.Action != "" {
+		if request.Status.ProviderStatuses == nil {
+			request.Status.ProviderStatuses = make(map[string]models.ProviderStatus)
+		}
+		request.Status.ProviderStatuses[p.name] = response.Status
+	}
+
+	if response.Error != "" {
+		if response.Status.Action == "" {
+			request.SetProviderStatusError(p.name, "", response.Error)
+		}
+		return fmt.Errorf("plugin %s: %s", p.name, response.Error)
+	}
+
+	return nil
+}
+
+func (p *PluginProvider) invoke(ctx context.Context, method string, in any, out any) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	return p.conn.Invoke(ctx, fullMethod(method), in, out)
+}
+
+// connPool shares plugin connections and processes between provider instances
+type connPool struct {
+	mu        sync.Mutex
+	conns     map[string]*grpc.ClientConn
+	processes map[string]*os.Process
+	sockets   atomic.Int64
+}
+
+var pool = &connPool{
+	conns:     map[string]*grpc.ClientConn{},
+	processes: map[string]*os.Process{},
+}
+
+// Shutdown closes plugin connections and stops launched plugin processes
+func Shutdown() {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	for key, conn := range pool.conns {
+		_ = conn.Close()
+		delete(pool.conns, key)
+	}
+	for key, process := range pool.processes {
+		_ = process.Kill()
+		delete(pool.processes, key)
+	}
+}
+
+func (c *connPool) dial(address string) (*grpc.ClientConn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if conn, ok := c.conns[address]; ok {
+		return conn, nil
+	}
+
+	conn, err := newClientConn(address)
+	if err != nil {
+		return nil, err
+	}
+	c.conns[address] = conn
+	return conn, nil
+}
+
+// launch starts plugin command once and reuses it until the process exits
+func (c *connPool) launch(ctx context.Context, command string, args []string, startTimeout time.Duration) (*grpc.ClientConn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := strings.Join(append([]string{command}, args...), " ")
+	if conn, ok := c.conns[key]; ok {
+		return conn, nil
+	}
+
+	socket := filepath.Join(os.TempDir(), fmt.Sprintf("passage-plugin-%d-%d.sock", os.Getpid(), c.sockets.Add(1)))
+	address := unixScheme + socket
+
+	cmd := exec.Command(command, args...)
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("%s=%s", EnvAddress, address),
+		fmt.Sprintf("%s=%d", EnvParentPid, os.Getpid()),
+	)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start plugin %s: %w", command, err)
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		err := cmd.Wait()
+		close(exited)
+		log.Warn().
+			Str("Plugin", command).
+			Err(err).
+			Msg("Plugin process exited")
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if conn, ok := c.conns[key]; ok {
+			_ = conn.Close()
+			delete(c.conns, key)
+		}
+		delete(c.processes, key)
+		_ = os.Remove(socket)
+	}()
+
+	if err := waitForSocket(ctx, socket, exited, startTimeout); err != nil {
+		_ = cmd.Process.Kill()
+		return nil, fmt.Errorf("plugin %s: %w", command, err)
+	}
+
+	conn, err := newClientConn(address)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		return nil, err
+	}
+
+	log.Info().
+		Str("Plugin", command).
+		Str("Address", address).
+		Int("Pid", cmd.Process.Pid).
+		Msg("Plugin process started")
+
+	c.conns[key] = conn
+	c.processes[key] = cmd.Process
+	return conn, nil
+}
+
+func waitForSocket(ctx context.Context, socket string, exited <-chan struct{}, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		if _, err := os.Stat(socket); err == nil {
+			return nil
+		}
+		select {
+		case <-exited:
+			return errors.New("process exited before listening")
+		case <-ctx.Done():
+			return fmt.Errorf("not listening on %s after %s", socket, timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+func newClientConn(address string) (*grpc.ClientConn, error) {
+	conn, err := grpc.NewClient(address,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to plugin %s: %w", address, err)
+	}
+	return conn, nil
+}
+
+func parseDuration(value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return time.ParseDuration(value)
+}
+
+func startSpan(ctx context.Context, name string) (context.Context, *tracing.SpanWrapper) {
+	ctx, span := tracing.NewSpanWrapper(ctx, fmt.Sprintf("providers.plugin.%s", name))
+	return ctx, span
+}
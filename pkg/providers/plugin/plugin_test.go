@@ -0,0 +1,186 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const envTestPlugin = "PASSAGE_PLUGIN_TEST"
+
+// TestMain serves test plugin when the test binary is launched as plugin subprocess
+func TestMain(m *testing.M) {
+	if os.Getenv(envTestPlugin) == "1" {
+		if err := Serve(testFactory(nil), ""); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+type testPlugin struct {
+	config PluginConfig
+}
+
+type testCall struct {
+	config PluginConfig
+	trace  trace.SpanContext
+}
+
+func testFactory(calls chan<- testCall) Factory {
+	return func(ctx context.Context, config PluginConfig) (registry.Provider, error) {
+		if calls != nil {
+			calls <- testCall{config: config, trace: trace.SpanContextFromContext(ctx)}
+		}
+		return &testPlugin{config: config}, nil
+	}
+}
+
+func (p *testPlugin) GrantAccess(ctx context.Context, request *models.AccessRequest) error {
+	if request.Details.Justification == "fail" {
+		request.SetProviderStatusError(p.config.Name, "team-a", "user not found")
+		return errors.New("user not found")
+	}
+	request.SetProviderStatusGranted(p.config.Name, p.config.Parameters["team"], request.Details.Justification)
+	return nil
+}
+
+func (p *testPlugin) RevokeAccess(ctx context.Context, request *models.AccessRequest) error {
+	return errors.New("revoke not supported")
+}
+
+func (p *testPlugin) ListUsersWithAccess(ctx context.Context, roleRef models.AccessRoleRef) ([]string, error) {
+	return []string{"alice", roleRef.Name}, nil
+}
+
+func (p *testPlugin) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
+	return request.Id == "expired", nil
+}
+
+func startTestServer(t *testing.T, calls chan<- testCall) string {
+	address := "unix://" + filepath.Join(t.TempDir(), "plugin.sock")
+	listener, err := listen(address)
+	require.NoError(t, err)
+
+	server := NewServer(testFactory(calls))
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	return address
+}
+
+func testProvider(t *testing.T, parameters map[string]string) *PluginProvider {
+	Config.Creds = map[string]models.Credential{
+		"plugin": {
+			Name: "plugin",
+			Data: map[string]string{"token": "secret"},
+		},
+	}
+
+	p, err := NewPluginProvider(context.Background(), models.ProviderConfig{
+		Name:          "Plugin",
+		Provider:      "plugin",
+		CredentialRef: models.CredentialRef{Name: "plugin"},
+		Parameters:    parameters,
+	})
+	require.NoError(t, err)
+	return p
+}
+
+func TestPluginAddress(t *testing.T) {
+	calls := make(chan testCall, 10)
+	address := startTestServer(t, calls)
+	p := testProvider(t, map[string]string{"address": address, "team": "team-a", "timeout": "5s"})
+	ctx := context.Background()
+
+	request := &models.AccessRequest{Id: "req-1"}
+	request.Details.Justification = "on call"
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatus{Action: models.ProviderStatusGranted, Details: "team-a", Error: "on call"}, request.Status.ProviderStatuses["Plugin"])
+
+	call := <-calls
+	assert.Equal(t, "Plugin", call.config.Name)
+	assert.Equal(t, map[string]string{"team": "team-a"}, call.config.Parameters, "plugin parameters are not forwarded")
+	assert.Equal(t, map[string]string{"token": "secret"}, call.config.Credentials)
+
+	request.Details.Justification = "fail"
+	err := p.GrantAccess(ctx, request)
+	assert.ErrorContains(t, err, "user not found")
+	assert.Equal(t, models.ProviderStatusError, request.Status.ProviderStatuses["Plugin"].Action)
+
+	err = p.RevokeAccess(ctx, request)
+	assert.ErrorContains(t, err, "revoke not supported")
+	assert.Equal(t, models.ProviderStatus{Action: models.ProviderStatusError, Error: "revoke not supported"}, request.Status.ProviderStatuses["Plugin"])
+
+	users, err := p.ListUsersWithAccess(ctx, models.AccessRoleRef{Name: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, users)
+
+	expired, err := p.IsAccessExpired(ctx, &models.AccessRequest{Id: "expired"})
+	require.NoError(t, err)
+	assert.True(t, expired)
+}
+
+func TestPluginTracePropagation(t *testing.T) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	calls := make(chan testCall, 10)
+	p := testProvider(t, map[string]string{"address": startTestServer(t, calls)})
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "test")
+	defer span.End()
+
+	_, err := p.IsAccessExpired(ctx, &models.AccessRequest{})
+	require.NoError(t, err)
+
+	call := <-calls
+	assert.Equal(t, span.SpanContext().TraceID(), call.trace.TraceID())
+}
+
+func TestPluginCommand(t *testing.T) {
+	t.Setenv(envTestPlugin, "1")
+	t.Cleanup(Shutdown)
+
+	p := testProvider(t, map[string]string{"command": os.Args[0], "startTimeout": "10s"})
+
+	users, err := p.ListUsersWithAccess(context.Background(), models.AccessRoleRef{Name: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, users)
+
+	// Plugin process is shared between providers
+	other := testProvider(t, map[string]string{"command": os.Args[0]})
+	assert.Same(t, p.conn, other.conn)
+}
+
+func TestPluginConfigErrors(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewPluginProvider(ctx, models.ProviderConfig{Name: "Plugin", Parameters: map[string]string{}})
+	assert.Error(t, err)
+
+	_, err = NewPluginProvider(ctx, models.ProviderConfig{Name: "Plugin", Parameters: map[string]string{"address": "a", "command": "b"}})
+	assert.Error(t, err)
+
+	// Credentials are not sent over unencrypted TCP
+	_, err = NewPluginProvider(ctx, models.ProviderConfig{Name: "Plugin", Parameters: map[string]string{"address": "localhost:50051"}})
+	assert.ErrorContains(t, err, "not a unix socket")
+	_, err = listen("localhost:0")
+	assert.Error(t, err)
+
+	_, err = NewPluginProvider(ctx, models.ProviderConfig{Name: "Plugin", Parameters: map[string]string{"command": filepath.Join(t.TempDir(), "missing")}})
+	assert.Error(t, err)
+}
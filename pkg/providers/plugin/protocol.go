@@ -0,0 +1,110 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"google.golang.org/grpc"
+)
+
+// Plugin protocol. Messages are JSON encoded, plugins written in other languages
+// must accept the application/grpc+json content type.
+//
+//	service passage.provider.v1.Provider {
+//	  rpc GrantAccess(AccessRequestMessage) returns (AccessResponse);
+//	  rpc RevokeAccess(AccessRequestMessage) returns (AccessResponse);
+//	  rpc ListUsersWithAccess(ListUsersMessage) returns (ListUsersResponse);
+//	  rpc IsAccessExpired(AccessRequestMessage) returns (ExpiredResponse);
+//	}
+const ServiceName = "passage.provider.v1.Provider"
+
+// PluginConfig is passed to the plugin with every call
+type PluginConfig struct {
+	Name        string            `json:"name"`
+	Parameters  map[string]string `json:"parameters"`
+	Credentials map[string]string `json:"credentials"`
+}
+
+type AccessRequestMessage struct {
+	Config  PluginConfig         `json:"config"`
+	Request models.AccessRequest `json:"request"`
+}
+
+// AccessResponse carries provider status set by the plugin. Error is set when the provider call failed
+type AccessResponse struct {
+	Status models.ProviderStatus `json:"status"`
+	Error  string                `json:"error,omitempty"`
+}
+
+type ListUsersMessage struct {
+	Config PluginConfig         `json:"config"`
+	Role   models.AccessRoleRef `json:"role"`
+}
+
+type ListUsersResponse struct {
+	Usernames []string `json:"usernames"`
+}
+
+type ExpiredResponse struct {
+	Expired bool `json:"expired"`
+}
+
+// providerServer is implemented by the plugin side of the protocol
+type providerServer interface {
+	GrantAccess(ctx context.Context, in *AccessRequestMessage) (*AccessResponse, error)
+	RevokeAccess(ctx context.Context, in *AccessRequestMessage) (*AccessResponse, error)
+	ListUsersWithAccess(ctx context.Context, in *ListUsersMessage) (*ListUsersResponse, error)
+	IsAccessExpired(ctx context.Context, in *AccessRequestMessage) (*ExpiredResponse, error)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*providerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "GrantAccess", Handler: unaryHandler("GrantAccess", providerServer.GrantAccess)},
+		{MethodName: "RevokeAccess", Handler: unaryHandler("RevokeAccess", providerServer.RevokeAccess)},
+		{MethodName: "ListUsersWithAccess", Handler: unaryHandler("ListUsersWithAccess", providerServer.ListUsersWithAccess)},
+		{MethodName: "IsAccessExpired", Handler: unaryHandler("IsAccessExpired", providerServer.IsAccessExpired)},
+	},
+	Metadata: "passage/provider/v1/provider.proto",
+}
+
+func unaryHandler[Req any, Resp any](method string, call func(providerServer, context.Context, *Req) (*Resp, error)) grpc.MethodHandler {
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		in := new(Req)
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+		if interceptor == nil {
+			return call(srv.(providerServer), ctx, in)
+		}
+		info := &grpc.UnaryServerInfo{
+			Server:     srv,
+			FullMethod: fullMethod(method),
+		}
+		return interceptor(ctx, in, info, func(ctx context.Context, req any) (any, error) {
+			return call(srv.(providerServer), ctx, req.(*Req))
+		})
+	}
+}
+
+func fullMethod(method string) string {
+	return "/" + ServiceName + "/" + method
+}
+
+// jsonCodec encodes protocol messages as JSON
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
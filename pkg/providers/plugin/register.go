@@ -0,0 +1,29 @@
+package plugin
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:        string(kinds.ProviderKindPlugin),
+		Description: "Forwards provider calls to an out-of-process plugin over gRPC. Remaining parameters and credentials are passed to the plugin",
+		Parameters: []registry.Parameter{
+			{Name: "address", Description: "Unix socket of a running plugin. Either address or command is required", Example: "unix:///run/passage/plugin.sock"},
+			{Name: "command", Description: "Plugin binary launched as subprocess", Example: "/usr/local/bin/passage-plugin-example"},
+			{Name: "args", Description: "Space separated plugin command arguments", Example: "--verbose"},
+			{Name: "timeout", Description: "Plugin call timeout", Example: "60s"},
+			{Name: "startTimeout", Description: "Time to wait for launched plugin to listen", Example: "10s"},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewPluginProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
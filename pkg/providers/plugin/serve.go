@@ -0,0 +1,146 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+)
+
+const (
+	// Address plugin should listen on. Set by the server when plugin is launched as subprocess
+	EnvAddress = "PASSAGE_PLUGIN_ADDRESS"
+	// Server process id. Plugin exits when the server is gone
+	EnvParentPid = "PASSAGE_PLUGIN_PARENT_PID"
+)
+
+// Plugins are reachable only over unix sockets as calls are not encrypted
+const unixScheme = "unix://"
+
+// Factory creates provider handling a single plugin call
+type Factory func(ctx context.Context, config PluginConfig) (registry.Provider, error)
+
+// NewServer returns gRPC server exposing providers created by factory
+func NewServer(factory Factory) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ForceServerCodec(jsonCodec{}),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+	)
+	server.RegisterService(&serviceDesc, &pluginServer{factory: factory})
+	return server
+}
+
+// Serve runs plugin server on address. Empty address is read from PASSAGE_PLUGIN_ADDRESS.
+// Address must be a unix socket, e.g. unix:///path/to/socket
+func Serve(factory Factory, address string) error {
+
+	if address == "" {
+		address = os.Getenv(EnvAddress)
+	}
+	if address == "" {
+		return fmt.Errorf("plugin address is not set. Set %s", EnvAddress)
+	}
+
+	listener, err := listen(address)
+	if err != nil {
+		return err
+	}
+
+	server := NewServer(factory)
+	go watchParent(server)
+
+	return server.Serve(listener)
+}
+
+func listen(address string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(address, unixScheme)
+	if !ok {
+		return nil, fmt.Errorf("plugin address %s is not a unix socket", address)
+	}
+	_ = os.Remove(path)
+	return net.Listen("unix", path)
+}
+
+// watchParent stops server once the launching process exits
+func watchParent(server *grpc.Server) {
+	parent, err := strconv.Atoi(os.Getenv(EnvParentPid))
+	if err != nil {
+		return
+	}
+	for range time.Tick(5 * time.Second) {
+		if os.Getppid() != parent {
+			server.Stop()
+			return
+		}
+	}
+}
+
+type pluginServer struct {
+	factory Factory
+}
+
+func (s *pluginServer) GrantAccess(ctx context.Context, in *AccessRequestMessage) (*AccessResponse, error) {
+	return s.call(ctx, in, func(p registry.Provider, request *models.AccessRequest) error {
+		return p.GrantAccess(ctx, request)
+	})
+}
+
+func (s *pluginServer) RevokeAccess(ctx context.Context, in *AccessRequestMessage) (*AccessResponse, error) {
+	return s.call(ctx, in, func(p registry.Provider, request *models.AccessRequest) error {
+		return p.RevokeAccess(ctx, request)
+	})
+}
+
+func (s *pluginServer) ListUsersWithAccess(ctx context.Context, in *ListUsersMessage) (*ListUsersResponse, error) {
+	provider, err := s.factory(ctx, in.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	usernames, err := provider.ListUsersWithAccess(ctx, in.Role)
+	if err != nil {
+		return nil, err
+	}
+	return &ListUsersResponse{Usernames: usernames}, nil
+}
+
+func (s *pluginServer) IsAccessExpired(ctx context.Context, in *AccessRequestMessage) (*ExpiredResponse, error) {
+	provider, err := s.factory(ctx, in.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	expired, err := provider.IsAccessExpired(ctx, &in.Request)
+	if err != nil {
+		return nil, err
+	}
+	return &ExpiredResponse{Expired: expired}, nil
+}
+
+func (s *pluginServer) call(ctx context.Context, in *AccessRequestMessage, method func(registry.Provider, *models.AccessRequest) error) (*AccessResponse, error) {
+	provider, err := s.factory(ctx, in.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Report only status set by this call
+	request := in.Request
+	delete(request.Status.ProviderStatuses, in.Config.Name)
+
+	response := &AccessResponse{}
+	if err := method(provider, &request); err != nil {
+		response.Error = err.Error()
+	}
+	response.Status = request.Status.ProviderStatuses[in.Config.Name]
+
+	return response, nil
+}
@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const passwordAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+func (p *PostgresProvider) roleExists(ctx context.Context, username string) (bool, error) {
+	var exists bool
+	err := p.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = $1)", username).Scan(&exists)
+	return exists, err
+}
+
+func (p *PostgresProvider) isMember(ctx context.Context, username string) (bool, error) {
+	var exists bool
+	err := p.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM pg_auth_members am
+			JOIN pg_roles r ON r.oid = am.roleid
+			JOIN pg_roles m ON m.oid = am.member
+			WHERE r.rolname = $1 AND m.rolname = $2
+		)`, p.role, username).Scan(&exists)
+	return exists, err
+}
+
+func (p *PostgresProvider) isManaged(ctx context.Context, username string) (bool, error) {
+	var comment sql.NullString
+	err := p.db.QueryRowContext(ctx, "SELECT shobj_description(oid, 'pg_authid') FROM pg_roles WHERE rolname = $1", username).Scan(&comment)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return comment.String == managedComment, err
+}
+
+// createLogin creates login role valid until request expiration
+func (p *PostgresProvider) createLogin(ctx context.Context, username string, password string, expiresAt *time.Time) error {
+	statement := fmt.Sprintf("CREATE ROLE %s WITH LOGIN PASSWORD %s", quoteIdent(username), quoteLiteral(password))
+	if expiresAt != nil {
+		statement += " VALID UNTIL " + quoteLiteral(expiresAt.UTC().Format(time.RFC3339))
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, statement); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("COMMENT ON ROLE %s IS %s", quoteIdent(username), quoteLiteral(managedComment))); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// extendLogin moves VALID UNTIL of a managed login role to request expiration
+func (p *PostgresProvider) extendLogin(ctx context.Context, username string, expiresAt *time.Time) error {
+	managed, err := p.isManaged(ctx, username)
+	if err != nil || !managed {
+		return err
+	}
+
+	validUntil := "infinity"
+	if expiresAt != nil {
+		validUntil = expiresAt.UTC().Format(time.RFC3339)
+	}
+
+	// Never shorten validity granted by another active request
+	var current sql.NullTime
+	if err := p.db.QueryRowContext(ctx, "SELECT rolvaliduntil FROM pg_roles WHERE rolname = $1", username).Scan(&current); err != nil {
+		return err
+	}
+	if current.Valid && expiresAt != nil && current.Time.After(*expiresAt) {
+		return nil
+	}
+
+	_, err = p.db.ExecContext(ctx, fmt.Sprintf("ALTER ROLE %s VALID UNTIL %s", quoteIdent(username), quoteLiteral(validUntil)))
+	return err
+}
+
+// dropLogin drops managed login role when it is no longer member of any role
+func (p *PostgresProvider) dropLogin(ctx context.Context, username string) (bool, error) {
+	managed, err := p.isManaged(ctx, username)
+	if err != nil || !managed {
+		return false, err
+	}
+
+	var memberships int
+	err = p.db.QueryRowContext(ctx, `
+		SELECT count(*)
+		FROM pg_auth_members am
+		JOIN pg_roles m ON m.oid = am.member
+		WHERE m.rolname = $1`, username).Scan(&memberships)
+	if err != nil {
+		return false, err
+	}
+	if memberships > 0 {
+		return false, nil
+	}
+
+	if _, err := p.db.ExecContext(ctx, "DROP ROLE "+quoteIdent(username)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func quoteIdent(name string) string {
+	return pgx.Identifier{name}.Sanitize()
+}
+
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func generatePassword() (string, error) {
+	password := make([]byte, 32)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(passwordAlphabet))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate password: %w", err)
+		}
+		password[i] = passwordAlphabet[n.Int64()]
+	}
+	return string(password), nil
+}
+
+func parseBool(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
@@ -0,0 +1,280 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/config"
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/tracing"
+
+	"github.com/jackc/pgx/v5"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/rs/zerolog/log"
+)
+
+const providerType = string(kinds.ProviderKindPostgres)
+
+// Comment marking login roles created by passage. Only those are dropped on revoke
+const managedComment = "managed by passage"
+
+var Config = config.GetConfig()
+
+// Connection pools shared between provider instances
+var (
+	poolsMu sync.Mutex
+	pools   = map[string]*sql.DB{}
+)
+
+// PostgresProvider grants database role membership
+type PostgresProvider struct {
+	db *sql.DB
+
+	// provider name as defined in the provider configuration
+	name string
+	// database role granted to the user
+	role string
+	// create login role with generated password when user does not exist
+	createUser bool
+
+	host     string
+	port     string
+	database string
+}
+
+// NewPostgresProvider initializes a new PostgresProvider with connection from CredentialRef
+func NewPostgresProvider(ctx context.Context, config models.ProviderConfig) (*PostgresProvider, error) {
+	creds := Config.GetCredentials(config.CredentialRef.Name)
+
+	dsn := creds.GetString("dsn")
+	if dsn == "" {
+		return nil, errors.New("dsn not found in credentials")
+	}
+
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dsn: %w", err)
+	}
+
+	role := config.Parameters["role"]
+	if role == "" {
+		return nil, errors.New("role not found in provider config")
+	}
+
+	createUser, err := parseBool(config.Parameters["createUser"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid createUser: %w", err)
+	}
+
+	db, err := openDB(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresProvider{
+		db:         db,
+		name:       config.Name,
+		role:       role,
+		createUser: createUser,
+		host:       connConfig.Host,
+		port:       strconv.Itoa(int(connConfig.Port)),
+		database:   connConfig.Database,
+	}, nil
+}
+
+func openDB(dsn string) (*sql.DB, error) {
+	poolsMu.Lock()
+	defer poolsMu.Unlock()
+
+	if db, ok := pools[dsn]; ok {
+		return db, nil
+	}
+
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db.SetMaxOpenConns(4)
+	db.SetConnMaxIdleTime(5 * time.Minute)
+
+	pools[dsn] = db
+	return db, nil
+}
+
+// GrantAccess grants role to the user. Login role is created first when createUser is enabled
+func (p *PostgresProvider) GrantAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "GrantAccess")
+	defer span.End()
+
+	username := request.GetProviderUsername(providerType)
+	if username == "" {
+		err := errors.New("postgres username is not set")
+		request.SetProviderStatusError(p.name, p.role, err.Error())
+		return err
+	}
+
+	exists, err := p.roleExists(ctx, username)
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.role, err.Error())
+		return fmt.Errorf("failed to check user: %w", err)
+	}
+
+	switch {
+	case !exists && !p.createUser:
+		err := fmt.Errorf("user %s does not exist", username)
+		request.SetProviderStatusError(p.name, p.role, err.Error())
+		return err
+	case !exists:
+		password, err := generatePassword()
+		if err != nil {
+			request.SetProviderStatusError(p.name, p.role, err.Error())
+			return err
+		}
+		if err := p.createLogin(ctx, username, password, request.Status.ExpiresAt); err != nil {
+			request.SetProviderStatusError(p.name, p.role, err.Error())
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		request.AddCredential(p.name, models.AccessCredentialPassword, map[string]string{
+			"host":     p.host,
+			"port":     p.port,
+			"database": p.database,
+			"username": username,
+			"password": password,
+		}, request.Status.ExpiresAt)
+	case p.createUser:
+		if err := p.extendLogin(ctx, username, request.Status.ExpiresAt); err != nil {
+			request.SetProviderStatusError(p.name, p.role, err.Error())
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+	}
+
+	isMember, err := p.isMember(ctx, username)
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.role, err.Error())
+		return fmt.Errorf("failed to check role membership: %w", err)
+	}
+
+	if isMember {
+		request.SetProviderStatusGranted(p.name, p.role, "already granted")
+		return nil
+	}
+
+	if _, err := p.db.ExecContext(ctx, fmt.Sprintf("GRANT %s TO %s", quoteIdent(p.role), quoteIdent(username))); err != nil {
+		request.SetProviderStatusError(p.name, p.role, err.Error())
+		return fmt.Errorf("failed to grant role: %w", err)
+	}
+
+	request.SetProviderStatusGranted(p.name, p.role, "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", username).
+		Str("Role", p.role).
+		Msg("Role granted")
+
+	return nil
+}
+
+// RevokeAccess revokes role from the user. Login roles created by passage are dropped once they hold no roles
+func (p *PostgresProvider) RevokeAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "RevokeAccess")
+	defer span.End()
+
+	username := request.GetProviderUsername(providerType)
+
+	exists, err := p.roleExists(ctx, username)
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.role, err.Error())
+		return fmt.Errorf("failed to check user: %w", err)
+	}
+
+	if !exists {
+		request.SetProviderStatusRevoked(p.name, p.role, "user does not exist")
+		return nil
+	}
+
+	isMember, err := p.isMember(ctx, username)
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.role, err.Error())
+		return fmt.Errorf("failed to check role membership: %w", err)
+	}
+
+	if isMember {
+		if _, err := p.db.ExecContext(ctx, fmt.Sprintf("REVOKE %s FROM %s", quoteIdent(p.role), quoteIdent(username))); err != nil {
+			request.SetProviderStatusError(p.name, p.role, err.Error())
+			return fmt.Errorf("failed to revoke role: %w", err)
+		}
+	}
+
+	details := ""
+	if p.createUser {
+		dropped, err := p.dropLogin(ctx, username)
+		if err != nil {
+			request.SetProviderStatusError(p.name, p.role, err.Error())
+			return fmt.Errorf("failed to drop user: %w", err)
+		}
+		if dropped {
+			details = "user dropped"
+		}
+	}
+
+	request.SetProviderStatusRevoked(p.name, p.role, details)
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", username).
+		Str("Role", p.role).
+		Msg("Role revoked")
+
+	return nil
+}
+
+// ListUsersWithAccess lists direct members of the provider role
+func (p *PostgresProvider) ListUsersWithAccess(ctx context.Context, roleRef models.AccessRoleRef) ([]string, error) {
+	ctx, span := startSpan(ctx, "ListUsersWithAccess")
+	defer span.End()
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT m.rolname
+		FROM pg_auth_members am
+		JOIN pg_roles r ON r.oid = am.roleid
+		JOIN pg_roles m ON m.oid = am.member
+		WHERE r.rolname = $1
+		ORDER BY m.rolname`, p.role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role members: %w", err)
+	}
+	defer rows.Close()
+
+	usernames := []string{}
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, err
+		}
+		usernames = append(usernames, username)
+	}
+
+	return usernames, rows.Err()
+}
+
+// IsAccessExpired checks whether the access for the given request has expired
+func (p *PostgresProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
+	_, span := startSpan(ctx, "IsAccessExpired")
+	defer span.End()
+
+	return request.IsExpired(time.Now())
+}
+
+func startSpan(ctx context.Context, name string) (context.Context, *tracing.SpanWrapper) {
+	ctx, span := tracing.NewSpanWrapper(ctx, fmt.Sprintf("providers.postgres.%s", name))
+	return ctx, span
+}
@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:             string(kinds.ProviderKindPostgres),
+		RequiresUsername: true,
+		Description:      "Grants PostgreSQL role membership. Optionally creates a login role with generated password valid until request expiration",
+		Parameters: []registry.Parameter{
+			{Name: "role", Description: "Database role granted to the user", Required: true, Example: "app_readonly"},
+			{Name: "createUser", Description: "Create login role with generated password when user does not exist. Created users are dropped on revoke", Example: "true"},
+		},
+		Credentials: []registry.Parameter{
+			{Name: "dsn", Description: "Connection string of a role with CREATEROLE privilege", Required: true, Example: "postgres://passage@db.example.com:5432/app?sslmode=require"},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewPostgresProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
@@ -2,57 +2,62 @@ package providers
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/CTO2BPublic/passage-server/pkg/models"
-	"github.com/CTO2BPublic/passage-server/pkg/providers/aws"
-	"github.com/CTO2BPublic/passage-server/pkg/providers/cloudflare"
-	"github.com/CTO2BPublic/passage-server/pkg/providers/github"
-	"github.com/CTO2BPublic/passage-server/pkg/providers/gitlab"
-	"github.com/CTO2BPublic/passage-server/pkg/providers/google"
-	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
-	mockp "github.com/CTO2BPublic/passage-server/pkg/providers/mock"
-	"github.com/CTO2BPublic/passage-server/pkg/providers/teleport"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+
+	// Providers register themselves in the registry
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/atlassian"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/aws"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/awssts"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/cloudflare"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/entra"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/exec"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/github"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/gitlab"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/google"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/kubernetes"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/ldap"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/mock"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/mysql"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/okta"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/plugin"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/postgres"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/scim"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/sshca"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/teleport"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/vault"
+	_ "github.com/CTO2BPublic/passage-server/pkg/providers/webhook"
 )
 
-type Provider interface {
-	GrantAccess(ctx context.Context, request *models.AccessRequest) error
-	RevokeAccess(ctx context.Context, request *models.AccessRequest) error
-	ListUsersWithAccess(ctx context.Context, role models.AccessRoleRef) ([]string, error)
-	IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error)
-}
+type Provider = registry.Provider
+
+type RequestScoped = registry.RequestScoped
+
+var ErrRevokeUnsupported = registry.ErrRevokeUnsupported
 
 func NewProvider(ctx context.Context, providerConfig models.ProviderConfig) (Provider, error) {
+	return registry.New(ctx, providerConfig)
+}
 
-	switch providerConfig.Provider {
-	case string(kinds.ProviderKindMock):
-		return mockp.NewMockProvider(ctx, providerConfig)
-	case string(kinds.ProviderKindAWS):
-		return aws.NewAWSProvider(ctx, providerConfig)
-	case string(kinds.ProviderKindGitlab):
-		return gitlab.NewGitlabProvider(ctx, providerConfig)
-	case string(kinds.ProviderKindGoogle):
-		return google.NewGoogleProvider(ctx, providerConfig)
-	case string(kinds.ProviderKindTeleport):
-		return teleport.NewTeleportProvider(ctx, providerConfig)
-	case string(kinds.ProviderKindCloudflare):
-		return cloudflare.NewCloudflareProvider(ctx, providerConfig)
-	case string(kinds.ProviderKindGithub):
-		return github.NewGithubProvider(ctx, providerConfig)
-	default:
-		return nil, fmt.Errorf("unsupported provider type: %s", providerConfig.Provider)
-	}
+func RequiresUsername(kind string) bool {
+	return registry.RequiresUsername(kind)
+}
+
+func ValidateRole(role models.AccessRole) error {
+	return registry.ValidateRole(role)
 }
 
 func NewProviderUsernames() models.ProviderUsernames {
+	kinds := registry.Kinds()
 	p := models.ProviderUsernames{
-		ProviderUsernames: make(map[string]string, len(kinds.AllProviderKinds)),
+		ProviderUsernames: make(map[string]string, len(kinds)),
 	}
-	for _, kind := range kinds.AllProviderKinds {
+	for _, kind := range kinds {
 		// Initialize with empty string
 		// This ensures that all providers are present in the map
 		// even if they are not used
-		p.ProviderUsernames[string(kind)] = ""
+		p.ProviderUsernames[kind] = ""
 	}
 	return p
 }
@@ -0,0 +1,144 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+)
+
+// Provider manages user access in an external system
+type Provider interface {
+	GrantAccess(ctx context.Context, request *models.AccessRequest) error
+	RevokeAccess(ctx context.Context, request *models.AccessRequest) error
+	ListUsersWithAccess(ctx context.Context, role models.AccessRoleRef) ([]string, error)
+	IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error)
+}
+
+// RequestScoped is implemented by providers whose grants are keyed on access request id rather than username
+type RequestScoped interface {
+	// IsRequestScoped reports whether grants can only be found through the access request they were made for
+	IsRequestScoped() bool
+}
+
+// ErrRevokeUnsupported is returned when access cannot be revoked by username alone
+var ErrRevokeUnsupported = errors.New("revoking access without access request is not supported")
+
+// Factory creates provider from role provider configuration
+type Factory func(ctx context.Context, config models.ProviderConfig) (Provider, error)
+
+// Descriptor documents provider kind. The username parameter is set by the server from the request and is not listed
+type Descriptor struct {
+	Kind        string      `json:"kind" example:"gitlab"`
+	Description string      `json:"description" example:"Manages Gitlab group membership"`
+	Parameters  []Parameter `json:"parameters"`
+	Credentials []Parameter `json:"credentials"`
+	// Access requests must set username for the provider kind. Providers falling back to the requester leave it unset
+	RequiresUsername bool `json:"requiresUsername"`
+	// ValidateRole optionally rejects role settings the provider can not honour
+	ValidateRole func(role models.AccessRole, config models.ProviderConfig) error `json:"-" swaggerignore:"true"`
+}
+
+// Parameter documents single provider parameter or credential key
+type Parameter struct {
+	Name        string `json:"name" example:"group"`
+	Description string `json:"description" example:"Full path of the group"`
+	Required    bool   `json:"required"`
+	Example     string `json:"example,omitempty" example:"exampleorg/pu-group"`
+}
+
+type entry struct {
+	descriptor Descriptor
+	factory    Factory
+}
+
+var (
+	mu      sync.RWMutex
+	entries = map[string]entry{}
+)
+
+// Register adds provider kind to the registry. Provider packages call it from init. Registering the same kind twice panics
+func Register(descriptor Descriptor, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if descriptor.Kind == "" || factory == nil {
+		panic("registry: provider kind and factory are required")
+	}
+	if _, exists := entries[descriptor.Kind]; exists {
+		panic(fmt.Sprintf("registry: provider kind %s registered twice", descriptor.Kind))
+	}
+
+	if descriptor.Parameters == nil {
+		descriptor.Parameters = []Parameter{}
+	}
+	if descriptor.Credentials == nil {
+		descriptor.Credentials = []Parameter{}
+	}
+
+	entries[descriptor.Kind] = entry{descriptor: descriptor, factory: factory}
+}
+
+// New creates provider of the configured kind
+func New(ctx context.Context, config models.ProviderConfig) (Provider, error) {
+	mu.RLock()
+	e, exists := entries[config.Provider]
+	mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("unsupported provider type: %s", config.Provider)
+	}
+
+	return e.factory(ctx, config)
+}
+
+// RequiresUsername reports whether access requests must set username for the provider kind
+func RequiresUsername(kind string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return entries[kind].descriptor.RequiresUsername
+}
+
+// ValidateRole checks providers of the resolved role against role settings
+func ValidateRole(role models.AccessRole) error {
+	for _, config := range role.Providers {
+		mu.RLock()
+		validate := entries[config.Provider].descriptor.ValidateRole
+		mu.RUnlock()
+
+		if validate == nil {
+			continue
+		}
+		if err := validate(role, config); err != nil {
+			return fmt.Errorf("role %s provider %s: %w", role.Name, config.Name, err)
+		}
+	}
+	return nil
+}
+
+// Kinds returns sorted names of registered provider kinds
+func Kinds() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	kinds := make([]string, 0, len(entries))
+	for kind := range entries {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	return kinds
+}
+
+// Descriptors returns descriptors of registered provider kinds sorted by kind
+func Descriptors() []Descriptor {
+	descriptors := []Descriptor{}
+	for _, kind := range Kinds() {
+		mu.RLock()
+		descriptors = append(descriptors, entries[kind].descriptor)
+		mu.RUnlock()
+	}
+	return descriptors
+}
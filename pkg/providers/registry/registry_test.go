@@ -0,0 +1,73 @@
+package registry_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testProvider struct {
+	name string
+}
+
+func (p *testProvider) GrantAccess(ctx context.Context, request *models.AccessRequest) error {
+	return nil
+}
+
+func (p *testProvider) RevokeAccess(ctx context.Context, request *models.AccessRequest) error {
+	return nil
+}
+
+func (p *testProvider) ListUsersWithAccess(ctx context.Context, role models.AccessRoleRef) ([]string, error) {
+	return []string{p.name}, nil
+}
+
+func (p *testProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
+	return false, nil
+}
+
+func TestRegistry(t *testing.T) {
+	registry.Register(registry.Descriptor{
+		Kind:             "registry-test",
+		Description:      "Test provider",
+		RequiresUsername: true,
+		Parameters: []registry.Parameter{
+			{Name: "group", Required: true},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		return &testProvider{name: config.Name}, nil
+	})
+
+	assert.Contains(t, registry.Kinds(), "registry-test")
+
+	var descriptor registry.Descriptor
+	for _, d := range registry.Descriptors() {
+		if d.Kind == "registry-test" {
+			descriptor = d
+		}
+	}
+	assert.Equal(t, "group", descriptor.Parameters[0].Name)
+	assert.True(t, registry.RequiresUsername("registry-test"))
+	assert.False(t, registry.RequiresUsername("unknown"))
+
+	p, err := registry.New(context.Background(), models.ProviderConfig{Name: "Test", Provider: "registry-test"})
+	require.NoError(t, err)
+
+	users, err := p.ListUsersWithAccess(context.Background(), models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Test"}, users)
+
+	_, err = registry.New(context.Background(), models.ProviderConfig{Provider: "unknown"})
+	assert.Error(t, err)
+
+	assert.Panics(t, func() {
+		registry.Register(registry.Descriptor{Kind: "registry-test"}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+			return nil, nil
+		})
+	})
+}
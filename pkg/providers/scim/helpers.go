@@ -0,0 +1,269 @@
+package scim
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	maxErrorBody  = 512
+	contentType   = "application/scim+json"
+	schemaPatchOp = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+)
+
+var errUserNotFound = errors.New("user not found")
+
+// listResponse is a SCIM ListResponse
+type listResponse struct {
+	TotalResults int        `json:"totalResults"`
+	Resources    []resource `json:"Resources"`
+}
+
+type resource struct {
+	ID string `json:"id"`
+}
+
+// member is an item of the group members attribute
+type member struct {
+	Value string `json:"value"`
+	Type  string `json:"type,omitempty"`
+}
+
+type patchOperation struct {
+	Op    string   `json:"op"`
+	Path  string   `json:"path"`
+	Value []member `json:"value,omitempty"`
+}
+
+type patchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []patchOperation `json:"Operations"`
+}
+
+// statusError is returned for unexpected HTTP response codes
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	if e.body == "" {
+		return fmt.Sprintf("unexpected status %d", e.code)
+	}
+	return fmt.Sprintf("unexpected status %d: %s", e.code, e.body)
+}
+
+// call sends request relative to base URL and decodes 2xx response body into out
+func (p *SCIMProvider) call(ctx context.Context, method string, path string, query url.Values, in any, out any) error {
+	ctx, span := startSpan(ctx, "call")
+	span.SetAttributes(
+		attribute.String("span.kind", "client"),
+		attribute.String("http.method", method),
+	)
+	defer span.End()
+
+	rawURL := p.baseURL + path
+	if len(query) > 0 {
+		rawURL += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", contentType)
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	if in != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &statusError{code: resp.StatusCode, body: truncate(string(respBody), maxErrorBody)}
+	}
+
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+// findUser resolves username to SCIM user id with an equality filter
+func (p *SCIMProvider) findUser(ctx context.Context, username string) (string, error) {
+	if username == "" {
+		return "", errors.New("scim username is not set")
+	}
+
+	query := url.Values{}
+	query.Set("filter", fmt.Sprintf("%s eq %s", p.userAttribute, filterValue(username)))
+	query.Set("attributes", "id")
+
+	var users listResponse
+	if err := p.call(ctx, http.MethodGet, "/Users", query, nil, &users); err != nil {
+		return "", fmt.Errorf("failed to search user: %w", err)
+	}
+
+	switch {
+	case len(users.Resources) == 0:
+		return "", fmt.Errorf("%w: %s", errUserNotFound, username)
+	case len(users.Resources) > 1:
+		return "", fmt.Errorf("user filter matched multiple users for %s", username)
+	}
+	return users.Resources[0].ID, nil
+}
+
+// groupID returns configured group id or resolves groupName by displayName
+func (p *SCIMProvider) groupID(ctx context.Context) (string, error) {
+	if p.group != "" {
+		return p.group, nil
+	}
+
+	query := url.Values{}
+	query.Set("filter", "displayName eq "+filterValue(p.groupName))
+	query.Set("attributes", "id")
+
+	var groups listResponse
+	if err := p.call(ctx, http.MethodGet, "/Groups", query, nil, &groups); err != nil {
+		return "", fmt.Errorf("failed to search group: %w", err)
+	}
+
+	switch {
+	case len(groups.Resources) == 0:
+		return "", fmt.Errorf("group %s not found", p.groupName)
+	case len(groups.Resources) > 1:
+		return "", fmt.Errorf("multiple groups named %s", p.groupName)
+	}
+	return groups.Resources[0].ID, nil
+}
+
+func (p *SCIMProvider) groupMembers(ctx context.Context, groupID string) ([]member, error) {
+	query := url.Values{}
+	query.Set("attributes", "members")
+
+	var group struct {
+		Members []member `json:"members"`
+	}
+	if err := p.call(ctx, http.MethodGet, "/Groups/"+url.PathEscape(groupID), query, nil, &group); err != nil {
+		return nil, err
+	}
+	return group.Members, nil
+}
+
+// membership resolves group and user ids and checks whether the user is a group member
+func (p *SCIMProvider) membership(ctx context.Context, username string) (string, string, bool, error) {
+	groupID, err := p.groupID(ctx)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	userID, err := p.findUser(ctx, username)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	members, err := p.groupMembers(ctx, groupID)
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to read group members: %w", err)
+	}
+
+	for _, m := range members {
+		if m.Value == userID {
+			return groupID, userID, true, nil
+		}
+	}
+	return groupID, userID, false, nil
+}
+
+// username reads user attribute of a user. Returns empty string when user does not exist
+func (p *SCIMProvider) username(ctx context.Context, userID string) (string, error) {
+	query := url.Values{}
+	query.Set("attributes", p.userAttribute)
+
+	var user map[string]any
+	err := p.call(ctx, http.MethodGet, "/Users/"+url.PathEscape(userID), query, nil, &user)
+
+	var statusErr *statusError
+	if errors.As(err, &statusErr) && statusErr.code == http.StatusNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	username, _ := user[p.userAttribute].(string)
+	return username, nil
+}
+
+func (p *SCIMProvider) addMember(ctx context.Context, groupID string, userID string) error {
+	return p.patchGroup(ctx, groupID, patchOperation{
+		Op:    "add",
+		Path:  "members",
+		Value: []member{{Value: userID}},
+	})
+}
+
+func (p *SCIMProvider) removeMember(ctx context.Context, groupID string, userID string) error {
+	operation := patchOperation{
+		Op:   "remove",
+		Path: fmt.Sprintf("members[value eq %s]", filterValue(userID)),
+	}
+	if p.memberRemoval == removalValue {
+		operation = patchOperation{
+			Op:    "remove",
+			Path:  "members",
+			Value: []member{{Value: userID}},
+		}
+	}
+	return p.patchGroup(ctx, groupID, operation)
+}
+
+func (p *SCIMProvider) patchGroup(ctx context.Context, groupID string, operation patchOperation) error {
+	return p.call(ctx, http.MethodPatch, "/Groups/"+url.PathEscape(groupID), nil, patchRequest{
+		Schemas:    []string{schemaPatchOp},
+		Operations: []patchOperation{operation},
+	}, nil)
+}
+
+// filterValue quotes value as a SCIM filter string literal
+func filterValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
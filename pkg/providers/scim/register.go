@@ -0,0 +1,34 @@
+package scim
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:             string(kinds.ProviderKindSCIM),
+		RequiresUsername: true,
+		Description:      "Manages group membership in any SCIM 2.0 service provider. Users are resolved with a userName filter",
+		Parameters: []registry.Parameter{
+			{Name: "group", Description: "SCIM group id. Either group or groupName is required", Example: "5c2bd4e8-3f1a-4b9e-9d7a-0c6b1e2f3a4d"},
+			{Name: "groupName", Description: "Group displayName resolved to id on each call", Example: "Engineering Admins"},
+			{Name: "userAttribute", Description: "User attribute matched against username. Defaults to userName", Example: "externalId"},
+			{Name: "memberRemoval", Description: "PATCH remove style. path uses members[value eq \"id\"] filter, value sends member in operation value. Defaults to path", Example: "value"},
+			{Name: "timeout", Description: "HTTP request timeout. Defaults to 30s", Example: "10s"},
+		},
+		Credentials: []registry.Parameter{
+			{Name: "baseurl", Description: "SCIM base URL without trailing /Users or /Groups", Required: true, Example: "https://api.example.com/scim/v2"},
+			{Name: "token", Description: "Bearer token of the SCIM integration", Required: true},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewSCIMProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
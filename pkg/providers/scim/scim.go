@@ -0,0 +1,240 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/config"
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/tracing"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const providerType = string(kinds.ProviderKindSCIM)
+
+// Member removal styles. Services differ in PATCH remove operations they accept
+const (
+	// {"op": "remove", "path": "members[value eq \"<id>\"]"} as defined by RFC 7644
+	removalPath = "path"
+	// {"op": "remove", "path": "members", "value": [{"value": "<id>"}]}
+	removalValue = "value"
+)
+
+var attributePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+
+var Config = config.GetConfig()
+
+// SCIMProvider manages group membership in SCIM 2.0 service providers
+type SCIMProvider struct {
+	client *http.Client
+
+	// provider name as defined in the provider configuration
+	name string
+	// SCIM group id
+	group string
+	// group displayName resolved to id when group is not set
+	groupName string
+	// user attribute matched against username
+	userAttribute string
+	memberRemoval string
+
+	baseURL string
+	token   string
+}
+
+// NewSCIMProvider initializes a new SCIMProvider with base URL and token from CredentialRef
+func NewSCIMProvider(ctx context.Context, config models.ProviderConfig) (*SCIMProvider, error) {
+	creds := Config.GetCredentials(config.CredentialRef.Name)
+	data := config.Parameters
+
+	timeout := 30 * time.Second
+	if value := data["timeout"]; value != "" {
+		var err error
+		timeout, err = time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+
+	p := &SCIMProvider{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+		name:          config.Name,
+		group:         data["group"],
+		groupName:     data["groupName"],
+		userAttribute: data["userAttribute"],
+		memberRemoval: data["memberRemoval"],
+		baseURL:       strings.TrimSuffix(creds.GetString("baseurl"), "/"),
+		token:         creds.GetString("token"),
+	}
+
+	if p.baseURL == "" {
+		return nil, errors.New("baseurl not found in credentials")
+	}
+	if u, err := url.Parse(p.baseURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("invalid baseurl %s", p.baseURL)
+	}
+	if p.token == "" {
+		return nil, errors.New("token not found in credentials")
+	}
+
+	if p.group == "" && p.groupName == "" {
+		return nil, errors.New("group or groupName not found in provider config")
+	}
+
+	if p.userAttribute == "" {
+		p.userAttribute = "userName"
+	}
+	if !attributePattern.MatchString(p.userAttribute) {
+		return nil, fmt.Errorf("invalid userAttribute %s", p.userAttribute)
+	}
+
+	if p.memberRemoval == "" {
+		p.memberRemoval = removalPath
+	}
+	if p.memberRemoval != removalPath && p.memberRemoval != removalValue {
+		return nil, fmt.Errorf("invalid memberRemoval %s. Expected path or value", p.memberRemoval)
+	}
+
+	return p, nil
+}
+
+// GrantAccess adds the user to the group with a PATCH add operation
+func (p *SCIMProvider) GrantAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "GrantAccess")
+	defer span.End()
+
+	username := request.GetProviderUsername(providerType)
+	group := p.groupRef()
+
+	groupID, userID, isMember, err := p.membership(ctx, username)
+	if err != nil {
+		request.SetProviderStatusError(p.name, group, err.Error())
+		return err
+	}
+
+	if isMember {
+		request.SetProviderStatusGranted(p.name, group, "already granted")
+		return nil
+	}
+
+	if err := p.addMember(ctx, groupID, userID); err != nil {
+		request.SetProviderStatusError(p.name, group, err.Error())
+		return fmt.Errorf("failed to add group member: %w", err)
+	}
+
+	request.SetProviderStatusGranted(p.name, group, "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", username).
+		Str("Group", group).
+		Msg("User added to SCIM group")
+
+	return nil
+}
+
+// RevokeAccess removes the user from the group with a PATCH remove operation
+func (p *SCIMProvider) RevokeAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "RevokeAccess")
+	defer span.End()
+
+	username := request.GetProviderUsername(providerType)
+	group := p.groupRef()
+
+	groupID, userID, isMember, err := p.membership(ctx, username)
+	if errors.Is(err, errUserNotFound) {
+		request.SetProviderStatusRevoked(p.name, group, "user not found")
+		return nil
+	}
+	if err != nil {
+		request.SetProviderStatusError(p.name, group, err.Error())
+		return err
+	}
+
+	if !isMember {
+		request.SetProviderStatusRevoked(p.name, group, "already revoked")
+		return nil
+	}
+
+	if err := p.removeMember(ctx, groupID, userID); err != nil {
+		request.SetProviderStatusError(p.name, group, err.Error())
+		return fmt.Errorf("failed to remove group member: %w", err)
+	}
+
+	request.SetProviderStatusRevoked(p.name, group, "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", username).
+		Str("Group", group).
+		Msg("User removed from SCIM group")
+
+	return nil
+}
+
+// ListUsersWithAccess lists user attribute of group members. Members that are not users are skipped
+func (p *SCIMProvider) ListUsersWithAccess(ctx context.Context, roleRef models.AccessRoleRef) ([]string, error) {
+	ctx, span := startSpan(ctx, "ListUsersWithAccess")
+	defer span.End()
+
+	groupID, err := p.groupID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := p.groupMembers(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read group members: %w", err)
+	}
+
+	usernames := []string{}
+	for _, member := range members {
+		if member.Type != "" && member.Type != "User" {
+			continue
+		}
+		username, err := p.username(ctx, member.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read member %s: %w", member.Value, err)
+		}
+		if username != "" {
+			usernames = append(usernames, username)
+		}
+	}
+
+	return usernames, nil
+}
+
+// IsAccessExpired checks whether the access for the given request has expired
+func (p *SCIMProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
+	_, span := startSpan(ctx, "IsAccessExpired")
+	defer span.End()
+
+	return request.IsExpired(time.Now())
+}
+
+// groupRef returns group reference for provider status
+func (p *SCIMProvider) groupRef() string {
+	if p.groupName != "" {
+		return p.groupName
+	}
+	return p.group
+}
+
+func startSpan(ctx context.Context, name string) (context.Context, *tracing.SpanWrapper) {
+	ctx, span := tracing.NewSpanWrapper(ctx, fmt.Sprintf("providers.scim.%s", name))
+	return ctx, span
+}
@@ -0,0 +1,244 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "scim-token"
+
+var (
+	userFilterPattern  = regexp.MustCompile(`^userName eq "(.*)"$`)
+	groupFilterPattern = regexp.MustCompile(`^displayName eq "(.*)"$`)
+	memberPathPattern  = regexp.MustCompile(`^members\[value eq "(.*)"\]$`)
+)
+
+// scimAPI is a minimal SCIM service provider with users u1..uN and group g1
+type scimAPI struct {
+	mu      sync.Mutex
+	users   map[string]string
+	members []string
+	patches []patchRequest
+}
+
+func newSCIMAPI(usernames ...string) *scimAPI {
+	api := &scimAPI{users: map[string]string{}}
+	for i, username := range usernames {
+		api.users[fmt.Sprintf("u%d", i+1)] = username
+	}
+	return api
+}
+
+func (s *scimAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+testToken {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	filter := r.URL.Query().Get("filter")
+	w.Header().Set("Content-Type", contentType)
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/scim/v2/Users":
+		match := userFilterPattern.FindStringSubmatch(filter)
+		if match == nil {
+			http.Error(w, "unsupported filter", http.StatusBadRequest)
+			return
+		}
+		resources := []resource{}
+		for id, username := range s.users {
+			if username == match[1] {
+				resources = append(resources, resource{ID: id})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(listResponse{TotalResults: len(resources), Resources: resources})
+
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/scim/v2/Users/"):
+		username, ok := s.users[strings.TrimPrefix(r.URL.Path, "/scim/v2/Users/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"userName": username})
+
+	case r.Method == http.MethodGet && r.URL.Path == "/scim/v2/Groups":
+		resources := []resource{}
+		if match := groupFilterPattern.FindStringSubmatch(filter); match != nil && match[1] == "Admins" {
+			resources = append(resources, resource{ID: "g1"})
+		}
+		_ = json.NewEncoder(w).Encode(listResponse{TotalResults: len(resources), Resources: resources})
+
+	case r.Method == http.MethodGet && r.URL.Path == "/scim/v2/Groups/g1":
+		members := []member{{Value: "g2", Type: "Group"}}
+		for _, id := range s.members {
+			members = append(members, member{Value: id, Type: "User"})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "g1", "members": members})
+
+	case r.Method == http.MethodPatch && r.URL.Path == "/scim/v2/Groups/g1":
+		if r.Header.Get("Content-Type") != contentType {
+			http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+			return
+		}
+		var patch patchRequest
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil || len(patch.Operations) != 1 || patch.Schemas[0] != schemaPatchOp {
+			http.Error(w, "invalid patch", http.StatusBadRequest)
+			return
+		}
+		s.patches = append(s.patches, patch)
+
+		operation := patch.Operations[0]
+		switch {
+		case operation.Op == "add" && operation.Path == "members":
+			s.members = append(s.members, operation.Value[0].Value)
+		case operation.Op == "remove" && operation.Path == "members":
+			s.members = slices.DeleteFunc(s.members, func(id string) bool { return id == operation.Value[0].Value })
+		case operation.Op == "remove" && memberPathPattern.MatchString(operation.Path):
+			id := memberPathPattern.FindStringSubmatch(operation.Path)[1]
+			s.members = slices.DeleteFunc(s.members, func(m string) bool { return m == id })
+		default:
+			http.Error(w, "unsupported operation", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func testProvider(t *testing.T, api *scimAPI, parameters map[string]string) *SCIMProvider {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	Config.Creds = map[string]models.Credential{
+		"scim": {
+			Name: "scim",
+			Data: map[string]string{"baseurl": server.URL + "/scim/v2/", "token": testToken},
+		},
+	}
+
+	p, err := NewSCIMProvider(context.Background(), models.ProviderConfig{
+		Name:          "SaaSAdmins",
+		Provider:      "scim",
+		CredentialRef: models.CredentialRef{Name: "scim"},
+		Parameters:    parameters,
+	})
+	require.NoError(t, err)
+	return p
+}
+
+func testRequest(username string) *models.AccessRequest {
+	request := &models.AccessRequest{Id: "req-1"}
+	request.Status.ProviderUsernames = map[string]string{providerType: username}
+	return request
+}
+
+func TestGrantRevoke(t *testing.T) {
+	ctx := context.Background()
+	api := newSCIMAPI("alice@example.com", "bob@example.com")
+	p := testProvider(t, api, map[string]string{"group": "g1"})
+
+	request := testRequest("alice@example.com")
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusGranted, request.Status.ProviderStatuses["SaaSAdmins"].Action)
+	assert.Equal(t, []string{"u1"}, api.members)
+
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, "already granted", request.Status.ProviderStatuses["SaaSAdmins"].Error)
+	assert.Len(t, api.patches, 1)
+
+	users, err := p.ListUsersWithAccess(ctx, models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice@example.com"}, users)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusRevoked, request.Status.ProviderStatuses["SaaSAdmins"].Action)
+	assert.Empty(t, api.members)
+	assert.Equal(t, `members[value eq "u1"]`, api.patches[1].Operations[0].Path)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, "already revoked", request.Status.ProviderStatuses["SaaSAdmins"].Error)
+}
+
+func TestGroupNameAndValueRemoval(t *testing.T) {
+	ctx := context.Background()
+	api := newSCIMAPI("alice@example.com")
+	p := testProvider(t, api, map[string]string{"groupName": "Admins", "memberRemoval": "value"})
+
+	request := testRequest("alice@example.com")
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, "Admins", request.Status.ProviderStatuses["SaaSAdmins"].Details)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Empty(t, api.members)
+	assert.Equal(t, "members", api.patches[1].Operations[0].Path)
+	assert.Equal(t, []member{{Value: "u1"}}, api.patches[1].Operations[0].Value)
+}
+
+func TestUnknownUserAndGroup(t *testing.T) {
+	ctx := context.Background()
+	api := newSCIMAPI()
+	p := testProvider(t, api, map[string]string{"group": "g1"})
+
+	request := testRequest(`mallory" or userName pr`)
+	assert.ErrorIs(t, p.GrantAccess(ctx, request), errUserNotFound)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, "user not found", request.Status.ProviderStatuses["SaaSAdmins"].Error)
+
+	p = testProvider(t, api, map[string]string{"groupName": "Missing"})
+	assert.EqualError(t, p.GrantAccess(ctx, testRequest("alice@example.com")), "group Missing not found")
+}
+
+func TestUnauthorized(t *testing.T) {
+	p := testProvider(t, newSCIMAPI("alice@example.com"), map[string]string{"group": "g1"})
+	p.token = "wrong"
+
+	request := testRequest("alice@example.com")
+	assert.Error(t, p.GrantAccess(context.Background(), request))
+	assert.Contains(t, request.Status.ProviderStatuses["SaaSAdmins"].Error, "unexpected status 401")
+}
+
+func TestFilterValue(t *testing.T) {
+	assert.Equal(t, `"alice@example.com"`, filterValue("alice@example.com"))
+	assert.Equal(t, `"a\" or userName pr \\"`, filterValue(`a" or userName pr \`))
+}
+
+func TestConfigErrors(t *testing.T) {
+	ctx := context.Background()
+	Config.Creds = map[string]models.Credential{
+		"scim":    {Name: "scim", Data: map[string]string{"baseurl": "https://api.example.com/scim/v2", "token": testToken}},
+		"notoken": {Name: "notoken", Data: map[string]string{"baseurl": "https://api.example.com/scim/v2"}},
+		"badurl":  {Name: "badurl", Data: map[string]string{"baseurl": "api.example.com", "token": testToken}},
+	}
+
+	for _, config := range []models.ProviderConfig{
+		{Parameters: map[string]string{"group": "g1"}},
+		{CredentialRef: models.CredentialRef{Name: "notoken"}, Parameters: map[string]string{"group": "g1"}},
+		{CredentialRef: models.CredentialRef{Name: "badurl"}, Parameters: map[string]string{"group": "g1"}},
+		{CredentialRef: models.CredentialRef{Name: "scim"}, Parameters: map[string]string{}},
+		{CredentialRef: models.CredentialRef{Name: "scim"}, Parameters: map[string]string{"group": "g1", "userAttribute": "emails[type eq \"work\"]"}},
+		{CredentialRef: models.CredentialRef{Name: "scim"}, Parameters: map[string]string{"group": "g1", "memberRemoval": "replace"}},
+		{CredentialRef: models.CredentialRef{Name: "scim"}, Parameters: map[string]string{"group": "g1", "timeout": "soon"}},
+	} {
+		_, err := NewSCIMProvider(ctx, config)
+		assert.Error(t, err, fmt.Sprint(config.CredentialRef.Name, config.Parameters))
+	}
+}
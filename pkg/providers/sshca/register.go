@@ -0,0 +1,31 @@
+package sshca
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:        string(kinds.ProviderKindSSHCA),
+		Description: "Signs the SSH public key from the requester's profile with a CA key. The certificate expires with the request and is retrieved once by the requester",
+		Parameters: []registry.Parameter{
+			{Name: "principals", Description: "Comma-separated certificate principals. {username} is replaced with the requester's username", Required: true, Example: "{username},deploy"},
+			{Name: "extensions", Description: "Comma-separated certificate extensions, name or name=value. Defaults to permit-pty", Example: "permit-pty,permit-port-forwarding"},
+		},
+		Credentials: []registry.Parameter{
+			{Name: "privatekey", Description: "CA private key in OpenSSH or PEM format. Either privatekey or privatekeypath is required"},
+			{Name: "privatekeypath", Description: "Path to CA private key", Example: "creds/ssh-user-ca"},
+			{Name: "passphrase", Description: "CA private key passphrase"},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewSSHCAProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
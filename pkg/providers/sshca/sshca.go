@@ -0,0 +1,279 @@
+package sshca
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/config"
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/tracing"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	providerType = string(kinds.ProviderKindSSHCA)
+
+	// Certificates are valid slightly before issue to tolerate clock skew between server and hosts
+	clockSkew = 5 * time.Minute
+)
+
+var Config = config.GetConfig()
+
+// Extensions granted when none are configured
+var defaultExtensions = []string{"permit-pty"}
+
+// SSHCAProvider signs requester's SSH public key with a CA key. Certificates expire with the request
+type SSHCAProvider struct {
+	signer ssh.Signer
+
+	// provider name as defined in the provider configuration
+	name string
+	// certificate principals. {username} is replaced with requester's provider username
+	principals []string
+	// certificate extensions, e.g. permit-pty or permit-port-forwarding
+	extensions map[string]string
+}
+
+// NewSSHCAProvider initializes a new SSHCAProvider with CA private key from CredentialRef
+func NewSSHCAProvider(ctx context.Context, config models.ProviderConfig) (*SSHCAProvider, error) {
+	creds := Config.GetCredentials(config.CredentialRef.Name)
+	data := config.Parameters
+
+	key := []byte(creds.GetString("privatekey"))
+	if path := creds.GetString("privatekeypath"); len(key) == 0 && path != "" {
+		var err error
+		key, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key: %w", err)
+		}
+	}
+	if len(key) == 0 {
+		return nil, errors.New("privatekey or privatekeypath not found in credentials")
+	}
+
+	var signer ssh.Signer
+	var err error
+	if passphrase := creds.GetString("passphrase"); passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey(key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CA private key: %w", err)
+	}
+
+	p := &SSHCAProvider{
+		signer:     signer,
+		name:       config.Name,
+		principals: splitList(data["principals"]),
+		extensions: map[string]string{},
+	}
+
+	if len(p.principals) == 0 {
+		return nil, errors.New("principals not found in provider config")
+	}
+
+	extensions := defaultExtensions
+	if value, ok := data["extensions"]; ok {
+		extensions = splitList(value)
+	}
+	for _, extension := range extensions {
+		name, value, _ := strings.Cut(extension, "=")
+		p.extensions[name] = value
+	}
+
+	return p, nil
+}
+
+// GrantAccess signs requester's public key and attaches the certificate to the request for download
+func (p *SSHCAProvider) GrantAccess(ctx context.Context, request *models.AccessRequest) error {
+	_, span := startSpan(ctx, "GrantAccess")
+	defer span.End()
+
+	username := p.username(request)
+
+	cert, err := p.sign(request, username, time.Now())
+	if err != nil {
+		request.SetProviderStatusError(p.name, strings.Join(p.principals, ","), err.Error())
+		return err
+	}
+
+	principals := strings.Join(cert.ValidPrincipals, ",")
+	validBefore := time.Unix(int64(cert.ValidBefore), 0)
+
+	request.AddCredential(p.name, models.AccessCredentialSSH, map[string]string{
+		"certificate": string(ssh.MarshalAuthorizedKey(cert)),
+		"keyid":       cert.KeyId,
+		"principals":  principals,
+		"validbefore": validBefore.UTC().Format(time.RFC3339),
+		"capublickey": string(ssh.MarshalAuthorizedKey(p.signer.PublicKey())),
+	}, &validBefore)
+
+	request.SetProviderStatusGranted(p.name, principals, "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", username).
+		Str("KeyID", cert.KeyId).
+		Uint64("Serial", cert.Serial).
+		Str("Principals", principals).
+		Time("ValidBefore", validBefore).
+		Msg("SSH certificate issued")
+
+	return nil
+}
+
+// RevokeAccess records revocation. Issued certificates stay valid until they expire with the request
+func (p *SSHCAProvider) RevokeAccess(ctx context.Context, request *models.AccessRequest) error {
+	_, span := startSpan(ctx, "RevokeAccess")
+	defer span.End()
+
+	request.SetProviderStatusRevoked(p.name, strings.Join(p.principals, ","), "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", p.username(request)).
+		Msg("SSH certificate expires with the request")
+
+	return nil
+}
+
+// ListUsersWithAccess returns no users. Hosts trust the CA, so there is no membership to list
+func (p *SSHCAProvider) ListUsersWithAccess(ctx context.Context, roleRef models.AccessRoleRef) ([]string, error) {
+	_, span := startSpan(ctx, "ListUsersWithAccess")
+	defer span.End()
+
+	return []string{}, nil
+}
+
+// IsAccessExpired checks whether the access for the given request has expired
+func (p *SSHCAProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
+	_, span := startSpan(ctx, "IsAccessExpired")
+	defer span.End()
+
+	return request.IsExpired(time.Now())
+}
+
+// sign issues a user certificate for requester's public key valid until request expiry
+func (p *SSHCAProvider) sign(request *models.AccessRequest, username string, now time.Time) (*ssh.Certificate, error) {
+	if request.Status.SSHPublicKey == "" {
+		return nil, errors.New("ssh public key is not set in user profile")
+	}
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(request.Status.SSHPublicKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ssh public key: %w", err)
+	}
+	if _, ok := publicKey.(*ssh.Certificate); ok {
+		return nil, errors.New("invalid ssh public key: certificates are not accepted")
+	}
+
+	validBefore, err := expiresAt(request, now)
+	if err != nil {
+		return nil, err
+	}
+	if !validBefore.After(now) {
+		return nil, errors.New("access request already expired")
+	}
+
+	principals, err := p.certificatePrincipals(username)
+	if err != nil {
+		return nil, err
+	}
+
+	serial := make([]byte, 8)
+	if _, err := rand.Read(serial); err != nil {
+		return nil, fmt.Errorf("failed to generate serial: %w", err)
+	}
+
+	extensions := make(map[string]string, len(p.extensions))
+	for name, value := range p.extensions {
+		extensions[name] = value
+	}
+
+	cert := &ssh.Certificate{
+		Key:             publicKey,
+		Serial:          binary.BigEndian.Uint64(serial),
+		CertType:        ssh.UserCert,
+		KeyId:           keyID(request.Id, username),
+		ValidPrincipals: principals,
+		ValidAfter:      uint64(now.Add(-clockSkew).Unix()),
+		ValidBefore:     uint64(validBefore.Unix()),
+		Permissions: ssh.Permissions{
+			Extensions: extensions,
+		},
+	}
+
+	if err := cert.SignCert(rand.Reader, p.signer); err != nil {
+		return nil, fmt.Errorf("failed to sign certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// certificatePrincipals expands {username} in configured principals
+func (p *SSHCAProvider) certificatePrincipals(username string) ([]string, error) {
+	principals := make([]string, 0, len(p.principals))
+	for _, principal := range p.principals {
+		if strings.Contains(principal, "{username}") {
+			if username == "" {
+				return nil, errors.New("sshca username is not set")
+			}
+			principal = strings.ReplaceAll(principal, "{username}", username)
+		}
+		principals = append(principals, principal)
+	}
+	return principals, nil
+}
+
+// username returns provider username of the requester, falling back to the requester
+func (p *SSHCAProvider) username(request *models.AccessRequest) string {
+	if username := request.GetProviderUsername(providerType); username != "" {
+		return username
+	}
+	return request.Status.RequestedBy
+}
+
+// expiresAt returns request expiration. Certificates are not issued for standing requests
+func expiresAt(request *models.AccessRequest, now time.Time) (time.Time, error) {
+	expires, err := request.Expiration(now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if expires == nil {
+		return time.Time{}, errors.New("TTL not specified in access request")
+	}
+	return *expires, nil
+}
+
+// keyID references the access request in sshd logs
+func keyID(requestID string, username string) string {
+	if username == "" {
+		return "passage:" + requestID
+	}
+	return fmt.Sprintf("passage:%s:%s", requestID, username)
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func startSpan(ctx context.Context, name string) (context.Context, *tracing.SpanWrapper) {
+	ctx, span := tracing.NewSpanWrapper(ctx, fmt.Sprintf("providers.sshca.%s", name))
+	return ctx, span
+}
@@ -0,0 +1,179 @@
+package sshca
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// testKeyPair returns OpenSSH PEM private key and authorized_keys public key
+func testKeyPair(t *testing.T) (string, string) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	block, err := ssh.MarshalPrivateKey(private, "")
+	require.NoError(t, err)
+
+	sshPublic, err := ssh.NewPublicKey(public)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(block)), string(ssh.MarshalAuthorizedKey(sshPublic))
+}
+
+func testProvider(t *testing.T, creds map[string]string, parameters map[string]string) *SSHCAProvider {
+	Config.Creds = map[string]models.Credential{"sshca": {Name: "sshca", Data: creds}}
+
+	p, err := NewSSHCAProvider(context.Background(), models.ProviderConfig{
+		Name:          "LegacyHosts",
+		Provider:      "sshca",
+		CredentialRef: models.CredentialRef{Name: "sshca"},
+		Parameters:    parameters,
+	})
+	require.NoError(t, err)
+	return p
+}
+
+func testRequest(publicKey string) *models.AccessRequest {
+	expires := time.Now().Add(time.Hour)
+	request := &models.AccessRequest{Id: "req-1"}
+	request.Details.TTL = "1h"
+	request.Status.ExpiresAt = &expires
+	request.Status.RequestedBy = "alice@example.com"
+	request.Status.ProviderUsernames = map[string]string{providerType: "alice"}
+	request.SetSSHPublicKey(publicKey)
+	return request
+}
+
+func TestGrantAccess(t *testing.T) {
+	caKey, _ := testKeyPair(t)
+	_, userKey := testKeyPair(t)
+	p := testProvider(t, map[string]string{"privatekey": caKey}, map[string]string{
+		"principals": "{username}, deploy",
+		"extensions": "permit-pty,permit-port-forwarding,login@example.com=alice",
+	})
+
+	request := testRequest(userKey)
+	require.NoError(t, p.GrantAccess(context.Background(), request))
+	assert.Equal(t, models.ProviderStatus{Action: models.ProviderStatusGranted, Details: "alice,deploy"}, request.Status.ProviderStatuses["LegacyHosts"])
+
+	require.Len(t, request.Credentials, 1)
+	credential := request.Credentials[0]
+	assert.Equal(t, models.AccessCredentialSSH, credential.Kind)
+	assert.Equal(t, "passage:req-1:alice", credential.Data["keyid"])
+	require.NotNil(t, credential.ExpiresAt)
+	assert.Equal(t, request.Status.ExpiresAt.Unix(), credential.ExpiresAt.Unix())
+
+	parsed, _, _, _, err := ssh.ParseAuthorizedKey([]byte(credential.Data["certificate"]))
+	require.NoError(t, err)
+	cert, ok := parsed.(*ssh.Certificate)
+	require.True(t, ok)
+
+	assert.Equal(t, uint32(ssh.UserCert), cert.CertType)
+	assert.Equal(t, []string{"alice", "deploy"}, cert.ValidPrincipals)
+	assert.Equal(t, map[string]string{"permit-pty": "", "permit-port-forwarding": "", "login@example.com": "alice"}, cert.Extensions)
+	assert.Equal(t, uint64(request.Status.ExpiresAt.Unix()), cert.ValidBefore)
+	assert.Equal(t, userKey, string(ssh.MarshalAuthorizedKey(cert.Key)))
+
+	// Certificate is accepted by a host trusting the CA
+	checker := ssh.CertChecker{
+		IsUserAuthority: func(auth ssh.PublicKey) bool {
+			return string(auth.Marshal()) == string(p.signer.PublicKey().Marshal())
+		},
+	}
+	_, err = checker.Authenticate(connMetadata("deploy"), cert)
+	assert.NoError(t, err)
+	_, err = checker.Authenticate(connMetadata("root"), cert)
+	assert.Error(t, err)
+
+	require.NoError(t, p.RevokeAccess(context.Background(), request))
+	assert.Equal(t, models.ProviderStatusRevoked, request.Status.ProviderStatuses["LegacyHosts"].Action)
+}
+
+func TestDefaultsAndKeyFile(t *testing.T) {
+	caKey, _ := testKeyPair(t)
+	_, userKey := testKeyPair(t)
+
+	path := filepath.Join(t.TempDir(), "ca")
+	require.NoError(t, os.WriteFile(path, []byte(caKey), 0o600))
+	p := testProvider(t, map[string]string{"privatekeypath": path}, map[string]string{"principals": "ops"})
+
+	request := testRequest(userKey)
+	request.Status.ExpiresAt = nil
+	request.Status.ProviderUsernames = nil
+
+	cert, err := p.sign(request, p.username(request), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ops"}, cert.ValidPrincipals)
+	assert.Equal(t, map[string]string{"permit-pty": ""}, cert.Extensions)
+	assert.Equal(t, "passage:req-1:alice@example.com", cert.KeyId)
+	assert.InDelta(t, time.Now().Add(time.Hour).Unix(), int64(cert.ValidBefore), 5)
+	assert.InDelta(t, time.Now().Add(-clockSkew).Unix(), int64(cert.ValidAfter), 5)
+}
+
+func TestGrantErrors(t *testing.T) {
+	caKey, _ := testKeyPair(t)
+	_, userKey := testKeyPair(t)
+	p := testProvider(t, map[string]string{"privatekey": caKey}, map[string]string{"principals": "{username}"})
+
+	request := testRequest("")
+	assert.EqualError(t, p.GrantAccess(context.Background(), request), "ssh public key is not set in user profile")
+	assert.Equal(t, models.ProviderStatusError, request.Status.ProviderStatuses["LegacyHosts"].Action)
+	assert.Empty(t, request.Credentials)
+
+	request = testRequest(userKey)
+	expired := time.Now().Add(-time.Minute)
+	request.Status.ExpiresAt = &expired
+	assert.EqualError(t, p.GrantAccess(context.Background(), request), "access request already expired")
+
+	request = testRequest(userKey)
+	request.Status.ExpiresAt = nil
+	request.Details.TTL = ""
+	assert.Error(t, p.GrantAccess(context.Background(), request))
+
+	request = testRequest(userKey)
+	request.Status.ProviderUsernames = nil
+	request.Status.RequestedBy = ""
+	assert.EqualError(t, p.GrantAccess(context.Background(), request), "sshca username is not set")
+}
+
+func TestConfigErrors(t *testing.T) {
+	caKey, _ := testKeyPair(t)
+	Config.Creds = map[string]models.Credential{
+		"sshca":   {Name: "sshca", Data: map[string]string{"privatekey": caKey}},
+		"nokey":   {Name: "nokey", Data: map[string]string{}},
+		"badkey":  {Name: "badkey", Data: map[string]string{"privatekey": "not a key"}},
+		"badpath": {Name: "badpath", Data: map[string]string{"privatekeypath": "/nonexistent/ca"}},
+	}
+
+	for _, config := range []models.ProviderConfig{
+		{CredentialRef: models.CredentialRef{Name: "nokey"}, Parameters: map[string]string{"principals": "ops"}},
+		{CredentialRef: models.CredentialRef{Name: "badkey"}, Parameters: map[string]string{"principals": "ops"}},
+		{CredentialRef: models.CredentialRef{Name: "badpath"}, Parameters: map[string]string{"principals": "ops"}},
+		{CredentialRef: models.CredentialRef{Name: "sshca"}, Parameters: map[string]string{"principals": " , "}},
+	} {
+		_, err := NewSSHCAProvider(context.Background(), config)
+		assert.Error(t, err, fmt.Sprint(config.CredentialRef.Name, config.Parameters))
+	}
+}
+
+type connMetadata string
+
+func (c connMetadata) User() string          { return string(c) }
+func (c connMetadata) SessionID() []byte     { return nil }
+func (c connMetadata) ClientVersion() []byte { return nil }
+func (c connMetadata) ServerVersion() []byte { return nil }
+func (c connMetadata) RemoteAddr() net.Addr  { return nil }
+func (c connMetadata) LocalAddr() net.Addr   { return nil }
@@ -2,18 +2,28 @@ package teleport
 
 import (
 	"context"
+	"errors"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/CTO2BPublic/passage-server/pkg/tracing"
+	"github.com/google/uuid"
 	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
 	jsoniter "github.com/json-iterator/go"
 	"gopkg.in/yaml.v2"
 
 	"go.opentelemetry.io/otel/attribute"
 )
 
-func (a *TeleportProvider) addRoleToUser(ctx context.Context, Username string, RoleName string) (Created bool, Error error) {
+// Label linking per-request roles to the access request
+const requestLabel = "passage/access-request"
+
+var errUserNotFound = errors.New("user not found")
+
+// addRoleToUser adds missing roles to the user. Returns false when the user already has all of them
+func (a *TeleportProvider) addRoleToUser(ctx context.Context, Username string, roles []string) (Created bool, Error error) {
 	ctx, span := tracing.NewSpanWrapper(ctx, "teleport.addRoleToUser")
 	span.SetAttributes(
 		attribute.String("peer.service", "teleport"),
@@ -26,59 +36,94 @@ func (a *TeleportProvider) addRoleToUser(ctx context.Context, Username string, R
 		return false, err
 	}
 
-	if user.GetName() == Username {
-
-		roles := a.parseRoles(ctx, RoleName)
-
-		for _, role := range roles {
+	currentRoles := user.GetRoles()
+	added := false
+	for _, role := range roles {
+		if !slices.Contains(currentRoles, role) {
 			user.AddRole(role)
+			added = true
 		}
+	}
 
-		_, err := a.Client.UpdateUser(ctx, user)
-		if err != nil {
-			return false, err
-		}
+	if !added {
+		return false, nil
+	}
+
+	_, err = a.Client.UpdateUser(ctx, user)
+	if err != nil {
+		return false, err
 	}
 	return true, nil
 }
 
-func (a *TeleportProvider) upsertRole(RoleName string, roleDefinition string) error {
-	ctx := context.Background()
+// upsertRole creates the role from YAML definition. Teleport deletes the role itself once it expires
+func (a *TeleportProvider) upsertRole(ctx context.Context, RoleName string, roleDefinition string, requestID string, expires *time.Time) error {
+	ctx, span := tracing.NewSpanWrapper(ctx, "teleport.upsertRole")
+	span.SetAttributes(
+		attribute.String("peer.service", "teleport"),
+		attribute.String("span.kind", "client"),
+	)
+	defer span.End()
+
+	role, err := buildRole(RoleName, roleDefinition, requestID, expires)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.Client.UpsertRole(ctx, role)
+	return err
+}
+
+// buildRole parses YAML role spec and sets role metadata
+func buildRole(RoleName string, roleDefinition string, requestID string, expires *time.Time) (*types.RoleV6, error) {
 	var json = jsoniter.ConfigCompatibleWithStandardLibrary
 
 	var holder map[interface{}]interface{}
 	if err := yaml.Unmarshal([]byte(roleDefinition), &holder); err != nil {
-		return err
+		return nil, err
 	}
 	bytes, err := json.Marshal(holder)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var role types.RoleV6
 	err = json.Unmarshal(bytes, &role)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	role.SetMetadata(types.Metadata{
 		Name:        RoleName,
 		Description: "Passage created role",
+		Labels:      map[string]string{requestLabel: requestID},
+		Expires:     expires,
 	})
 
-	if a.Client != nil {
+	if err := role.CheckAndSetDefaults(); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
 
-		_, err = a.Client.UpsertRole(ctx, &role)
-		if err != nil {
-			return err
-		}
-	} else {
+// deleteRole deletes the role. Roles already removed by Teleport on expiry are ignored
+func (a *TeleportProvider) deleteRole(ctx context.Context, RoleName string) error {
+	ctx, span := tracing.NewSpanWrapper(ctx, "teleport.deleteRole")
+	span.SetAttributes(
+		attribute.String("peer.service", "teleport"),
+		attribute.String("span.kind", "client"),
+	)
+	defer span.End()
+
+	err := a.Client.DeleteRole(ctx, RoleName)
+	if err != nil && !trace.IsNotFound(err) {
 		return err
 	}
 	return nil
 }
 
-func (a *TeleportProvider) removeRoleFromUser(ctx context.Context, Username string, RoleName string) error {
+// removeRoleFromUser removes roles from the user. Returns false when the user had none of them
+func (a *TeleportProvider) removeRoleFromUser(ctx context.Context, Username string, rolesToRemove []string) (bool, error) {
 	ctx, span := tracing.NewSpanWrapper(ctx, "teleport.removeRoleFromUser")
 	span.SetAttributes(
 		attribute.String("peer.service", "teleport"),
@@ -87,14 +132,16 @@ func (a *TeleportProvider) removeRoleFromUser(ctx context.Context, Username stri
 	defer span.End()
 
 	user, err := a.Client.GetUser(ctx, Username, false)
+	if trace.IsNotFound(err) {
+		return false, errUserNotFound
+	}
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	filteredRoles := []string{}
 
 	currentRoles := user.GetRoles()
-	rolesToRemove := a.parseRoles(ctx, RoleName)
 
 	for _, role := range currentRoles {
 		if !slices.Contains(rolesToRemove, role) {
@@ -102,23 +149,80 @@ func (a *TeleportProvider) removeRoleFromUser(ctx context.Context, Username stri
 		}
 	}
 
+	if len(filteredRoles) == len(currentRoles) {
+		return false, nil
+	}
+
 	user.SetRoles(filteredRoles)
 
 	_, err = a.Client.UpdateUser(ctx, user)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	return nil
+	return true, nil
+}
+
+// listUsersWithRoles returns users holding all static roles, or any per-request role of the group
+func (a *TeleportProvider) listUsersWithRoles(ctx context.Context) ([]string, error) {
+	ctx, span := tracing.NewSpanWrapper(ctx, "teleport.listUsersWithRoles")
+	span.SetAttributes(
+		attribute.String("peer.service", "teleport"),
+		attribute.String("span.kind", "client"),
+	)
+	defer span.End()
+
+	users, err := a.Client.GetUsers(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	usernames := []string{}
+	for _, user := range users {
+		if a.hasAccess(user.GetRoles()) {
+			usernames = append(usernames, user.GetName())
+		}
+	}
+	return usernames, nil
 }
 
-func (a *TeleportProvider) parseRoles(ctx context.Context, group string) []string {
+func (a *TeleportProvider) hasAccess(userRoles []string) bool {
+	if a.Parameters.GroupDefinition != "" {
+		return slices.ContainsFunc(userRoles, func(role string) bool {
+			return isEphemeralRole(a.Parameters.Group, role)
+		})
+	}
 
-	roles := strings.Split(group, ",")
+	for _, role := range a.parseRoles(a.Parameters.Group) {
+		if !slices.Contains(userRoles, role) {
+			return false
+		}
+	}
+	return true
+}
+
+// ephemeralRoleName returns name of the role created for the request
+func ephemeralRoleName(group string, requestID string) string {
+	return group + "-" + requestID
+}
 
-	if len(roles) > 0 {
-		return roles
+// isEphemeralRole reports whether role was created for a request from the group
+func isEphemeralRole(group string, role string) bool {
+	id, ok := strings.CutPrefix(role, group+"-")
+	if !ok {
+		return false
 	}
+	_, err := uuid.Parse(id)
+	return err == nil
+}
+
+func (a *TeleportProvider) parseRoles(group string) []string {
 
-	return []string{"group"}
+	roles := []string{}
+	for _, role := range strings.Split(group, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return roles
 }
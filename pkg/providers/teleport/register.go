@@ -0,0 +1,32 @@
+package teleport
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:             string(kinds.ProviderKindTeleport),
+		RequiresUsername: true,
+		Description:      "Manages Teleport user roles",
+		Parameters: []registry.Parameter{
+			{Name: "group", Description: "Comma separated Teleport role names. With groupDefinition, prefix of the per-request role", Required: true, Example: "pu-role"},
+			{Name: "groupDefinition", Description: "Role spec in YAML. When set, a role expiring with the request is created for each request"},
+			{Name: "username", Description: "Teleport username used when the requester has none set"},
+		},
+		Credentials: []registry.Parameter{
+			{Name: "credentialsfile", Description: "Path to Teleport identity file", Required: true, Example: "/secrets/teleport-identity"},
+			{Name: "hostname", Description: "Teleport proxy address", Required: true, Example: "teleport.example.com:443"},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewTeleportProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
@@ -4,28 +4,60 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/CTO2BPublic/passage-server/pkg/config"
 	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
 	"github.com/CTO2BPublic/passage-server/pkg/tracing"
 
 	"github.com/gravitational/teleport/api/client"
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
 	"github.com/rs/zerolog/log"
 	"go.opentelemetry.io/otel"
 )
 
+const providerType = string(kinds.ProviderKindTeleport)
+
 var Config = config.GetConfig()
 var Tracer = otel.Tracer("pkg/providers/Teleport")
 
+// Clients shared between provider instances, keyed by proxy address and identity file
+var (
+	clientsMu sync.Mutex
+	clients   = map[string]teleportClient{}
+	// connect opens a new client. Replaced in tests
+	connect = newClient
+)
+
+// staleClientTTL is how long an evicted client stays open for calls other providers still run on it
+const staleClientTTL = 5 * time.Minute
+
+// teleportClient is the part of Teleport API client used by the provider
+type teleportClient interface {
+	GetUser(ctx context.Context, name string, withSecrets bool) (types.User, error)
+	GetUsers(ctx context.Context, withSecrets bool) ([]types.User, error)
+	UpdateUser(ctx context.Context, user types.User) (types.User, error)
+	UpsertRole(ctx context.Context, role types.Role) (types.Role, error)
+	DeleteRole(ctx context.Context, name string) error
+	Close() error
+}
+
 type TeleportProvider struct {
-	Client     *client.Client
+	Client     teleportClient
 	Parameters TeleportProviderParameters
 	Name       string `json:"name"`
 }
 
 type TeleportProviderParameters struct {
-	Group           string `json:"group"`
+	Group string `json:"group"`
+	// GroupDefinition is a role spec in YAML. When set, a role named <group>-<request id> is created
+	// for each request and expires together with the request
 	GroupDefinition string `json:"groupDefinition"`
+	// Username is used when the request carries no Teleport username
 	Username        string `json:"username"`
 	CredentialsFile string `json:"credentialsFile"`
 	Hostname        string `json:"hostname"`
@@ -37,7 +69,60 @@ func NewTeleportProvider(ctx context.Context, config models.ProviderConfig) (*Te
 		return nil, fmt.Errorf("failed to parse provider config: %w", err)
 	}
 
-	client, err := client.New(ctx, client.Config{
+	client, err := getClient(ctx, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TeleportProvider{Parameters: parameters, Name: config.Name, Client: client}, nil
+}
+
+// getClient returns a pooled client for the proxy and identity file, connecting on first use
+func getClient(ctx context.Context, parameters TeleportProviderParameters) (teleportClient, error) {
+	key := clientKey(parameters)
+
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	if c, ok := clients[key]; ok {
+		return c, nil
+	}
+
+	c, err := connect(ctx, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	clients[key] = c
+	return c, nil
+}
+
+// evictClient drops the pooled client, unless it was already replaced by another caller.
+// The client is shared, so it is closed only after in-flight calls had time to finish
+func evictClient(parameters TeleportProviderParameters, stale teleportClient) {
+	key := clientKey(parameters)
+
+	clientsMu.Lock()
+	evicted := clients[key] == stale
+	if evicted {
+		delete(clients, key)
+	}
+	clientsMu.Unlock()
+
+	if evicted {
+		time.AfterFunc(staleClientTTL, func() {
+			_ = stale.Close()
+		})
+	}
+}
+
+func clientKey(parameters TeleportProviderParameters) string {
+	return parameters.Hostname + "|" + parameters.CredentialsFile
+}
+
+// newClient connects to the proxy with the identity file and checks the connection
+func newClient(ctx context.Context, parameters TeleportProviderParameters) (teleportClient, error) {
+	c, err := client.New(ctx, client.Config{
 		Addrs: []string{
 			parameters.Hostname,
 		},
@@ -49,77 +134,201 @@ func NewTeleportProvider(ctx context.Context, config models.ProviderConfig) (*Te
 		return nil, err
 	}
 
-	// defer client.Close()
-	_, err = client.Ping(ctx)
-	if err != nil {
+	if _, err := c.Ping(ctx); err != nil {
+		c.Close()
 		return nil, err
 	}
+	return c, nil
+}
 
-	return &TeleportProvider{Parameters: parameters, Name: config.Name, Client: client}, nil
+// call runs fn and retries it once with a new client when the connection fails or the certificate has expired,
+// so identity files renewed by tbot are picked up
+func (a *TeleportProvider) call(ctx context.Context, fn func() error) error {
+	err := fn()
+	if !isConnectionError(err) {
+		return err
+	}
+
+	log.Warn().
+		Str("Provider", a.Name).
+		Str("Hostname", a.Parameters.Hostname).
+		Err(err).
+		Msg("Reconnecting to Teleport")
+
+	evictClient(a.Parameters, a.Client)
+	c, connectErr := getClient(ctx, a.Parameters)
+	if connectErr != nil {
+		return fmt.Errorf("%w. Reconnect failed: %w", err, connectErr)
+	}
+	a.Client = c
+
+	return fn()
 }
 
+// isConnectionError reports errors fixed by reconnecting. Other access denied errors are permission problems
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if trace.IsConnectionProblem(err) {
+		return true
+	}
+	return trace.IsAccessDenied(err) && strings.Contains(strings.ToLower(err.Error()), "expired")
+}
+
+// GrantAccess adds the roles to the user. With GroupDefinition a per-request role is created first
 func (a *TeleportProvider) GrantAccess(ctx context.Context, request *models.AccessRequest) error {
 	ctx, span := tracing.NewSpanWrapper(ctx, "providers.teleport.GrantAccess")
 	defer span.End()
 
 	parameters := a.Parameters
+	username := a.username(request)
+	roles := a.requestRoles(request)
+	group := strings.Join(roles, ",")
 
 	if parameters.GroupDefinition != "" {
-		err := a.upsertRole(parameters.Group, parameters.GroupDefinition)
+		expires, err := request.Expiration(time.Now())
+		if err != nil {
+			request.SetProviderStatusError(a.Name, group, err.Error())
+			return err
+		}
+
+		err = a.call(ctx, func() error {
+			return a.upsertRole(ctx, roles[0], parameters.GroupDefinition, request.Id, expires)
+		})
 		if err != nil {
-			request.SetProviderStatusError(a.Name, parameters.Group, err.Error())
+			request.SetProviderStatusError(a.Name, group, err.Error())
 			return fmt.Errorf("failed to create teleport role: %w", err)
 		}
 	}
 
-	_, err := a.addRoleToUser(ctx, parameters.Username, parameters.Group)
+	var added bool
+	err := a.call(ctx, func() (err error) {
+		added, err = a.addRoleToUser(ctx, username, roles)
+		return err
+	})
 	if err != nil {
-		request.SetProviderStatusError(a.Name, parameters.Group, err.Error())
+		request.SetProviderStatusError(a.Name, group, err.Error())
 		return fmt.Errorf("failed to add user to group: %w", err)
 	}
 
-	request.SetProviderStatusGranted(a.Name, parameters.Group, "")
+	if !added {
+		request.SetProviderStatusGranted(a.Name, group, "already granted")
+		log.Info().
+			Str("TraceID", span.GetTraceID()).
+			Str("Provider", a.Name).
+			Str("AccessRequest", request.Id).
+			Str("Username", username).
+			Str("Group", group).
+			Msg("User already in group")
+		return nil
+	}
+
+	request.SetProviderStatusGranted(a.Name, group, "")
 	log.Info().
 		Str("TraceID", span.GetTraceID()).
 		Str("Provider", a.Name).
 		Str("AccessRequest", request.Id).
-		Str("Username", parameters.Username).
-		Str("Group", parameters.Group).
+		Str("Username", username).
+		Str("Group", group).
 		Msg("User added to group")
 
 	return nil
 }
 
+// RevokeAccess removes the roles from the user. Per-request roles are deleted as well
 func (a *TeleportProvider) RevokeAccess(ctx context.Context, request *models.AccessRequest) error {
-	_, span := tracing.NewSpanWrapper(ctx, "providers.teleport.RevokeAccess")
+	ctx, span := tracing.NewSpanWrapper(ctx, "providers.teleport.RevokeAccess")
 	defer span.End()
 
 	parameters := a.Parameters
-	err := a.removeRoleFromUser(ctx, parameters.Username, parameters.Group)
-	if err != nil {
-		request.SetProviderStatusError(a.Name, parameters.Group, err.Error())
+	username := a.username(request)
+	roles := a.requestRoles(request)
+	group := strings.Join(roles, ",")
+
+	var removed bool
+	err := a.call(ctx, func() (err error) {
+		removed, err = a.removeRoleFromUser(ctx, username, roles)
+		return err
+	})
+	userNotFound := errors.Is(err, errUserNotFound)
+	if err != nil && !userNotFound {
+		request.SetProviderStatusError(a.Name, group, err.Error())
 		return fmt.Errorf("failed to remove user from group: %w", err)
 	}
 
-	request.SetProviderStatusRevoked(a.Name, parameters.Group, "")
+	if parameters.GroupDefinition != "" {
+		err := a.call(ctx, func() error {
+			return a.deleteRole(ctx, roles[0])
+		})
+		if err != nil {
+			request.SetProviderStatusError(a.Name, group, err.Error())
+			return fmt.Errorf("failed to delete teleport role: %w", err)
+		}
+	}
+
+	switch {
+	case userNotFound:
+		request.SetProviderStatusRevoked(a.Name, group, "user not found")
+	case !removed:
+		request.SetProviderStatusRevoked(a.Name, group, "already revoked")
+	default:
+		request.SetProviderStatusRevoked(a.Name, group, "")
+	}
 	log.Info().
 		Str("TraceID", span.GetTraceID()).
 		Str("Provider", a.Name).
 		Str("AccessRequest", request.Id).
-		Str("Username", parameters.Username).
-		Str("Group", parameters.Group).
+		Str("Username", username).
+		Str("Group", group).
 		Msg("User removed from group")
 
 	return nil
 }
 
+// ListUsersWithAccess lists users holding all configured roles, or any per-request role of the group
 func (a *TeleportProvider) ListUsersWithAccess(ctx context.Context, roleRef models.AccessRoleRef) ([]string, error) {
-	return []string{"user1", "user2"}, nil
+	ctx, span := tracing.NewSpanWrapper(ctx, "providers.teleport.ListUsersWithAccess")
+	defer span.End()
+
+	var users []string
+	err := a.call(ctx, func() (err error) {
+		users, err = a.listUsersWithRoles(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	return users, nil
+}
+
+// IsRequestScoped reports whether roles are created per request with groupDefinition
+func (a *TeleportProvider) IsRequestScoped() bool {
+	return a.Parameters.GroupDefinition != ""
 }
 
+// IsAccessExpired checks whether the access for the given request has expired
 func (a *TeleportProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
-	fmt.Printf("true")
-	return true, nil
+	_, span := tracing.NewSpanWrapper(ctx, "providers.teleport.IsAccessExpired")
+	defer span.End()
+
+	return request.IsExpired(time.Now())
+}
+
+// username returns Teleport username of the requester, falling back to the username parameter
+func (a *TeleportProvider) username(request *models.AccessRequest) string {
+	if username := request.GetProviderUsername(providerType); username != "" {
+		return username
+	}
+	return a.Parameters.Username
+}
+
+// requestRoles returns roles granted for the request
+func (a *TeleportProvider) requestRoles(request *models.AccessRequest) []string {
+	if a.Parameters.GroupDefinition != "" {
+		return []string{ephemeralRoleName(a.Parameters.Group, request.Id)}
+	}
+	return a.parseRoles(a.Parameters.Group)
 }
 
 func extractParameters(config models.ProviderConfig) (TeleportProviderParameters, error) {
@@ -135,20 +344,19 @@ func extractParameters(config models.ProviderConfig) (TeleportProviderParameters
 		return TeleportProviderParameters{}, errors.New("group not found in provider config")
 	}
 
-	username, ok := data["username"]
-	if !ok {
-		return TeleportProviderParameters{}, errors.New("username not found in provider config")
-	}
-
 	groupDefinition, ok := data["groupDefinition"]
 	if !ok {
 		groupDefinition = ""
 	}
 
+	if groupDefinition != "" && strings.Contains(group, ",") {
+		return TeleportProviderParameters{}, errors.New("group must be a single role name with groupDefinition")
+	}
+
 	return TeleportProviderParameters{
 		Group:           group,
 		GroupDefinition: groupDefinition,
-		Username:        username,
+		Username:        data["username"],
 		CredentialsFile: credentialsFile,
 		Hostname:        hostname,
 	}, nil
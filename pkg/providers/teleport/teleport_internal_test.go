@@ -0,0 +1,243 @@
+package teleport
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const roleDefinition = `
+kind: role
+version: v6
+spec:
+  allow:
+    logins: ["ubuntu"]
+    node_labels:
+      env: prod
+`
+
+// fakeClient keeps users and roles in memory. Calls fail with err when set
+type fakeClient struct {
+	users  map[string]types.User
+	roles  map[string]types.Role
+	err    error
+	closed bool
+}
+
+func newFakeClient(users ...string) *fakeClient {
+	c := &fakeClient{users: map[string]types.User{}, roles: map[string]types.Role{}}
+	for _, name := range users {
+		user, _ := types.NewUser(name)
+		c.users[name] = user
+	}
+	return c
+}
+
+func (c *fakeClient) GetUser(ctx context.Context, name string, withSecrets bool) (types.User, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	user, ok := c.users[name]
+	if !ok {
+		return nil, trace.NotFound("user %q is not found", name)
+	}
+	return user, nil
+}
+
+func (c *fakeClient) GetUsers(ctx context.Context, withSecrets bool) ([]types.User, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	users := []types.User{}
+	for _, user := range c.users {
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (c *fakeClient) UpdateUser(ctx context.Context, user types.User) (types.User, error) {
+	c.users[user.GetName()] = user
+	return user, nil
+}
+
+func (c *fakeClient) UpsertRole(ctx context.Context, role types.Role) (types.Role, error) {
+	c.roles[role.GetName()] = role
+	return role, nil
+}
+
+func (c *fakeClient) DeleteRole(ctx context.Context, name string) error {
+	if _, ok := c.roles[name]; !ok {
+		return trace.NotFound("role %q is not found", name)
+	}
+	delete(c.roles, name)
+	return nil
+}
+
+func (c *fakeClient) Close() error {
+	c.closed = true
+	return nil
+}
+
+func testProvider(t *testing.T, client *fakeClient, parameters map[string]string) *TeleportProvider {
+	config := models.ProviderConfig{Name: "Teleport", Provider: providerType, Parameters: parameters}
+	p, err := extractParameters(config)
+	require.NoError(t, err)
+	return &TeleportProvider{Client: client, Parameters: p, Name: config.Name}
+}
+
+func testRequest(username string) *models.AccessRequest {
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+	request := &models.AccessRequest{Id: "8f1c2f7e-4b7a-4d0e-9a51-0a3f6a4c2b10"}
+	request.Status.ExpiresAt = &expires
+	request.Status.ProviderUsernames = map[string]string{providerType: username}
+	return request
+}
+
+func TestEphemeralRole(t *testing.T) {
+	client := newFakeClient("alice", "bob")
+	p := testProvider(t, client, map[string]string{"group": "prod-ssh", "groupDefinition": roleDefinition})
+
+	request := testRequest("alice")
+	roleName := "prod-ssh-" + request.Id
+
+	require.NoError(t, p.GrantAccess(context.Background(), request))
+	assert.Equal(t, models.ProviderStatus{Action: models.ProviderStatusGranted, Details: roleName}, request.Status.ProviderStatuses["Teleport"])
+
+	role, ok := client.roles[roleName]
+	require.True(t, ok)
+	assert.Equal(t, *request.Status.ExpiresAt, role.Expiry())
+	assert.Equal(t, request.Id, role.GetMetadata().Labels[requestLabel])
+	assert.Equal(t, []string{"ubuntu"}, role.GetLogins(types.Allow))
+	assert.Contains(t, client.users["alice"].GetRoles(), roleName)
+
+	// Second grant is idempotent
+	require.NoError(t, p.GrantAccess(context.Background(), request))
+	assert.Equal(t, "already granted", request.Status.ProviderStatuses["Teleport"].Error)
+
+	users, err := p.ListUsersWithAccess(context.Background(), models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, users)
+
+	require.NoError(t, p.RevokeAccess(context.Background(), request))
+	assert.Equal(t, models.ProviderStatus{Action: models.ProviderStatusRevoked, Details: roleName}, request.Status.ProviderStatuses["Teleport"])
+	assert.NotContains(t, client.users["alice"].GetRoles(), roleName)
+	assert.NotContains(t, client.roles, roleName)
+
+	// Role already expired in Teleport
+	require.NoError(t, p.RevokeAccess(context.Background(), request))
+	assert.Equal(t, "already revoked", request.Status.ProviderStatuses["Teleport"].Error)
+}
+
+func TestStaticRoles(t *testing.T) {
+	client := newFakeClient("alice", "bob")
+	p := testProvider(t, client, map[string]string{"group": "access, auditor", "username": "bob"})
+
+	request := testRequest("alice")
+	require.NoError(t, p.GrantAccess(context.Background(), request))
+	assert.Equal(t, "access,auditor", request.Status.ProviderStatuses["Teleport"].Details)
+	assert.ElementsMatch(t, []string{"access", "auditor"}, client.users["alice"].GetRoles())
+	assert.Empty(t, client.roles)
+
+	users, err := p.ListUsersWithAccess(context.Background(), models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, users)
+
+	// Username parameter is used when request has none
+	request = testRequest("")
+	require.NoError(t, p.GrantAccess(context.Background(), request))
+	assert.ElementsMatch(t, []string{"access", "auditor"}, client.users["bob"].GetRoles())
+
+	require.NoError(t, p.RevokeAccess(context.Background(), request))
+	assert.Empty(t, client.users["bob"].GetRoles())
+}
+
+func TestRevokeMissingUser(t *testing.T) {
+	client := newFakeClient()
+	p := testProvider(t, client, map[string]string{"group": "prod-ssh", "groupDefinition": roleDefinition})
+
+	request := testRequest("carol")
+	assert.Error(t, p.GrantAccess(context.Background(), request))
+	assert.Equal(t, models.ProviderStatusError, request.Status.ProviderStatuses["Teleport"].Action)
+
+	require.NoError(t, p.RevokeAccess(context.Background(), request))
+	assert.Equal(t, "user not found", request.Status.ProviderStatuses["Teleport"].Error)
+	assert.Empty(t, client.roles)
+}
+
+func TestReconnect(t *testing.T) {
+	fresh := newFakeClient("alice")
+	connects := 0
+	connect = func(ctx context.Context, parameters TeleportProviderParameters) (teleportClient, error) {
+		connects++
+		return fresh, nil
+	}
+	t.Cleanup(func() {
+		connect = newClient
+		clients = map[string]teleportClient{}
+	})
+
+	// Pooled client lost its identity after tbot renewed the certificate
+	stale := newFakeClient("alice")
+	stale.err = trace.AccessDenied("certificate has expired")
+	p := testProvider(t, stale, map[string]string{"group": "access"})
+	clients[clientKey(p.Parameters)] = stale
+
+	request := testRequest("alice")
+	require.NoError(t, p.GrantAccess(context.Background(), request))
+	assert.Contains(t, fresh.users["alice"].GetRoles(), "access")
+	assert.False(t, stale.closed, "stale client is still shared with other providers")
+	assert.Same(t, fresh, clients[clientKey(p.Parameters)])
+
+	// Other providers holding the stale client reuse the new connection
+	other := testProvider(t, stale, map[string]string{"group": "access"})
+	stale.err = trace.ConnectionProblem(nil, "connection refused")
+	users, err := other.ListUsersWithAccess(context.Background(), models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, users)
+	assert.Equal(t, 1, connects)
+
+	// Other errors are not retried
+	fresh.err = trace.BadParameter("invalid user")
+	assert.Error(t, p.GrantAccess(context.Background(), request))
+	assert.Equal(t, 1, connects)
+
+	// Missing permissions are not fixed by reconnecting
+	fresh.err = trace.AccessDenied("access denied to perform action \"update\" on \"user\"")
+	assert.Error(t, p.GrantAccess(context.Background(), request))
+	assert.Equal(t, 1, connects)
+	assert.False(t, fresh.closed)
+}
+
+func TestIsAccessExpired(t *testing.T) {
+	p := testProvider(t, newFakeClient(), map[string]string{"group": "access"})
+
+	expired, err := p.IsAccessExpired(context.Background(), testRequest("alice"))
+	require.NoError(t, err)
+	assert.False(t, expired)
+
+	request := testRequest("alice")
+	past := time.Now().Add(-time.Minute)
+	request.Status.ExpiresAt = &past
+	expired, err = p.IsAccessExpired(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, expired)
+}
+
+func TestExtractParameters(t *testing.T) {
+	_, err := extractParameters(models.ProviderConfig{Parameters: map[string]string{"group": "a,b", "groupDefinition": roleDefinition}})
+	assert.Error(t, err)
+
+	_, err = extractParameters(models.ProviderConfig{Parameters: map[string]string{}})
+	assert.Error(t, err)
+
+	assert.True(t, isEphemeralRole("prod-ssh", "prod-ssh-8f1c2f7e-4b7a-4d0e-9a51-0a3f6a4c2b10"))
+	assert.False(t, isEphemeralRole("prod", "prod-ssh-8f1c2f7e-4b7a-4d0e-9a51-0a3f6a4c2b10"))
+	assert.False(t, isEphemeralRole("prod-ssh", "prod-ssh-admin"))
+}
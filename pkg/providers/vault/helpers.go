@@ -0,0 +1,522 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const maxErrorBody = 512
+
+var errEntityNotFound = errors.New("entity not found")
+
+// AppRole tokens shared between provider instances, keyed by address, namespace and role id
+var (
+	tokensMu sync.Mutex
+	tokens   = map[string]loginToken{}
+)
+
+type loginToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+// authMethod holds a static token or AppRole credentials used to log in
+type authMethod struct {
+	token        string
+	roleID       string
+	secretID     string
+	approleMount string
+}
+
+// response is the common Vault response envelope
+type response struct {
+	Data json.RawMessage `json:"data"`
+	Auth *tokenAuth      `json:"auth"`
+}
+
+type tokenAuth struct {
+	ClientToken   string `json:"client_token"`
+	Accessor      string `json:"accessor"`
+	LeaseDuration int    `json:"lease_duration"`
+}
+
+type alias struct {
+	Name          string `json:"name"`
+	MountAccessor string `json:"mount_accessor"`
+}
+
+type entity struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Policies []string `json:"policies"`
+	Aliases  []alias  `json:"aliases"`
+}
+
+type group struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Type            string   `json:"type"`
+	MemberEntityIDs []string `json:"member_entity_ids"`
+}
+
+// statusError is returned for unexpected HTTP response codes
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	if e.body == "" {
+		return fmt.Sprintf("unexpected status %d", e.code)
+	}
+	return fmt.Sprintf("unexpected status %d: %s", e.code, e.body)
+}
+
+func isNotFound(err error) bool {
+	var statusErr *statusError
+	return errors.As(err, &statusErr) && statusErr.code == http.StatusNotFound
+}
+
+// call sends request to the Vault HTTP API and returns decoded response envelope. Empty responses return nil
+func (p *VaultProvider) call(ctx context.Context, method string, path string, in any) (*response, error) {
+	ctx, span := startSpan(ctx, "call")
+	span.SetAttributes(
+		attribute.String("span.kind", "client"),
+		attribute.String("http.method", method),
+	)
+	defer span.End()
+
+	token, err := p.authToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to log in: %w", err)
+	}
+
+	resp, err := p.send(ctx, method, path, token, in)
+	if err != nil {
+		var statusErr *statusError
+		if errors.As(err, &statusErr) && statusErr.code == http.StatusForbidden && p.auth.token == "" {
+			// Drop cached token so next call logs in again
+			tokensMu.Lock()
+			delete(tokens, p.tokenKey())
+			tokensMu.Unlock()
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// send performs a single request with the given client token
+func (p *VaultProvider) send(ctx context.Context, method string, path string, token string, in any) (*response, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.address+"/v1/"+strings.TrimPrefix(path, "/"), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &statusError{code: resp.StatusCode, body: vaultErrors(respBody)}
+	}
+
+	if len(respBody) == 0 {
+		return nil, nil
+	}
+
+	var out response
+	if err := json.Unmarshal(respBody, &out); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	return &out, nil
+}
+
+// authToken returns the static token or a cached AppRole token, logging in when it is about to expire
+func (p *VaultProvider) authToken(ctx context.Context) (string, error) {
+	if p.auth.token != "" {
+		return p.auth.token, nil
+	}
+
+	tokensMu.Lock()
+	token, ok := tokens[p.tokenKey()]
+	tokensMu.Unlock()
+	if ok && time.Now().Add(time.Minute).Before(token.expiresAt) {
+		return token.value, nil
+	}
+
+	resp, err := p.send(ctx, http.MethodPost, "auth/"+p.auth.approleMount+"/login", "", map[string]string{
+		"role_id":   p.auth.roleID,
+		"secret_id": p.auth.secretID,
+	})
+	if err != nil {
+		return "", err
+	}
+	if resp == nil || resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return "", errors.New("login response has no client token")
+	}
+
+	token = loginToken{value: resp.Auth.ClientToken, expiresAt: time.Now().Add(time.Duration(resp.Auth.LeaseDuration) * time.Second)}
+	if resp.Auth.LeaseDuration == 0 {
+		// Tokens without TTL do not expire
+		token.expiresAt = time.Now().Add(24 * time.Hour)
+	}
+
+	tokensMu.Lock()
+	tokens[p.tokenKey()] = token
+	tokensMu.Unlock()
+
+	return token.value, nil
+}
+
+func (p *VaultProvider) tokenKey() string {
+	return p.address + "|" + p.namespace + "|" + p.auth.approleMount + "|" + p.auth.roleID
+}
+
+// vaultErrors joins messages from Vault error response
+func vaultErrors(body []byte) string {
+	var vaultErr struct {
+		Errors []string `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &vaultErr); err == nil && len(vaultErr.Errors) > 0 {
+		return truncate(strings.Join(vaultErr.Errors, "; "), maxErrorBody)
+	}
+	return truncate(string(body), maxErrorBody)
+}
+
+// findEntity resolves username to entity by name, or by alias on authMount when set
+func (p *VaultProvider) findEntity(ctx context.Context, username string) (entity, error) {
+	if username == "" {
+		return entity{}, errors.New("vault username is not set")
+	}
+
+	var resp *response
+	var err error
+	if p.authMount == "" {
+		resp, err = p.call(ctx, http.MethodGet, "identity/entity/name/"+url.PathEscape(username), nil)
+		if isNotFound(err) {
+			return entity{}, fmt.Errorf("%w: %s", errEntityNotFound, username)
+		}
+	} else {
+		accessor, accessorErr := p.mountAccessor(ctx)
+		if accessorErr != nil {
+			return entity{}, accessorErr
+		}
+		resp, err = p.call(ctx, http.MethodPost, "identity/lookup/entity", map[string]string{
+			"alias_name":           username,
+			"alias_mount_accessor": accessor,
+		})
+	}
+	if err != nil {
+		return entity{}, fmt.Errorf("failed to look up entity: %w", err)
+	}
+	// Lookup returns 204 without body when alias does not exist
+	if resp == nil || len(resp.Data) == 0 || string(resp.Data) == "null" {
+		return entity{}, fmt.Errorf("%w: %s", errEntityNotFound, username)
+	}
+
+	var e entity
+	if err := json.Unmarshal(resp.Data, &e); err != nil {
+		return entity{}, fmt.Errorf("invalid entity: %w", err)
+	}
+	return e, nil
+}
+
+// mountAccessor returns accessor of authMount used by entity aliases
+func (p *VaultProvider) mountAccessor(ctx context.Context) (string, error) {
+	resp, err := p.call(ctx, http.MethodGet, "sys/auth", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to list auth mounts: %w", err)
+	}
+	if resp == nil {
+		return "", errors.New("failed to list auth mounts: empty response")
+	}
+
+	var mounts map[string]struct {
+		Accessor string `json:"accessor"`
+	}
+	if err := json.Unmarshal(resp.Data, &mounts); err != nil {
+		return "", fmt.Errorf("invalid auth mounts: %w", err)
+	}
+
+	mount, ok := mounts[p.authMount+"/"]
+	if !ok || mount.Accessor == "" {
+		return "", fmt.Errorf("auth mount %s not found", p.authMount)
+	}
+	return mount.Accessor, nil
+}
+
+// readEntity reads entity by id
+func (p *VaultProvider) readEntity(ctx context.Context, id string) (entity, error) {
+	resp, err := p.call(ctx, http.MethodGet, "identity/entity/id/"+url.PathEscape(id), nil)
+	if err != nil {
+		return entity{}, err
+	}
+	if resp == nil {
+		return entity{}, errEntityNotFound
+	}
+
+	var e entity
+	if err := json.Unmarshal(resp.Data, &e); err != nil {
+		return entity{}, fmt.Errorf("invalid entity: %w", err)
+	}
+	return e, nil
+}
+
+// listEntities returns all entities with their names and aliases keyed by id
+func (p *VaultProvider) listEntities(ctx context.Context) (map[string]entity, error) {
+	resp, err := p.call(ctx, http.MethodGet, "identity/entity/id?list=true", nil)
+	if isNotFound(err) {
+		return map[string]entity{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return map[string]entity{}, nil
+	}
+
+	var list struct {
+		KeyInfo map[string]entity `json:"key_info"`
+	}
+	if err := json.Unmarshal(resp.Data, &list); err != nil {
+		return nil, fmt.Errorf("invalid entity list: %w", err)
+	}
+	if list.KeyInfo == nil {
+		return map[string]entity{}, nil
+	}
+	for id, e := range list.KeyInfo {
+		e.ID = id
+		list.KeyInfo[id] = e
+	}
+	return list.KeyInfo, nil
+}
+
+// attach adds policy or group membership to entity. Returns false when already attached
+func (p *VaultProvider) attach(ctx context.Context, e entity) (bool, error) {
+	if p.policy != "" {
+		return p.attachPolicy(ctx, e)
+	}
+	return p.addGroupMember(ctx, e.ID)
+}
+
+// detach removes policy or group membership from entity. Returns false when not attached
+func (p *VaultProvider) detach(ctx context.Context, e entity) (bool, error) {
+	if p.policy != "" {
+		return p.detachPolicy(ctx, e)
+	}
+	return p.removeGroupMember(ctx, e.ID)
+}
+
+// rollback detaches policy or group just attached to the user's entity
+func (p *VaultProvider) rollback(ctx context.Context, username string) error {
+	e, err := p.findEntity(ctx, username)
+	if err != nil {
+		return err
+	}
+	_, err = p.detach(ctx, e)
+	return err
+}
+
+// attachPolicy adds policy to entity policies. Returns false when already attached
+func (p *VaultProvider) attachPolicy(ctx context.Context, e entity) (bool, error) {
+	if slices.Contains(e.Policies, p.policy) {
+		return false, nil
+	}
+
+	policies := append(slices.Clone(e.Policies), p.policy)
+	if _, err := p.call(ctx, http.MethodPost, "identity/entity/id/"+url.PathEscape(e.ID), map[string][]string{"policies": policies}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// detachPolicy removes policy from entity policies. Returns false when not attached
+func (p *VaultProvider) detachPolicy(ctx context.Context, e entity) (bool, error) {
+	if !slices.Contains(e.Policies, p.policy) {
+		return false, nil
+	}
+
+	policies := slices.DeleteFunc(slices.Clone(e.Policies), func(policy string) bool { return policy == p.policy })
+	if _, err := p.call(ctx, http.MethodPost, "identity/entity/id/"+url.PathEscape(e.ID), map[string][]string{"policies": policies}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// readGroup reads the configured identity group. Only internal groups have explicit members
+func (p *VaultProvider) readGroup(ctx context.Context) (group, error) {
+	resp, err := p.call(ctx, http.MethodGet, "identity/group/name/"+url.PathEscape(p.group), nil)
+	if isNotFound(err) || err == nil && resp == nil {
+		return group{}, fmt.Errorf("group %s not found", p.group)
+	}
+	if err != nil {
+		return group{}, err
+	}
+
+	var g group
+	if err := json.Unmarshal(resp.Data, &g); err != nil {
+		return group{}, fmt.Errorf("invalid group: %w", err)
+	}
+	if g.Type != "" && g.Type != "internal" {
+		return group{}, fmt.Errorf("group %s is %s, only internal groups are supported", p.group, g.Type)
+	}
+	return g, nil
+}
+
+// addGroupMember adds entity to group members. Returns false when already a member
+func (p *VaultProvider) addGroupMember(ctx context.Context, entityID string) (bool, error) {
+	g, err := p.readGroup(ctx)
+	if err != nil {
+		return false, err
+	}
+	if slices.Contains(g.MemberEntityIDs, entityID) {
+		return false, nil
+	}
+
+	members := append(slices.Clone(g.MemberEntityIDs), entityID)
+	if err := p.updateGroupMembers(ctx, members); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// removeGroupMember removes entity from group members. Returns false when not a member
+func (p *VaultProvider) removeGroupMember(ctx context.Context, entityID string) (bool, error) {
+	g, err := p.readGroup(ctx)
+	if err != nil {
+		return false, err
+	}
+	if !slices.Contains(g.MemberEntityIDs, entityID) {
+		return false, nil
+	}
+
+	members := slices.DeleteFunc(slices.Clone(g.MemberEntityIDs), func(id string) bool { return id == entityID })
+	if err := p.updateGroupMembers(ctx, members); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (p *VaultProvider) updateGroupMembers(ctx context.Context, members []string) error {
+	if members == nil {
+		members = []string{}
+	}
+	_, err := p.call(ctx, http.MethodPost, "identity/group/name/"+url.PathEscape(p.group), map[string][]string{"member_entity_ids": members})
+	return err
+}
+
+// createToken issues a child token of the provider token that expires together with the request
+func (p *VaultProvider) createToken(ctx context.Context, username string, request *models.AccessRequest) (*tokenAuth, error) {
+	ttl := tokenTTL(request, time.Now())
+	if ttl <= 0 {
+		return nil, errors.New("access request has no remaining TTL")
+	}
+
+	body := map[string]any{
+		"ttl":              fmt.Sprintf("%ds", int(ttl.Seconds())),
+		"explicit_max_ttl": fmt.Sprintf("%ds", int(ttl.Seconds())),
+		"display_name":     "passage-" + request.Id,
+		"renewable":        false,
+		"meta": map[string]string{
+			"passage_request": request.Id,
+			"username":        username,
+		},
+	}
+
+	path := "auth/token/create"
+	if p.tokenRole != "" {
+		path += "/" + url.PathEscape(p.tokenRole)
+	}
+	if len(p.tokenPolicies) > 0 {
+		body["policies"] = p.tokenPolicies
+	}
+
+	resp, err := p.call(ctx, http.MethodPost, path, body)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil || resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return nil, errors.New("token response has no client token")
+	}
+	return resp.Auth, nil
+}
+
+// revokeToken revokes issued token by accessor. Tokens already expired or revoked are ignored
+func (p *VaultProvider) revokeToken(ctx context.Context, accessor string) error {
+	_, err := p.call(ctx, http.MethodPost, "auth/token/revoke-accessor", map[string]string{"accessor": accessor})
+
+	var statusErr *statusError
+	if errors.As(err, &statusErr) && statusErr.code == http.StatusBadRequest && strings.Contains(statusErr.body, "invalid accessor") {
+		return nil
+	}
+	return err
+}
+
+// tokenTTL returns time left until request expiry. Standing requests get no token TTL
+func tokenTTL(request *models.AccessRequest, now time.Time) time.Duration {
+	expires, err := request.Expiration(now)
+	if err != nil || expires == nil {
+		return 0
+	}
+	return expires.Sub(now).Truncate(time.Second)
+}
+
+// username returns entity alias name on the mount with given accessor, or entity name when accessor is empty
+func (e entity) username(accessor string) string {
+	if accessor == "" {
+		return e.Name
+	}
+	for _, a := range e.Aliases {
+		if a.MountAccessor == accessor {
+			return a.Name
+		}
+	}
+	return ""
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,49 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:             string(kinds.ProviderKindVault),
+		RequiresUsername: true,
+		Description:      "Attaches a policy or identity group to the user's Vault entity. Optionally issues a token that expires with the request",
+		Parameters: []registry.Parameter{
+			{Name: "policy", Description: "Policy added to entity policies. Either policy or group is required", Example: "payments-read"},
+			{Name: "group", Description: "Internal identity group name the entity is added to", Example: "payments-operators"},
+			{Name: "authMount", Description: "Auth mount path used to resolve usernames as entity aliases. Usernames are entity names when empty", Example: "oidc"},
+			{Name: "childToken", Description: "Issue a child token of the provider token with request TTL, retrieved once by the requester and revoked together with access", Example: "true"},
+			{Name: "tokenRole", Description: "Token role used to create the token", Example: "passage"},
+			{Name: "tokenPolicies", Description: "Comma-separated token policies. Defaults to policy", Example: "payments-read,default"},
+			{Name: "timeout", Description: "HTTP request timeout. Defaults to 30s", Example: "10s"},
+		},
+		Credentials: []registry.Parameter{
+			{Name: "address", Description: "Vault address", Required: true, Example: "https://vault.example.com:8200"},
+			{Name: "token", Description: "Vault token. Either token or roleid and secretid is required"},
+			{Name: "roleid", Description: "AppRole role id"},
+			{Name: "secretid", Description: "AppRole secret id"},
+			{Name: "approlemount", Description: "AppRole auth mount path. Defaults to approle", Example: "approle"},
+			{Name: "namespace", Description: "Vault Enterprise namespace", Example: "admin/platform"},
+		},
+		ValidateRole: func(role models.AccessRole, config models.ProviderConfig) error {
+			// Token TTL follows request TTL, standing requests have none
+			if childToken, _ := strconv.ParseBool(config.Parameters["childToken"]); childToken && role.Standing.Enabled {
+				return errors.New("childToken can not be used with standing access")
+			}
+			return nil
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewVaultProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
@@ -0,0 +1,306 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/config"
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/tracing"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const providerType = string(kinds.ProviderKindVault)
+
+// tokenAccessorReference is the provider reference holding accessor of the issued token
+const tokenAccessorReference = "tokenAccessor"
+
+var Config = config.GetConfig()
+
+// VaultProvider attaches a policy or identity group to Vault entities
+type VaultProvider struct {
+	client *http.Client
+
+	// provider name as defined in the provider configuration
+	name string
+	// policy attached to the entity. Mutually exclusive with group
+	policy string
+	// internal identity group the entity is added to
+	group string
+	// auth mount used to resolve usernames as entity aliases. Usernames are entity names when empty
+	authMount string
+
+	// issue a token with request TTL on grant
+	childToken bool
+	// token role used to create the token
+	tokenRole string
+	// token policies when tokenRole is not set
+	tokenPolicies []string
+
+	address   string
+	namespace string
+	auth      authMethod
+}
+
+// NewVaultProvider initializes a new VaultProvider with address and auth from CredentialRef
+func NewVaultProvider(ctx context.Context, config models.ProviderConfig) (*VaultProvider, error) {
+	creds := Config.GetCredentials(config.CredentialRef.Name)
+	data := config.Parameters
+
+	timeout := 30 * time.Second
+	if value := data["timeout"]; value != "" {
+		var err error
+		timeout, err = time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+
+	p := &VaultProvider{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+		name:      config.Name,
+		policy:    data["policy"],
+		group:     data["group"],
+		authMount: strings.Trim(data["authMount"], "/"),
+		tokenRole: data["tokenRole"],
+		address:   strings.TrimSuffix(creds.GetString("address"), "/"),
+		namespace: creds.GetString("namespace"),
+		auth: authMethod{
+			token:        creds.GetString("token"),
+			roleID:       creds.GetString("roleid"),
+			secretID:     creds.GetString("secretid"),
+			approleMount: strings.Trim(creds.GetString("approlemount"), "/"),
+		},
+	}
+
+	if p.address == "" {
+		return nil, errors.New("address not found in credentials")
+	}
+	if u, err := url.Parse(p.address); err != nil || u.Scheme != "https" && u.Scheme != "http" || u.Host == "" {
+		return nil, fmt.Errorf("invalid address %s", p.address)
+	}
+	if p.auth.token == "" && (p.auth.roleID == "" || p.auth.secretID == "") {
+		return nil, errors.New("token or roleid and secretid not found in credentials")
+	}
+	if p.auth.approleMount == "" {
+		p.auth.approleMount = "approle"
+	}
+
+	if p.policy == "" && p.group == "" {
+		return nil, errors.New("policy or group not found in provider config")
+	}
+	if p.policy != "" && p.group != "" {
+		return nil, errors.New("policy and group are mutually exclusive")
+	}
+
+	if value := data["childToken"]; value != "" {
+		var err error
+		p.childToken, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid childToken: %w", err)
+		}
+	}
+
+	for _, policy := range strings.Split(data["tokenPolicies"], ",") {
+		if policy = strings.TrimSpace(policy); policy != "" {
+			p.tokenPolicies = append(p.tokenPolicies, policy)
+		}
+	}
+	if len(p.tokenPolicies) == 0 && p.policy != "" {
+		p.tokenPolicies = []string{p.policy}
+	}
+	if p.childToken && p.tokenRole == "" && len(p.tokenPolicies) == 0 {
+		return nil, errors.New("tokenPolicies or tokenRole is required for childToken with group")
+	}
+
+	return p, nil
+}
+
+// GrantAccess attaches policy or group to the user's entity and optionally issues a token
+func (p *VaultProvider) GrantAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "GrantAccess")
+	defer span.End()
+
+	username := request.GetProviderUsername(providerType)
+
+	entity, err := p.findEntity(ctx, username)
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.target(), err.Error())
+		return err
+	}
+
+	attached, err := p.attach(ctx, entity)
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.target(), err.Error())
+		return fmt.Errorf("failed to attach %s: %w", p.target(), err)
+	}
+
+	if p.childToken {
+		token, err := p.createToken(ctx, username, request)
+		if err != nil {
+			// Do not leave behind access the request failed to grant
+			if attached {
+				if err := p.rollback(ctx, username); err != nil {
+					log.Error().Err(err).Str("Provider", p.name).Str("AccessRequest", request.Id).Msg("Failed to detach after token creation failure")
+				}
+			}
+			request.SetProviderStatusError(p.name, p.target(), err.Error())
+			return fmt.Errorf("failed to create token: %w", err)
+		}
+		request.AddCredential(p.name, models.AccessCredentialToken, map[string]string{
+			"address":   p.address,
+			"namespace": p.namespace,
+			"token":     token.ClientToken,
+			"accessor":  token.Accessor,
+		}, request.Status.ExpiresAt)
+		// Token credential is deleted once delivered, so its accessor is kept for revoke
+		request.SetProviderReference(p.name, tokenAccessorReference, token.Accessor)
+	}
+
+	details := ""
+	if !attached {
+		details = "already granted"
+	}
+
+	request.SetProviderStatusGranted(p.name, p.target(), details)
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", username).
+		Str("Entity", entity.ID).
+		Str("Target", p.target()).
+		Bool("Token", p.childToken).
+		Msg("Vault access granted")
+
+	return nil
+}
+
+// RevokeAccess revokes the issued token and detaches policy or group from the user's entity
+func (p *VaultProvider) RevokeAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "RevokeAccess")
+	defer span.End()
+
+	username := request.GetProviderUsername(providerType)
+
+	if accessor := request.GetProviderReference(p.name, tokenAccessorReference); accessor != "" {
+		if err := p.revokeToken(ctx, accessor); err != nil {
+			request.SetProviderStatusError(p.name, p.target(), err.Error())
+			return fmt.Errorf("failed to revoke token: %w", err)
+		}
+	}
+
+	entity, err := p.findEntity(ctx, username)
+	if errors.Is(err, errEntityNotFound) {
+		request.SetProviderStatusRevoked(p.name, p.target(), "entity not found")
+		return nil
+	}
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.target(), err.Error())
+		return err
+	}
+
+	detached, err := p.detach(ctx, entity)
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.target(), err.Error())
+		return fmt.Errorf("failed to detach %s: %w", p.target(), err)
+	}
+
+	details := ""
+	if !detached {
+		details = "already revoked"
+	}
+
+	request.SetProviderStatusRevoked(p.name, p.target(), details)
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", username).
+		Str("Entity", entity.ID).
+		Str("Target", p.target()).
+		Msg("Vault access revoked")
+
+	return nil
+}
+
+// ListUsersWithAccess lists usernames of entities holding the policy or group membership
+func (p *VaultProvider) ListUsersWithAccess(ctx context.Context, roleRef models.AccessRoleRef) ([]string, error) {
+	ctx, span := startSpan(ctx, "ListUsersWithAccess")
+	defer span.End()
+
+	entities, err := p.listEntities(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	var ids []string
+	if p.policy != "" {
+		for id := range entities {
+			e, err := p.readEntity(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read entity %s: %w", id, err)
+			}
+			if slices.Contains(e.Policies, p.policy) {
+				ids = append(ids, id)
+			}
+		}
+	} else {
+		g, err := p.readGroup(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read group: %w", err)
+		}
+		ids = g.MemberEntityIDs
+	}
+
+	accessor := ""
+	if p.authMount != "" {
+		if accessor, err = p.mountAccessor(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	usernames := []string{}
+	for _, id := range ids {
+		if username := entities[id].username(accessor); username != "" {
+			usernames = append(usernames, username)
+		}
+	}
+	slices.Sort(usernames)
+
+	return usernames, nil
+}
+
+// IsAccessExpired checks whether the access for the given request has expired
+func (p *VaultProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
+	_, span := startSpan(ctx, "IsAccessExpired")
+	defer span.End()
+
+	return request.IsExpired(time.Now())
+}
+
+// target returns attached policy or group for provider status
+func (p *VaultProvider) target() string {
+	if p.policy != "" {
+		return "policy " + p.policy
+	}
+	return "group " + p.group
+}
+
+func startSpan(ctx context.Context, name string) (context.Context, *tracing.SpanWrapper) {
+	ctx, span := tracing.NewSpanWrapper(ctx, fmt.Sprintf("providers.vault.%s", name))
+	return ctx, span
+}
@@ -0,0 +1,426 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testToken    = "root-token"
+	testAccessor = "auth_oidc_1234"
+)
+
+// vaultAPI is a minimal Vault identity API with entities e1..eN, each with an oidc alias, and group ops
+type vaultAPI struct {
+	mu       sync.Mutex
+	entities map[string]*entity
+	members  []string
+	tokens   []map[string]any
+	revoked  []string
+	logins   int
+	// failTokens makes token creation fail
+	failTokens bool
+}
+
+func newVaultAPI(usernames ...string) *vaultAPI {
+	api := &vaultAPI{entities: map[string]*entity{}}
+	for i, username := range usernames {
+		id := fmt.Sprintf("e%d", i+1)
+		api.entities[id] = &entity{
+			ID:       id,
+			Name:     "entity_" + id,
+			Policies: []string{"default"},
+			Aliases:  []alias{{Name: username, MountAccessor: testAccessor}},
+		}
+	}
+	return api
+}
+
+func (v *vaultAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	var body map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	if r.Method == http.MethodPost && path == "auth/approle/login" {
+		if body["role_id"] != "role" || body["secret_id"] != "secret" {
+			writeErrors(w, http.StatusBadRequest, "invalid role or secret ID")
+			return
+		}
+		v.logins++
+		writeJSON(w, map[string]any{"auth": map[string]any{"client_token": testToken, "lease_duration": 3600}})
+		return
+	}
+
+	if r.Header.Get("X-Vault-Token") != testToken {
+		writeErrors(w, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && path == "sys/auth":
+		writeJSON(w, map[string]any{"data": map[string]any{
+			"oidc/":  map[string]string{"accessor": testAccessor, "type": "oidc"},
+			"token/": map[string]string{"accessor": "auth_token_1", "type": "token"},
+		}})
+
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "identity/entity/name/"):
+		for _, e := range v.entities {
+			if e.Name == strings.TrimPrefix(path, "identity/entity/name/") {
+				writeJSON(w, map[string]any{"data": e})
+				return
+			}
+		}
+		writeErrors(w, http.StatusNotFound)
+
+	case r.Method == http.MethodPost && path == "identity/lookup/entity":
+		for _, e := range v.entities {
+			if e.username(fmt.Sprint(body["alias_mount_accessor"])) == body["alias_name"] {
+				writeJSON(w, map[string]any{"data": e})
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodGet && path == "identity/entity/id" && r.URL.Query().Get("list") == "true":
+		keyInfo := map[string]any{}
+		for id, e := range v.entities {
+			keyInfo[id] = map[string]any{"name": e.Name, "aliases": e.Aliases}
+		}
+		writeJSON(w, map[string]any{"data": map[string]any{"key_info": keyInfo}})
+
+	case strings.HasPrefix(path, "identity/entity/id/"):
+		e, ok := v.entities[strings.TrimPrefix(path, "identity/entity/id/")]
+		if !ok {
+			writeErrors(w, http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodPost {
+			e.Policies = []string{}
+			for _, policy := range body["policies"].([]any) {
+				e.Policies = append(e.Policies, policy.(string))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, map[string]any{"data": e})
+
+	case path == "identity/group/name/ops":
+		if r.Method == http.MethodPost {
+			v.members = []string{}
+			for _, id := range body["member_entity_ids"].([]any) {
+				v.members = append(v.members, id.(string))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, map[string]any{"data": group{ID: "g1", Name: "ops", Type: "internal", MemberEntityIDs: v.members}})
+
+	case r.Method == http.MethodPost && strings.HasPrefix(path, "auth/token/create"):
+		if v.failTokens {
+			writeErrors(w, http.StatusBadRequest, "token role not found")
+			return
+		}
+		body["path"] = path
+		v.tokens = append(v.tokens, body)
+		writeJSON(w, map[string]any{"auth": map[string]any{"client_token": "hvs.child", "accessor": "acc1", "lease_duration": 3600}})
+
+	case r.Method == http.MethodPost && path == "auth/token/revoke-accessor":
+		if body["accessor"] != "acc1" || slices.Contains(v.revoked, "acc1") {
+			writeErrors(w, http.StatusBadRequest, "1 error occurred:\n\t* invalid accessor\n\n")
+			return
+		}
+		v.revoked = append(v.revoked, "acc1")
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeErrors(w, http.StatusNotFound)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeErrors(w http.ResponseWriter, code int, errors ...string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string][]string{"errors": append([]string{}, errors...)})
+}
+
+func testProvider(t *testing.T, api *vaultAPI, creds map[string]string, parameters map[string]string) *VaultProvider {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	data := map[string]string{"address": server.URL}
+	for k, v := range creds {
+		data[k] = v
+	}
+	Config.Creds = map[string]models.Credential{"vault": {Name: "vault", Data: data}}
+
+	p, err := NewVaultProvider(context.Background(), models.ProviderConfig{
+		Name:          "VaultAccess",
+		Provider:      "vault",
+		CredentialRef: models.CredentialRef{Name: "vault"},
+		Parameters:    parameters,
+	})
+	require.NoError(t, err)
+	return p
+}
+
+func testRequest(username string) *models.AccessRequest {
+	expires := time.Now().Add(time.Hour)
+	request := &models.AccessRequest{Id: "req-1"}
+	request.Status.ExpiresAt = &expires
+	request.Status.ProviderUsernames = map[string]string{providerType: username}
+	return request
+}
+
+func TestPolicyGrantRevoke(t *testing.T) {
+	ctx := context.Background()
+	api := newVaultAPI("alice@example.com", "bob@example.com")
+	p := testProvider(t, api, map[string]string{"token": testToken}, map[string]string{"policy": "payments-read", "authMount": "oidc"})
+
+	request := testRequest("alice@example.com")
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusGranted, request.Status.ProviderStatuses["VaultAccess"].Action)
+	assert.Equal(t, []string{"default", "payments-read"}, api.entities["e1"].Policies)
+	assert.Empty(t, request.Credentials)
+
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, "already granted", request.Status.ProviderStatuses["VaultAccess"].Error)
+
+	users, err := p.ListUsersWithAccess(ctx, models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice@example.com"}, users)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusRevoked, request.Status.ProviderStatuses["VaultAccess"].Action)
+	assert.Equal(t, []string{"default"}, api.entities["e1"].Policies)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, "already revoked", request.Status.ProviderStatuses["VaultAccess"].Error)
+}
+
+func TestGroupGrantRevokeByEntityName(t *testing.T) {
+	ctx := context.Background()
+	api := newVaultAPI("alice@example.com", "bob@example.com")
+	p := testProvider(t, api, map[string]string{"token": testToken}, map[string]string{"group": "ops"})
+
+	request := testRequest("entity_e2")
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, "group ops", request.Status.ProviderStatuses["VaultAccess"].Details)
+	assert.Equal(t, []string{"e2"}, api.members)
+
+	users, err := p.ListUsersWithAccess(ctx, models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"entity_e2"}, users)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Empty(t, api.members)
+}
+
+func TestChildToken(t *testing.T) {
+	ctx := context.Background()
+	api := newVaultAPI("alice@example.com")
+	p := testProvider(t, api, map[string]string{"roleid": "role", "secretid": "secret"}, map[string]string{
+		"policy":     "payments-read",
+		"authMount":  "oidc",
+		"childToken": "true",
+		"tokenRole":  "passage",
+	})
+
+	request := testRequest("alice@example.com")
+	require.NoError(t, p.GrantAccess(ctx, request))
+	require.Len(t, request.Credentials, 1)
+
+	credential := request.Credentials[0]
+	assert.Equal(t, models.AccessCredentialToken, credential.Kind)
+	assert.Equal(t, "hvs.child", credential.Data["token"])
+	assert.Equal(t, p.address, credential.Data["address"])
+	assert.Equal(t, request.Status.ExpiresAt, credential.ExpiresAt)
+
+	require.Len(t, api.tokens, 1)
+	assert.Equal(t, "auth/token/create/passage", api.tokens[0]["path"])
+	assert.Equal(t, []any{"payments-read"}, api.tokens[0]["policies"])
+	assert.Equal(t, "passage-req-1", api.tokens[0]["display_name"])
+	assert.Contains(t, []string{"3599s", "3600s"}, api.tokens[0]["explicit_max_ttl"])
+	assert.Equal(t, 1, api.logins)
+
+	// Credential is gone after delivery, token is revoked by accessor kept in provider references
+	assert.Equal(t, "acc1", request.GetProviderReference("VaultAccess", tokenAccessorReference))
+	request.Credentials = nil
+	request.SetProviderStatusError("VaultAccess", "policy payments-read", "status written by a later call")
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, []string{"acc1"}, api.revoked)
+	assert.Equal(t, models.ProviderStatusRevoked, request.Status.ProviderStatuses["VaultAccess"].Action)
+
+	// Token already expired
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusRevoked, request.Status.ProviderStatuses["VaultAccess"].Action)
+
+	// Failed token creation detaches the policy it attached
+	api.failTokens = true
+	failed := testRequest("alice@example.com")
+	require.Error(t, p.GrantAccess(ctx, failed))
+	assert.Equal(t, models.ProviderStatusError, failed.Status.ProviderStatuses["VaultAccess"].Action)
+	assert.Empty(t, failed.Credentials)
+	assert.Equal(t, []string{"default"}, api.entities["e1"].Policies)
+}
+
+func TestValidateRole(t *testing.T) {
+	config := models.ProviderConfig{Name: "VaultAccess", Provider: providerType, Parameters: map[string]string{"policy": "payments-read", "childToken": "true"}}
+	role := models.AccessRole{Name: "Payments", Providers: []models.ProviderConfig{config}}
+	assert.NoError(t, registry.ValidateRole(role))
+
+	// Standing requests have no TTL for the token
+	role.Standing = models.StandingAccess{Enabled: true, RecertificationInterval: "2160h"}
+	assert.Error(t, registry.ValidateRole(role))
+
+	role.Providers[0].Parameters = map[string]string{"policy": "payments-read"}
+	assert.NoError(t, registry.ValidateRole(role))
+}
+
+func TestUnknownEntity(t *testing.T) {
+	ctx := context.Background()
+	api := newVaultAPI("alice@example.com")
+
+	for _, parameters := range []map[string]string{
+		{"policy": "payments-read"},
+		{"policy": "payments-read", "authMount": "oidc"},
+	} {
+		p := testProvider(t, api, map[string]string{"token": testToken}, parameters)
+
+		request := testRequest("mallory")
+		assert.ErrorIs(t, p.GrantAccess(ctx, request), errEntityNotFound)
+
+		require.NoError(t, p.RevokeAccess(ctx, request))
+		assert.Equal(t, "entity not found", request.Status.ProviderStatuses["VaultAccess"].Error)
+	}
+
+	p := testProvider(t, api, map[string]string{"token": testToken}, map[string]string{"policy": "payments-read", "authMount": "ldap"})
+	assert.EqualError(t, p.GrantAccess(ctx, testRequest("alice@example.com")), "auth mount ldap not found")
+}
+
+func TestPermissionDenied(t *testing.T) {
+	p := testProvider(t, newVaultAPI("alice@example.com"), map[string]string{"token": "wrong"}, map[string]string{"policy": "payments-read"})
+
+	request := testRequest("entity_e1")
+	assert.Error(t, p.GrantAccess(context.Background(), request))
+	assert.Contains(t, request.Status.ProviderStatuses["VaultAccess"].Error, "unexpected status 403: permission denied")
+}
+
+func TestTokenTTL(t *testing.T) {
+	now := time.Now()
+	expires := now.Add(90 * time.Minute)
+
+	request := &models.AccessRequest{}
+	request.Details.TTL = "2h"
+	assert.Equal(t, 2*time.Hour, tokenTTL(request, now))
+
+	request.Status.ExpiresAt = &expires
+	assert.Equal(t, 90*time.Minute, tokenTTL(request, now))
+
+	request.Details.TTL = ""
+	request.Status.ExpiresAt = nil
+	assert.Zero(t, tokenTTL(request, now))
+}
+
+func TestConfigErrors(t *testing.T) {
+	ctx := context.Background()
+	Config.Creds = map[string]models.Credential{
+		"vault":   {Name: "vault", Data: map[string]string{"address": "https://vault.example.com:8200", "token": testToken}},
+		"noauth":  {Name: "noauth", Data: map[string]string{"address": "https://vault.example.com:8200", "roleid": "role"}},
+		"badaddr": {Name: "badaddr", Data: map[string]string{"address": "vault.example.com", "token": testToken}},
+	}
+
+	for _, config := range []models.ProviderConfig{
+		{Parameters: map[string]string{"policy": "read"}},
+		{CredentialRef: models.CredentialRef{Name: "noauth"}, Parameters: map[string]string{"policy": "read"}},
+		{CredentialRef: models.CredentialRef{Name: "badaddr"}, Parameters: map[string]string{"policy": "read"}},
+		{CredentialRef: models.CredentialRef{Name: "vault"}, Parameters: map[string]string{}},
+		{CredentialRef: models.CredentialRef{Name: "vault"}, Parameters: map[string]string{"policy": "read", "group": "ops"}},
+		{CredentialRef: models.CredentialRef{Name: "vault"}, Parameters: map[string]string{"policy": "read", "childToken": "maybe"}},
+		{CredentialRef: models.CredentialRef{Name: "vault"}, Parameters: map[string]string{"group": "ops", "childToken": "true"}},
+		{CredentialRef: models.CredentialRef{Name: "vault"}, Parameters: map[string]string{"policy": "read", "timeout": "soon"}},
+	} {
+		_, err := NewVaultProvider(ctx, config)
+		assert.Error(t, err, fmt.Sprint(config.CredentialRef.Name, config.Parameters))
+	}
+}
+
+// TestVaultDevServer runs against `vault server -dev -dev-root-token-id=root`
+func TestVaultDevServer(t *testing.T) {
+	address := os.Getenv("PASSAGE_TEST_VAULT_ADDR")
+	if address == "" {
+		t.Skip("PASSAGE_TEST_VAULT_ADDR not set")
+	}
+	token := os.Getenv("PASSAGE_TEST_VAULT_TOKEN")
+	if token == "" {
+		token = "root"
+	}
+
+	ctx := context.Background()
+	Config.Creds = map[string]models.Credential{"vault": {Name: "vault", Data: map[string]string{"address": address, "token": token}}}
+	config := models.ProviderConfig{
+		Name:          "VaultAccess",
+		Provider:      "vault",
+		CredentialRef: models.CredentialRef{Name: "vault"},
+		Parameters:    map[string]string{"policy": "passage-test", "childToken": "true"},
+	}
+
+	p, err := NewVaultProvider(ctx, config)
+	require.NoError(t, err)
+
+	username := fmt.Sprintf("passage-test-%d", time.Now().UnixNano())
+	_, err = p.call(ctx, http.MethodPost, "identity/entity", map[string]any{"name": username, "policies": []string{"default"}})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = p.call(ctx, http.MethodDelete, "identity/entity/name/"+username, nil)
+	})
+
+	request := testRequest(username)
+	require.NoError(t, p.GrantAccess(ctx, request))
+	require.Len(t, request.Credentials, 1)
+
+	users, err := p.ListUsersWithAccess(ctx, models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.Contains(t, users, username)
+
+	child := &VaultProvider{client: p.client, address: p.address, auth: authMethod{token: request.Credentials[0].Data["token"]}}
+	resp, err := child.call(ctx, http.MethodGet, "auth/token/lookup-self", nil)
+	require.NoError(t, err)
+	var lookup struct {
+		Policies []string `json:"policies"`
+		TTL      int      `json:"ttl"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Data, &lookup))
+	assert.True(t, slices.Contains(lookup.Policies, "passage-test"))
+	assert.LessOrEqual(t, lookup.TTL, 3600)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	users, err = p.ListUsersWithAccess(ctx, models.AccessRoleRef{})
+	require.NoError(t, err)
+	assert.NotContains(t, users, username)
+
+	_, err = child.call(ctx, http.MethodGet, "auth/token/lookup-self", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,250 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const maxErrorBody = 512
+
+// templateData is available in URL and body templates
+type templateData struct {
+	Username      string
+	Group         string
+	Role          string
+	RequestId     string
+	RequestedBy   string
+	Justification string
+	TTL           string
+	Parameters    map[string]string
+}
+
+var templateFuncs = template.FuncMap{
+	// json renders value as JSON literal, e.g. {"user": {{ json .Username }}}
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	// path escapes value for use as URL path segment
+	"path": url.PathEscape,
+}
+
+type endpoint struct {
+	action string
+	method string
+	url    *template.Template
+	body   *template.Template
+}
+
+// newEndpoint parses <action>Url, <action>Method and <action>Body parameters. Returns nil when URL is not configured
+func newEndpoint(action string, data map[string]string, defaultMethod string) (*endpoint, error) {
+	rawURL := data[action+"Url"]
+	if rawURL == "" {
+		return nil, nil
+	}
+
+	e := &endpoint{
+		action: action,
+		method: strings.ToUpper(data[action+"Method"]),
+	}
+	if e.method == "" {
+		e.method = defaultMethod
+	}
+
+	var err error
+	e.url, err = template.New(action + "Url").Funcs(templateFuncs).Option("missingkey=error").Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %sUrl template: %w", action, err)
+	}
+
+	if body := data[action+"Body"]; body != "" {
+		e.body, err = template.New(action + "Body").Funcs(templateFuncs).Option("missingkey=error").Parse(body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %sBody template: %w", action, err)
+		}
+	}
+
+	return e, nil
+}
+
+// statusError is returned for unexpected HTTP response codes
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	if e.body == "" {
+		return fmt.Sprintf("unexpected status %d", e.code)
+	}
+	return fmt.Sprintf("unexpected status %d: %s", e.code, e.body)
+}
+
+// call renders endpoint templates, sends request and returns response body of a 2xx response
+func (p *WebhookProvider) call(ctx context.Context, e *endpoint, data templateData) ([]byte, error) {
+	ctx, span := startSpan(ctx, e.action)
+	span.SetAttributes(
+		attribute.String("span.kind", "client"),
+		attribute.String("http.method", e.method),
+	)
+	defer span.End()
+
+	rawURL, err := render(e.url, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	if e.body != nil {
+		rendered, err := render(e.body, data)
+		if err != nil {
+			return nil, err
+		}
+		if !json.Valid([]byte(rendered)) {
+			return nil, fmt.Errorf("%sBody template did not render valid JSON", e.action)
+		}
+		body = []byte(rendered)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, e.method, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	p.authenticate(req, body, time.Now())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &statusError{code: resp.StatusCode, body: truncate(string(respBody), maxErrorBody)}
+	}
+
+	return respBody, nil
+}
+
+// isMember calls check endpoint. 2xx means member, 404 means not a member
+func (p *WebhookProvider) isMember(ctx context.Context, data templateData) (bool, error) {
+	if p.check == nil {
+		return false, nil
+	}
+
+	_, err := p.call(ctx, p.check, data)
+	if err == nil {
+		return true, nil
+	}
+
+	var statusErr *statusError
+	if errors.As(err, &statusErr) && statusErr.code == http.StatusNotFound {
+		return false, nil
+	}
+	return false, err
+}
+
+// authenticate adds bearer token and HMAC signature headers.
+// Signature is hex encoded HMAC-SHA256 of "<timestamp>.<body>" sent as "sha256=<signature>"
+func (p *WebhookProvider) authenticate(req *http.Request, body []byte, now time.Time) {
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	if p.hmacSecret != "" {
+		timestamp := strconv.FormatInt(now.Unix(), 10)
+		req.Header.Set("X-Passage-Timestamp", timestamp)
+		req.Header.Set(p.signatureHeader, "sha256="+sign(p.hmacSecret, timestamp, body))
+	}
+}
+
+func sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func render(t *template.Template, data templateData) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", t.Name(), err)
+	}
+	return buf.String(), nil
+}
+
+// extractUsernames reads users array at dot separated field path.
+// Array items are either strings or objects holding username in usernameField
+func extractUsernames(body []byte, field string, usernameField string) ([]string, error) {
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil, fmt.Errorf("invalid list response: %w", err)
+	}
+
+	if field != "" {
+		for _, key := range strings.Split(field, ".") {
+			object, ok := value.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("list response field %s is not an object", key)
+			}
+			value = object[key]
+		}
+	}
+
+	items, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("list response field %q is not an array", field)
+	}
+
+	usernames := []string{}
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			usernames = append(usernames, v)
+		case map[string]any:
+			if usernameField == "" {
+				return nil, errors.New("listUsernameField is required when list response contains objects")
+			}
+			username, ok := v[usernameField].(string)
+			if !ok {
+				return nil, fmt.Errorf("list response item has no %s field", usernameField)
+			}
+			usernames = append(usernames, username)
+		default:
+			return nil, fmt.Errorf("unexpected list response item %v", item)
+		}
+	}
+
+	return usernames, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,45 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/registry"
+)
+
+func init() {
+	registry.Register(registry.Descriptor{
+		Kind:        string(kinds.ProviderKindWebhook),
+		Description: "Manages access by calling configurable HTTP endpoints. URLs and bodies are Go templates with .Username, .Group, .Role, .RequestId, .RequestedBy, .Justification, .TTL and .Parameters",
+		Parameters: []registry.Parameter{
+			{Name: "group", Description: "Group passed to templates", Example: "developers"},
+			{Name: "grantUrl", Description: "Grant endpoint URL template", Required: true, Example: "https://tool.example.com/api/groups/{{ path .Group }}/members"},
+			{Name: "grantMethod", Description: "Grant HTTP method. Defaults to POST", Example: "POST"},
+			{Name: "grantBody", Description: "Grant JSON body template", Example: `{"user": {{ json .Username }}}`},
+			{Name: "revokeUrl", Description: "Revoke endpoint URL template", Required: true, Example: "https://tool.example.com/api/groups/{{ path .Group }}/members/{{ path .Username }}"},
+			{Name: "revokeMethod", Description: "Revoke HTTP method. Defaults to DELETE", Example: "DELETE"},
+			{Name: "revokeBody", Description: "Revoke JSON body template"},
+			{Name: "listUrl", Description: "List members endpoint URL template", Example: "https://tool.example.com/api/groups/{{ path .Group }}/members"},
+			{Name: "listMethod", Description: "List HTTP method. Defaults to GET", Example: "GET"},
+			{Name: "listBody", Description: "List JSON body template"},
+			{Name: "listField", Description: "Dot separated path to users array in list response. Defaults to response root", Example: "data.members"},
+			{Name: "listUsernameField", Description: "Username field of list response items when they are objects", Example: "email"},
+			{Name: "checkUrl", Description: "Membership check URL template. 2xx means member, 404 means not a member", Example: "https://tool.example.com/api/groups/{{ path .Group }}/members/{{ path .Username }}"},
+			{Name: "checkMethod", Description: "Check HTTP method. Defaults to GET", Example: "GET"},
+			{Name: "checkBody", Description: "Check JSON body template"},
+			{Name: "signatureHeader", Description: "HMAC signature header. Defaults to X-Passage-Signature", Example: "X-Hub-Signature-256"},
+			{Name: "timeout", Description: "HTTP request timeout. Defaults to 30s", Example: "30s"},
+		},
+		Credentials: []registry.Parameter{
+			{Name: "token", Description: "Bearer token sent in Authorization header"},
+			{Name: "hmacsecret", Description: "Secret signing request timestamp and body with HMAC-SHA256"},
+		},
+	}, func(ctx context.Context, config models.ProviderConfig) (registry.Provider, error) {
+		provider, err := NewWebhookProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	})
+}
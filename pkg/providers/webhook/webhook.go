@@ -0,0 +1,237 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/CTO2BPublic/passage-server/pkg/config"
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+	"github.com/CTO2BPublic/passage-server/pkg/providers/kinds"
+	"github.com/CTO2BPublic/passage-server/pkg/tracing"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const providerType = string(kinds.ProviderKindWebhook)
+
+var Config = config.GetConfig()
+
+// WebhookProvider manages access by calling configurable HTTP endpoints
+type WebhookProvider struct {
+	client *http.Client
+
+	// provider name as defined in the provider configuration
+	name string
+	// group passed to templates. Defaults to role name for ListUsersWithAccess
+	group      string
+	parameters map[string]string
+
+	grant  *endpoint
+	revoke *endpoint
+	list   *endpoint
+	check  *endpoint
+
+	// dot separated path to the users array in list response
+	listField string
+	// field holding username when list response contains objects
+	listUsernameField string
+
+	token           string
+	hmacSecret      string
+	signatureHeader string
+}
+
+// NewWebhookProvider initializes a new WebhookProvider with credentials from ProviderConfig
+func NewWebhookProvider(ctx context.Context, config models.ProviderConfig) (*WebhookProvider, error) {
+	creds := Config.GetCredentials(config.CredentialRef.Name)
+	data := config.Parameters
+
+	timeout := 30 * time.Second
+	if value := data["timeout"]; value != "" {
+		var err error
+		timeout, err = time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+
+	p := &WebhookProvider{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+		name:              config.Name,
+		group:             data["group"],
+		parameters:        data,
+		listField:         data["listField"],
+		listUsernameField: data["listUsernameField"],
+		token:             creds.GetString("token"),
+		hmacSecret:        creds.GetString("hmacsecret"),
+		signatureHeader:   data["signatureHeader"],
+	}
+	if p.signatureHeader == "" {
+		p.signatureHeader = "X-Passage-Signature"
+	}
+
+	var err error
+	if p.grant, err = newEndpoint("grant", data, http.MethodPost); err != nil {
+		return nil, err
+	}
+	if p.revoke, err = newEndpoint("revoke", data, http.MethodDelete); err != nil {
+		return nil, err
+	}
+	if p.list, err = newEndpoint("list", data, http.MethodGet); err != nil {
+		return nil, err
+	}
+	if p.check, err = newEndpoint("check", data, http.MethodGet); err != nil {
+		return nil, err
+	}
+
+	if p.grant == nil || p.revoke == nil {
+		return nil, errors.New("grantUrl and revokeUrl are required in provider config")
+	}
+
+	return p, nil
+}
+
+// GrantAccess calls grant endpoint unless check endpoint reports existing membership
+func (p *WebhookProvider) GrantAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "GrantAccess")
+	defer span.End()
+
+	data := p.templateData(request)
+
+	isMember, err := p.isMember(ctx, data)
+	if err != nil {
+		request.SetProviderStatusError(p.name, p.group, err.Error())
+		return fmt.Errorf("failed to check membership: %w", err)
+	}
+
+	if isMember {
+		request.SetProviderStatusGranted(p.name, p.group, "already granted")
+		log.Info().
+			Str("TraceID", span.GetTraceID()).
+			Str("Provider", p.name).
+			Str("AccessRequest", request.Id).
+			Str("Username", data.Username).
+			Str("Group", p.group).
+			Msg("User already has access")
+		return nil
+	}
+
+	if _, err := p.call(ctx, p.grant, data); err != nil {
+		request.SetProviderStatusError(p.name, p.group, err.Error())
+		return fmt.Errorf("failed to grant access: %w", err)
+	}
+
+	request.SetProviderStatusGranted(p.name, p.group, "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", data.Username).
+		Str("Group", p.group).
+		Msg("Access granted")
+
+	return nil
+}
+
+// RevokeAccess calls revoke endpoint unless check endpoint reports no membership
+func (p *WebhookProvider) RevokeAccess(ctx context.Context, request *models.AccessRequest) error {
+	ctx, span := startSpan(ctx, "RevokeAccess")
+	defer span.End()
+
+	data := p.templateData(request)
+
+	if p.check != nil {
+		isMember, err := p.isMember(ctx, data)
+		if err != nil {
+			request.SetProviderStatusError(p.name, p.group, err.Error())
+			return fmt.Errorf("failed to check membership: %w", err)
+		}
+
+		if !isMember {
+			request.SetProviderStatusRevoked(p.name, p.group, "already revoked")
+			log.Info().
+				Str("TraceID", span.GetTraceID()).
+				Str("Provider", p.name).
+				Str("AccessRequest", request.Id).
+				Str("Username", data.Username).
+				Str("Group", p.group).
+				Msg("User already has no access")
+			return nil
+		}
+	}
+
+	if _, err := p.call(ctx, p.revoke, data); err != nil {
+		request.SetProviderStatusError(p.name, p.group, err.Error())
+		return fmt.Errorf("failed to revoke access: %w", err)
+	}
+
+	request.SetProviderStatusRevoked(p.name, p.group, "")
+	log.Info().
+		Str("TraceID", span.GetTraceID()).
+		Str("Provider", p.name).
+		Str("AccessRequest", request.Id).
+		Str("Username", data.Username).
+		Str("Group", p.group).
+		Msg("Access revoked")
+
+	return nil
+}
+
+// ListUsersWithAccess calls list endpoint and extracts usernames from the response
+func (p *WebhookProvider) ListUsersWithAccess(ctx context.Context, roleRef models.AccessRoleRef) ([]string, error) {
+	ctx, span := startSpan(ctx, "ListUsersWithAccess")
+	defer span.End()
+
+	if p.list == nil {
+		return nil, errors.New("listUrl not found in provider config")
+	}
+
+	data := templateData{
+		Role:       roleRef.Name,
+		Group:      p.group,
+		Parameters: p.parameters,
+	}
+	if data.Group == "" {
+		data.Group = roleRef.Name
+	}
+
+	body, err := p.call(ctx, p.list, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return extractUsernames(body, p.listField, p.listUsernameField)
+}
+
+// IsAccessExpired checks whether the access for the given request has expired
+func (p *WebhookProvider) IsAccessExpired(ctx context.Context, request *models.AccessRequest) (bool, error) {
+	_, span := startSpan(ctx, "IsAccessExpired")
+	defer span.End()
+
+	return request.IsExpired(time.Now())
+}
+
+func (p *WebhookProvider) templateData(request *models.AccessRequest) templateData {
+	return templateData{
+		Username:      request.GetProviderUsername(providerType),
+		Group:         p.group,
+		Role:          request.RoleRef.Name,
+		RequestId:     request.Id,
+		RequestedBy:   request.Status.RequestedBy,
+		Justification: request.Details.Justification,
+		TTL:           request.Details.TTL,
+		Parameters:    p.parameters,
+	}
+}
+
+func startSpan(ctx context.Context, name string) (context.Context, *tracing.SpanWrapper) {
+	ctx, span := tracing.NewSpanWrapper(ctx, fmt.Sprintf("providers.webhook.%s", name))
+	return ctx, span
+}
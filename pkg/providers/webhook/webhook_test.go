@@ -0,0 +1,213 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/CTO2BPublic/passage-server/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// groupAPI is a minimal group membership API
+type groupAPI struct {
+	mu      sync.Mutex
+	members []string
+	secret  string
+	token   string
+	bodies  []string
+}
+
+func (g *groupAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	g.bodies = append(g.bodies, string(body))
+
+	if g.token != "" && r.Header.Get("Authorization") != "Bearer "+g.token {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if g.secret != "" {
+		expected := "sha256=" + sign(g.secret, r.Header.Get("X-Passage-Timestamp"), body)
+		if r.Header.Get("X-Passage-Signature") != expected {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	member, found := strings.CutPrefix(r.URL.Path, "/groups/devs/members/")
+
+	switch {
+	case r.Method == http.MethodGet && found:
+		if !slices.Contains(g.members, member) {
+			http.NotFound(w, r)
+		}
+	case r.Method == http.MethodGet && r.URL.Path == "/groups/devs/members":
+		items := []map[string]string{}
+		for _, m := range g.members {
+			items = append(items, map[string]string{"email": m})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"members": items}})
+	case r.Method == http.MethodPost && r.URL.Path == "/groups/devs/members":
+		payload := struct {
+			User string `json:"user"`
+		}{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if payload.User == "blocked@example.com" {
+			http.Error(w, "user is blocked", http.StatusForbidden)
+			return
+		}
+		g.members = append(g.members, payload.User)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodDelete && found:
+		g.members = slices.DeleteFunc(g.members, func(m string) bool { return m == member })
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+	}
+}
+
+func testProvider(t *testing.T, api *groupAPI, parameters map[string]string) *WebhookProvider {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	Config.Creds = map[string]models.Credential{
+		"webhook": {
+			Name: "webhook",
+			Data: map[string]string{"token": api.token, "hmacsecret": api.secret},
+		},
+	}
+
+	params := map[string]string{
+		"group":             "devs",
+		"grantUrl":          server.URL + "/groups/{{ path .Group }}/members",
+		"grantBody":         `{"user": {{ json .Username }}, "reason": {{ json .Justification }}}`,
+		"revokeUrl":         server.URL + "/groups/{{ path .Group }}/members/{{ path .Username }}",
+		"listUrl":           server.URL + "/groups/{{ path .Group }}/members",
+		"listField":         "data.members",
+		"listUsernameField": "email",
+		"checkUrl":          server.URL + "/groups/{{ path .Group }}/members/{{ path .Username }}",
+	}
+	for k, v := range parameters {
+		params[k] = v
+	}
+
+	p, err := NewWebhookProvider(context.Background(), models.ProviderConfig{
+		Name:          "Webhook",
+		Provider:      "webhook",
+		CredentialRef: models.CredentialRef{Name: "webhook"},
+		Parameters:    params,
+	})
+	require.NoError(t, err)
+	return p
+}
+
+func testRequest(username string) *models.AccessRequest {
+	request := &models.AccessRequest{Id: "req-1"}
+	request.Details.Justification = `needs "quoted" access`
+	request.Status.ProviderUsernames = map[string]string{providerType: username}
+	return request
+}
+
+func TestGrantRevoke(t *testing.T) {
+	ctx := context.Background()
+	api := &groupAPI{token: "token", secret: "secret"}
+	p := testProvider(t, api, nil)
+
+	request := testRequest("alice@example.com")
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatus{Action: models.ProviderStatusGranted, Details: "devs"}, request.Status.ProviderStatuses["Webhook"])
+	assert.Equal(t, []string{"alice@example.com"}, api.members)
+	assert.JSONEq(t, `{"user": "alice@example.com", "reason": "needs \"quoted\" access"}`, api.bodies[len(api.bodies)-1])
+
+	require.NoError(t, p.GrantAccess(ctx, request))
+	assert.Equal(t, "already granted", request.Status.ProviderStatuses["Webhook"].Error)
+	assert.Len(t, api.members, 1)
+
+	users, err := p.ListUsersWithAccess(ctx, models.AccessRoleRef{Name: "Developers"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice@example.com"}, users)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, models.ProviderStatusRevoked, request.Status.ProviderStatuses["Webhook"].Action)
+	assert.Empty(t, api.members)
+
+	require.NoError(t, p.RevokeAccess(ctx, request))
+	assert.Equal(t, "already revoked", request.Status.ProviderStatuses["Webhook"].Error)
+}
+
+func TestGrantError(t *testing.T) {
+	p := testProvider(t, &groupAPI{}, nil)
+
+	request := testRequest("blocked@example.com")
+	err := p.GrantAccess(context.Background(), request)
+	require.Error(t, err)
+
+	status := request.Status.ProviderStatuses["Webhook"]
+	assert.Equal(t, models.ProviderStatusError, status.Action)
+	assert.Contains(t, status.Error, "unexpected status 403: user is blocked")
+}
+
+func TestAuthentication(t *testing.T) {
+	api := &groupAPI{token: "token", secret: "secret"}
+	p := testProvider(t, api, nil)
+	p.hmacSecret = "wrong"
+
+	err := p.GrantAccess(context.Background(), testRequest("alice@example.com"))
+	assert.ErrorContains(t, err, "unexpected status 401: bad signature")
+
+	p.hmacSecret = "secret"
+	p.token = ""
+	err = p.GrantAccess(context.Background(), testRequest("alice@example.com"))
+	assert.ErrorContains(t, err, "unexpected status 401: unauthorized")
+}
+
+func TestWithoutCheckEndpoint(t *testing.T) {
+	api := &groupAPI{members: []string{"alice@example.com"}}
+	p := testProvider(t, api, map[string]string{"checkUrl": ""})
+
+	request := testRequest("bob@example.com")
+	require.NoError(t, p.GrantAccess(context.Background(), request))
+	require.NoError(t, p.RevokeAccess(context.Background(), testRequest("alice@example.com")))
+	assert.Equal(t, []string{"bob@example.com"}, api.members)
+}
+
+func TestExtractUsernames(t *testing.T) {
+	users, err := extractUsernames([]byte(`["a", "b"]`), "", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, users)
+
+	_, err = extractUsernames([]byte(`{"members": [{"id": 1}]}`), "members", "")
+	assert.Error(t, err)
+
+	_, err = extractUsernames([]byte(`{"members": {}}`), "members", "email")
+	assert.Error(t, err)
+}
+
+func TestConfigErrors(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewWebhookProvider(ctx, models.ProviderConfig{Parameters: map[string]string{"grantUrl": "http://x"}})
+	assert.Error(t, err, "revokeUrl is required")
+
+	_, err = NewWebhookProvider(ctx, models.ProviderConfig{Parameters: map[string]string{"grantUrl": "http://x/{{ .Username", "revokeUrl": "http://x"}})
+	assert.Error(t, err, "invalid template")
+
+	p, err := NewWebhookProvider(ctx, models.ProviderConfig{Parameters: map[string]string{"grantUrl": "http://x", "revokeUrl": "http://x", "grantBody": "{{ .Username }}"}})
+	require.NoError(t, err)
+	err = p.GrantAccess(ctx, testRequest("not json"))
+	assert.ErrorContains(t, err, "valid JSON")
+}